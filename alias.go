@@ -0,0 +1,68 @@
+package brick
+
+import "fmt"
+
+// AddLiveIDAlias adds an alias for the liveID, so that Get, GetOrCreate and brick tags can use
+// the alias instead of the canonical liveID, e.g. to give a friendly name to a cloned brick.
+//
+// The alias must be unique and must not collide with an existing liveID or typeID,
+// nor with a liveID declared by a config or by RegisterLiveIDType. A config can't declare an alias as its liveID either.
+func AddLiveIDAlias(alias, liveID string) error {
+	return brickManager.AddLiveIDAlias(alias, liveID)
+}
+
+// ResolveAlias returns the canonical liveID of the alias.
+func ResolveAlias(alias string) (string, bool) {
+	return brickManager.ResolveAlias(alias)
+}
+
+// AddLiveIDAlias adds an alias for the liveID.
+func (b *BrickManager) AddLiveIDAlias(alias, liveID string) error {
+	if alias == "" || liveID == "" {
+		return fmt.Errorf("alias and liveID are required")
+	}
+	if alias == liveID {
+		return fmt.Errorf("alias(%s) is the same as the liveID", alias)
+	}
+	if _, ok := b.getBrickType(alias); ok {
+		return fmt.Errorf("alias(%s) collides with a typeID", alias)
+	}
+	if _, ok := b.getBrickConfig(alias); ok {
+		return fmt.Errorf("alias(%s) collides with a configured liveID", alias)
+	}
+	if _, ok := b.getBrickFromExist(alias); ok {
+		return fmt.Errorf("alias(%s) collides with an existing liveID", alias)
+	}
+	b.liveIDTypeMapLock.RLock()
+	_, declared := b.liveIDTypeMap[alias]
+	b.liveIDTypeMapLock.RUnlock()
+	if declared {
+		return fmt.Errorf("alias(%s) collides with a liveID registered by RegisterLiveIDType", alias)
+	}
+	b.liveIDAliasesLock.Lock()
+	defer b.liveIDAliasesLock.Unlock()
+	if old, ok := b.liveIDAliases[alias]; ok {
+		return fmt.Errorf("alias(%s) already exists for liveID(%s)", alias, old)
+	}
+	if _, ok := b.liveIDAliases[liveID]; ok {
+		return fmt.Errorf("liveID(%s) is an alias itself", liveID)
+	}
+	b.liveIDAliases[alias] = liveID
+	return nil
+}
+
+// ResolveAlias returns the canonical liveID of the alias.
+func (b *BrickManager) ResolveAlias(alias string) (string, bool) {
+	b.liveIDAliasesLock.RLock()
+	defer b.liveIDAliasesLock.RUnlock()
+	liveID, ok := b.liveIDAliases[alias]
+	return liveID, ok
+}
+
+// resolveLiveID translates the alias to its canonical liveID, other liveIDs are returned as is.
+func (b *BrickManager) resolveLiveID(liveID string) string {
+	if canonical, ok := b.ResolveAlias(liveID); ok {
+		return canonical
+	}
+	return liveID
+}
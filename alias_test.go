@@ -0,0 +1,57 @@
+package brick
+
+import "testing"
+
+type TestBrick8 struct {
+	Test string
+}
+
+func (t *TestBrick8) BrickTypeID() string {
+	return "TestBrick8"
+}
+
+type TestBrick81 struct {
+	T8 *TestBrick8 `brick:"TestBrick8 friendly alias"`
+}
+
+func (t *TestBrick81) BrickTypeID() string {
+	return "TestBrick81"
+}
+
+func TestLiveIDAlias(t *testing.T) {
+	Register[*TestBrick81]()
+	if err := AddLive("TestBrick8", "TestBrick8", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddLive("TestBrick8", "TestBrick8 primary", nil); err != nil {
+		t.Fatal(err)
+	}
+	primary := Get[*TestBrick8]("TestBrick8 primary")
+	primary.Test = "primary"
+
+	if err := AddLiveIDAlias("TestBrick8 friendly alias", "TestBrick8 primary"); err != nil {
+		t.Fatal(err)
+	}
+	if liveID, ok := ResolveAlias("TestBrick8 friendly alias"); !ok || liveID != "TestBrick8 primary" {
+		t.Errorf("ResolveAlias() = %v, %v, want TestBrick8 primary, true", liveID, ok)
+	}
+	if got := Get[*TestBrick8]("TestBrick8 friendly alias"); got != primary {
+		t.Errorf("Get by alias = %p, want %p", got, primary)
+	}
+	if got := Get[*TestBrick81](); got.T8 != primary {
+		t.Errorf("injected by alias = %p, want %p", got.T8, primary)
+	}
+
+	if err := AddLiveIDAlias("TestBrick8 friendly alias", "TestBrick8 primary"); err == nil {
+		t.Errorf("expected error for duplicate alias")
+	}
+	if err := AddLiveIDAlias("TestBrick8", "TestBrick8 primary"); err == nil {
+		t.Errorf("expected error for alias colliding with typeID")
+	}
+	if err := AddLiveIDAlias("TestBrick8 primary", "TestBrick8"); err == nil {
+		t.Errorf("expected error for alias colliding with a configured liveID")
+	}
+	if err := AddLive("TestBrick8", "TestBrick8 friendly alias", nil); err == nil {
+		t.Errorf("expected error for a config declaring an alias as its liveID")
+	}
+}
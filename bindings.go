@@ -0,0 +1,105 @@
+package brick
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// CheckInterfaceBindings reports the interface fields of the registered bricks whose liveID can't be bound to a type.
+// The type of a liveID injected into an interface field is given by its config, by RegisterLiveIDType,
+// by the typeID on the tag (e.g. `brick:"liveID,typeID"`), by an instance that already exists,
+// or by the brick bound to the interface by RegisterImpl.
+// A liveID with none of them fails when the field is injected.
+//
+// Get logs each of these errors once, or panics on them if SetFailFastOnConfig is on.
+// Call it at startup, after the registrations and the configs, to fail early.
+func CheckInterfaceBindings() error {
+	return brickManager.CheckInterfaceBindings()
+}
+
+// CheckInterfaceBindings reports the interface fields of the registered bricks whose liveID can't be bound to a type.
+func (b *BrickManager) CheckInterfaceBindings() error {
+	return errors.Join(b.interfaceBindingErrors()...)
+}
+
+// interfaceBindingErrors returns an error for every interface field whose liveID can't be bound to a type.
+func (b *BrickManager) interfaceBindingErrors() []error {
+	b.brickTypeIDMapLock.RLock()
+	types := make(map[string]reflect.Type, len(b.brickTypeIDMap2))
+	for typeID, typ := range b.brickTypeIDMap2 {
+		types[typeID] = typ
+	}
+	b.brickTypeIDMapLock.RUnlock()
+	typeIDs := make([]string, 0, len(types))
+	for typeID := range types {
+		typeIDs = append(typeIDs, typeID)
+	}
+	sort.Strings(typeIDs)
+
+	var errs []error
+	for _, typeID := range typeIDs {
+		typ := types[typeID]
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct {
+			continue
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			tag, ok := field.Tag.Lookup(brickTag)
+			if !ok {
+				continue
+			}
+			fieldType := field.Type
+			if elemType, ok := lazyElemType(fieldType); ok {
+				fieldType = elemType
+			}
+			if fieldType.Kind() == reflect.Slice {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() != reflect.Interface {
+				continue
+			}
+			if _, ok := b.getImplBinding(fieldType); ok {
+				// The type is given by RegisterImpl.
+				continue
+			}
+			liveIDs, tagTypeID, _, isRandomLiveID := b.parseTag(tag)
+			if _, ok := fromTagField(liveIDs); ok {
+				continue
+			}
+			if _, ok := methodTagName(liveIDs); ok {
+				continue
+			}
+			if isRandomLiveID || liveIDs == groupTag || liveIDs == allTag || (tagTypeID != "" && tagTypeID != deferredTag && tagTypeID != rewireableTag) {
+				// The type is given by the tag, or the field is not bound to a liveID.
+				continue
+			}
+			for _, liveID := range splitLiveIDList(liveIDs) {
+				if !b.isBoundLiveID(b.resolveLiveID(liveID)) {
+					errs = append(errs, fmt.Errorf("the interface field %s of brick(%s) depends on liveID(%s), whose type can't be determined, "+
+						"please add its config, or register its type with `brick.RegisterLiveIDType`, or give its typeID on the tag `brick:\"%s,typeID\"`",
+						field.Name, typeID, liveID, liveID))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// isBoundLiveID reports whether the type of the liveID is known when it is injected into an interface field.
+func (b *BrickManager) isBoundLiveID(liveID string) bool {
+	if _, ok := b.getBrickConfig(liveID); ok {
+		return true
+	}
+	if _, ok := b.getBrickFromExist(liveID); ok {
+		return true
+	}
+	b.liveIDTypeMapLock.RLock()
+	defer b.liveIDTypeMapLock.RUnlock()
+	_, ok := b.liveIDTypeMap[liveID]
+	return ok
+}
@@ -0,0 +1,147 @@
+package brick
+
+import (
+	"bytes"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type TestDatabase38 interface {
+	Query(string) string
+}
+
+type TestBrick38 struct{}
+
+func (t *TestBrick38) BrickTypeID() string {
+	return "TestBrick38"
+}
+
+func (t *TestBrick38) Query(q string) string {
+	return q
+}
+
+type TestBrick381 struct {
+	Database TestDatabase38 `brick:"TestBrick38 mysql"`
+}
+
+func (t *TestBrick381) BrickTypeID() string {
+	return "TestBrick381"
+}
+
+func TestCheckInterfaceBindings(t *testing.T) {
+	Register[*TestBrick38]()
+	Register[*TestBrick381]()
+	const want = "interface field Database of brick(TestBrick381) depends on liveID(TestBrick38 mysql)"
+	if err := CheckInterfaceBindings(); err == nil || !strings.Contains(err.Error(), want) || !strings.Contains(err.Error(), "RegisterLiveIDType") {
+		t.Errorf("CheckInterfaceBindings() = %v, want an error containing %q", err, want)
+	}
+
+	RegisterLiveIDType[*TestBrick38]("TestBrick38 mysql")
+	if err := CheckInterfaceBindings(); err != nil && strings.Contains(err.Error(), want) {
+		t.Errorf("CheckInterfaceBindings() = %v after RegisterLiveIDType", err)
+	}
+	if got := GetOrCreate[*TestBrick381]().Database.Query("select"); got != "select" {
+		t.Errorf("Query() = %s, want select", got)
+	}
+}
+
+type TestBrick385 struct {
+	Database TestDatabase38 `brick:"TestBrick38 postgres"`
+}
+
+func (t *TestBrick385) BrickTypeID() string {
+	return "TestBrick385"
+}
+
+func TestInterfaceBindingsCheckedOnGet(t *testing.T) {
+	var buf bytes.Buffer
+	output := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(output) })
+	Register[*TestBrick38]()
+	Register[*TestBrick385]()
+	const want = "interface field Database of brick(TestBrick385) depends on liveID(TestBrick38 postgres)"
+
+	func() {
+		SetFailFastOnConfig(true)
+		defer SetFailFastOnConfig(false)
+		defer func() {
+			err, _ := recover().(error)
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("Get() panic = %v with fail fast, want an error containing %q", err, want)
+			}
+		}()
+		brickManager.brickConfigCheckOnce = sync.Once{}
+		Get[*TestBrick38]()
+	}()
+
+	brickManager.brickConfigCheckOnce = sync.Once{}
+	Get[*TestBrick38]()
+	if got := buf.String(); strings.Count(got, want) != 1 {
+		t.Errorf("log = %q, want one warning containing %q", got, want)
+	}
+	brickManager.brickConfigCheckOnce = sync.Once{}
+	Get[*TestBrick38]()
+	if got := buf.String(); strings.Count(got, want) != 1 {
+		t.Errorf("log = %q, want the warning logged once", got)
+	}
+	RegisterLiveIDType[*TestBrick38]("TestBrick38 postgres")
+}
+
+type TestBrick94 struct{}
+
+func (t *TestBrick94) BrickTypeID() string {
+	return "TestBrick94"
+}
+
+type TestBrick941 struct {
+	Database TestDatabase38 `brick:"TestBrick94 a"`
+}
+
+func (t *TestBrick941) BrickTypeID() string {
+	return "TestBrick941"
+}
+
+type TestBrick942 struct {
+	Databases []TestDatabase38 `brick:"TestBrick38 b;TestBrick94 b"`
+}
+
+func (t *TestBrick942) BrickTypeID() string {
+	return "TestBrick942"
+}
+
+type TestBrick943 struct {
+	Database TestDatabase38 `brick:"TestBrick94 c,TestBrick94"`
+}
+
+func (t *TestBrick943) BrickTypeID() string {
+	return "TestBrick943"
+}
+
+func TestRegisterInterfaceFieldMismatch(t *testing.T) {
+	m := NewBrickManager()
+	m.register2("TestBrick38", reflect.TypeOf(&TestBrick38{}))
+	m.register2("TestBrick94", reflect.TypeOf(&TestBrick94{}))
+	m.RegisterLiveIDType("TestBrick38 b", reflect.TypeOf(&TestBrick38{}))
+	m.RegisterLiveIDType("TestBrick94 a", reflect.TypeOf(&TestBrick94{}))
+	m.RegisterLiveIDType("TestBrick94 b", reflect.TypeOf(&TestBrick94{}))
+	for name, typ := range map[string]reflect.Type{
+		"liveID":      reflect.TypeOf(&TestBrick941{}),
+		"liveID list": reflect.TypeOf(&TestBrick942{}),
+		"typeID":      reflect.TypeOf(&TestBrick943{}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				err, _ := r.(error)
+				if err == nil || !strings.Contains(err.Error(), "*brick.TestBrick94") || !strings.Contains(err.Error(), "does not implement brick.TestDatabase38") {
+					t.Errorf("register() panic = %v, want the mismatched type", r)
+				}
+			}()
+			m.register2(typ.Elem().Name(), typ)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package brick
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Manifest declares the wiring of an app for Bootstrap, the order of its fields doesn't matter.
+type Manifest struct {
+	// Registrations register the brick types, e.g. `brick.Register[*DB]` or `brick.RegisterNewer[*Cache]`.
+	Registrations []func()
+	// ConfigFiles are the config files added by AddConfigFile, in order.
+	ConfigFiles []string
+	// Configs are the configurations added by AddConfig after the config files.
+	Configs []BrickFileConfig
+	// Initialize builds every live by InitializeAll once the configurations are added.
+	Initialize bool
+}
+
+// Bootstrap wires the app from the manifest in the order brick expects: it registers the types,
+// then adds the configurations, then initializes the lives if manifest.Initialize is set.
+// Every step of a phase runs, and the next phase is skipped if any of them fails;
+// the errors of the phase, including the panics of the registrations, are returned joined.
+func Bootstrap(manifest Manifest) error {
+	var errs []error
+	for i, register := range manifest.Registrations {
+		if err := runRecovered(register); err != nil {
+			errs = append(errs, fmt.Errorf("registration %d: %w", i, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	for _, path := range manifest.ConfigFiles {
+		if err := AddConfigFile(path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(manifest.Configs) > 0 {
+		if err := AddConfig(manifest.Configs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 || !manifest.Initialize {
+		return errors.Join(errs...)
+	}
+	return runRecovered(InitializeAll)
+}
+
+// runRecovered runs fn, and returns its panic as an error.
+func runRecovered(fn func()) (err error) {
+	defer recoverError(&err)
+	fn()
+	return nil
+}
@@ -0,0 +1,67 @@
+package brick
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type TestBrick88 struct {
+	Dep *TestBrick881 `brick:"TestBrick881 primary"`
+}
+
+func (t *TestBrick88) BrickTypeID() string {
+	return "TestBrick88"
+}
+
+type TestBrick881 struct {
+	Name string `json:"name"`
+}
+
+func (t *TestBrick881) BrickTypeID() string {
+	return "TestBrick881"
+}
+
+func (t *TestBrick881) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick881{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func TestBootstrap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`[{"metaData": {"typeID": "TestBrick881"}, "lives": [
+		{"liveID": "TestBrick881", "config": {"name": "default"}},
+		{"liveID": "TestBrick881 primary", "config": {"name": "primary"}}
+	]}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Bootstrap(Manifest{
+		ConfigFiles: []string{filepath.Join(t.TempDir(), "missing.json")},
+		Registrations: []func(){
+			func() { panic("first") },
+			func() { panic("second") },
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "registration 0: first") || !strings.Contains(err.Error(), "registration 1: second") {
+		t.Errorf("Bootstrap() error = %v, want the errors of both registrations", err)
+	}
+
+	// The config files are declared before the registrations, they are still added after them.
+	err = Bootstrap(Manifest{
+		Initialize:    true,
+		ConfigFiles:   []string{path},
+		Registrations: []func(){Register[*TestBrick88], RegisterNewer[*TestBrick881]},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Get[*TestBrick88]().Dep.Name; got != "primary" {
+		t.Errorf("Get[*TestBrick88]().Dep.Name = %s, want primary", got)
+	}
+}
@@ -1,30 +1,124 @@
 package brick
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"golang.org/x/sync/singleflight"
 )
 
 var (
-	brickManager = &BrickManager{
-		brickConfigs:     make(map[string]BrickConfig),
-		instances:        make(map[string]reflect.Value),
-		brickFactories:   make(map[string]func(config any) Brick),
-		brickTypeIDMap1:  make(map[reflect.Type]string),
-		brickTypeIDMap2:  make(map[string]reflect.Type),
-		liveIDTypeMap:    make(map[string]reflect.Type),
-		declaredLiveIDs:  make(map[string]bool),
-		liveIDConstraint: true,
-		configs:          make([]*ConfigManager, 0, 1),
-	}
+	brickManager = NewBrickManager()
 )
 
+// NewBrickManager creates an empty BrickManager, isolated from the package-level one.
+// Bricks are always built by the package-level manager, an isolated manager is useful
+// to load and compare configurations, e.g. of two environments.
+func NewBrickManager() *BrickManager {
+	return &BrickManager{
+		brickConfigs:      make(map[string]BrickConfig),
+		instances:         make(map[string]reflect.Value),
+		generations:       make(map[string]uint64),
+		brickFactories:    make(map[string]func(config any) Brick),
+		brickCtxFactories: make(map[string]func(ctx context.Context, config any) (Brick, error)),
+		liveFactories:     make(map[string]func(jsonConfig []byte) Brick),
+		implBindings:      make(map[reflect.Type]string),
+		initDependencies:  make(map[string][]string),
+		configValidators:  make(map[string][]configKeyValidator),
+		tagModifiers:      make(map[string]func(ctx InjectContext, field reflect.Value, arg string) error),
+		secretProviders:   make(map[string]func() string),
+		closedBricks:      make(map[closedBrickKey]reflect.Value),
+		startedBricks:     make(map[closedBrickKey]reflect.Value),
+		goroutineScopes:   make(map[uint64]*Scope),
+		brickTypeIDMap1:   make(map[reflect.Type]string),
+		brickTypeIDMap2:   make(map[string]reflect.Type),
+		typeEdges:         make(map[string][]string),
+		brickFieldCache:   make(map[reflect.Type][]brickField),
+		brickLives:        make(map[string][]Live),
+		liveIDTypeMap:     make(map[string]reflect.Type),
+		declaredLiveIDs:   make(map[string]bool),
+		liveIDConstraint:  true,
+		configs:           make([]*ConfigManager, 0, 1),
+		liveIDAliases:     make(map[string]string),
+		builtConfigs:      make(map[string][]byte),
+		disabledTypes:     make(map[string]bool),
+		disabledFallback:  make(map[string]reflect.Value),
+		providers:         make(map[string]func() reflect.Value),
+		reloadEvents:      make(chan ReloadEvent, reloadEventsBuffer),
+		deferredBuilds:    make(map[string]*deferredBuild),
+		configMergeKeys:   make(map[string]map[string]string),
+		selectors:         make(map[reflect.Type]func(ctx context.Context) string),
+		buildStats:        make(map[string]*BuildStat),
+		inFlightBuilds:    make(map[string]int),
+		fallbackFactories: make(map[string]func() Brick),
+		degraded:          make(map[string]bool),
+		instanceHashes:    make(map[string]uint64),
+		prototypes:        make(map[string]bool),
+		dedupInstances:    make(map[dedupKey]dedupInstance),
+		scopedTypes:       make(map[string]bool),
+		bindingWarnings:   make(map[string]bool),
+	}
+}
+
 const brickTag = "brick"
 
+// weightedTag is the tag option to pick one live of the type at random by weight, e.g. `brick:"typeID,weighted"`.
+const weightedTag = "weighted"
+
+// providerTag is the tag option to inject the value of a provider, e.g. `brick:"liveID,provider"`.
+const providerTag = "provider"
+
+// deferredTag is the tag option to defer the construction of a dependency until first use, e.g. `brick:"liveID,deferred"`.
+const deferredTag = "deferred"
+
+// configsTag is the tag option to inject every live config of the type parsed into the slice element, e.g. `brick:"typeID,configs"`.
+const configsTag = "configs"
+
+// liveIDsTag is the tag option to inject the sorted liveIDs of the configured lives of the type into a string slice,
+// e.g. `brick:"typeID,liveids"`, or `brick:"typeID,liveids,nodefault"` without the default live of the type.
+const liveIDsTag = "liveids"
+
+// noDefaultTag is the option of liveIDsTag excluding the default live, whose liveID is the typeID.
+const noDefaultTag = "nodefault"
+
+// rewireableTag is the tag option to update the field when its dependency is replaced, e.g. `brick:"liveID,rewireable"`.
+const rewireableTag = "rewireable"
+
+// selectedTag is the tag option to inject the live chosen by the selector of the interface type, e.g. `brick:",selected"`.
+const selectedTag = "selected"
+
+// optionalTag is the tag option to leave the field zero if the dependency is missing, e.g. `brick:"liveID,optional"`:
+// its type is not registered or disabled, its liveID is unknown or disabled, or the type of its liveID can't be determined.
+// The missing bricks of a liveID list are skipped. It can't be used with nonemptyTag.
+const optionalTag = "optional"
+
+// fromTagPrefix is the tag prefix to read the liveID from another string field of the brick, e.g. `brick:"from:Driver"`.
+const fromTagPrefix = "from:"
+
+// deepCloneTagPrefix is the tag prefix to inject a clone of the dependency whose own dependencies are cloned too, recursively,
+// e.g. `brick:"deepclone:"` for the default live or `brick:"deepclone:liveID"`. A `clone` only clones the dependency itself,
+// its dependencies are the shared singletons.
+const deepCloneTagPrefix = "deepclone:"
+
+// methodTagPrefix is the tag prefix to set the field to the result of a method of the brick, e.g. `brick:"method:BuildCache"`.
+// The method is called once the other fields are injected, it takes no argument and returns the field type.
+const methodTagPrefix = "method:"
+
+// groupTag is the tag to inject every live of the element type into a slice, e.g. `brick:"group"`.
+// nonemptyTag is the option requiring at least one member in the group, e.g. `brick:"group,nonempty"`.
+// allTag is the tag to inject every configured live of the element type into a slice sorted by liveID,
+// or into a map keyed by liveID, e.g. `brick:"all"`.
+const (
+	groupTag    = "group"
+	nonemptyTag = "nonempty"
+	allTag      = "all"
+)
+
 // BrickManager manages brick configurations and instances.
 type BrickManager struct {
 	// brickConfigs stores configurations for each brick, indexed by LiveID.
@@ -33,7 +127,11 @@ type BrickManager struct {
 
 	// instances stores created brick instances, indexed by LiveID.
 	// All instances are saved as pointers.
-	instances     map[string]reflect.Value
+	instances map[string]reflect.Value
+	// buildOrder stores the liveIDs in the order they were first constructed.
+	buildOrder []string
+	// generations stores the number of times an instance was saved for each liveID, indexed by LiveID.
+	generations   map[string]uint64
 	instancesLock sync.RWMutex
 
 	// brickFactories stores functions to parse configurations into bricks, indexed by TypeID.
@@ -41,11 +139,55 @@ type BrickManager struct {
 	brickFactories     map[string]func(config any) Brick
 	brickFactoriesLock sync.RWMutex
 
+	// implBindings stores the typeID bound to an interface by RegisterImpl, indexed by interface type.
+	implBindings     map[reflect.Type]string
+	implBindingsLock sync.RWMutex
+
+	// initDependencies stores the typeIDs declared by DeclareInitDependency to initialize before a type, indexed by TypeID.
+	initDependencies     map[string][]string
+	initDependenciesLock sync.RWMutex
+
+	// configValidators stores the validators of config keys registered by RegisterConfigValidator, indexed by TypeID.
+	configValidators     map[string][]configKeyValidator
+	configValidatorsLock sync.RWMutex
+
+	// tagModifiers stores the handlers of the tag modifiers registered by RegisterTagModifier, indexed by name.
+	tagModifiers     map[string]func(ctx InjectContext, field reflect.Value, arg string) error
+	tagModifiersLock sync.RWMutex
+
+	// secretProviders stores the providers of the secrets registered by RegisterSecretProvider, indexed by key.
+	secretProviders     map[string]func() string
+	secretProvidersLock sync.RWMutex
+
+	// parent is the manager resolving the lives that have neither an instance nor a config in this manager.
+	parent     *BrickManager
+	parentLock sync.RWMutex
+
+	// liveFactories stores the factories overriding the factory of the type for a single live, indexed by LiveID.
+	liveFactories     map[string]func(jsonConfig []byte) Brick
+	liveFactoriesLock sync.RWMutex
+
+	// brickCtxFactories stores the factories of the bricks implementing BrickNewerCtx, indexed by TypeID.
+	brickCtxFactories     map[string]func(ctx context.Context, config any) (Brick, error)
+	brickCtxFactoriesLock sync.RWMutex
+
 	// brickTypeIDMap1 stores the TypeID of each brick type, indexed by reflect.Type.
 	brickTypeIDMap1    map[reflect.Type]string
 	brickTypeIDMap2    map[string]reflect.Type
 	brickTypeIDMapLock sync.RWMutex
 
+	// typeEdges stores the TypeIDs that the `brick` tagged fields of each registered type always build, indexed by TypeID.
+	typeEdges     map[string][]string
+	typeEdgesLock sync.RWMutex
+
+	// brickFieldCache stores the `brick` tagged fields of each struct type, indexed by reflect.Type.
+	brickFieldCache map[reflect.Type][]brickField
+	brickFieldsLock sync.RWMutex
+
+	// brickLives stores the lives registered by RegisterWithLives, indexed by TypeID.
+	brickLives     map[string][]Live
+	brickLivesLock sync.RWMutex
+
 	// liveIDTypeMap is a map that stores the type of registered liveID, indexed by liveID.
 	liveIDTypeMap     map[string]reflect.Type
 	liveIDTypeMapLock sync.RWMutex
@@ -54,6 +196,10 @@ type BrickManager struct {
 	declaredLiveIDs     map[string]bool
 	declaredLiveIDsLock sync.RWMutex
 
+	// randSource is the source of RandomLiveID set by SetRandSource, nil for the default generator.
+	randSource     rand.Source
+	randSourceLock sync.Mutex
+
 	// buildingBrickGroup is a group of bricks that are being built, indexed by LiveID.
 	buildingBrickGroup singleflight.Group
 
@@ -64,6 +210,112 @@ type BrickManager struct {
 
 	// liveIDConstraint is a flag to control whether the constraint that all instances of the same brick type must have one liveID set to typeID is enabled.
 	liveIDConstraint bool
+
+	// valueCopyChecks is a flag to control whether value-type bricks holding a lock are rejected when they are registered.
+	valueCopyChecks atomic.Bool
+
+	// failFastOnConfig is a flag to control whether a config for an unregistered brick type is rejected when it is added.
+	failFastOnConfig atomic.Bool
+	// bindingWarnings stores the interface binding errors already logged by checkConfig.
+	bindingWarnings     map[string]bool
+	bindingWarningsLock sync.Mutex
+
+	// activeProfiles are the profiles whose lives are added, see SetActiveProfiles.
+	activeProfiles []string
+	// flagResolver resolves the `${flag:name}` placeholders of the configs, see SetFlagResolver.
+	flagResolver func(name string) (string, bool)
+
+	// liveIDAliases maps a human-friendly alias to its canonical liveID.
+	liveIDAliases     map[string]string
+	liveIDAliasesLock sync.RWMutex
+
+	// builtConfigs stores the env-expanded json config each instance was built with, indexed by LiveID.
+	builtConfigs     map[string][]byte
+	builtConfigsLock sync.RWMutex
+
+	// disabledTypes stores the typeIDs disabled at runtime,
+	// disabledFallback stores the instance returned in place of a disabled brick, both indexed by TypeID.
+	disabledTypes     map[string]bool
+	disabledFallback  map[string]reflect.Value
+	disabledTypesLock sync.RWMutex
+
+	// providers stores functions supplying non-brick values, indexed by LiveID.
+	providers     map[string]func() reflect.Value
+	providersLock sync.RWMutex
+
+	// reloadEvents receives an event every time a config file reload completes.
+	reloadEvents chan ReloadEvent
+
+	// deferredBuilds stores the pending constructions of deferred bricks, indexed by LiveID.
+	deferredBuilds     map[string]*deferredBuild
+	deferredBuildsLock sync.RWMutex
+
+	// configMergeKeys stores the merge keys set by SetConfigMergeKey, indexed by TypeID then path.
+	configMergeKeys     map[string]map[string]string
+	configMergeKeysLock sync.RWMutex
+
+	// configTransforms stores the functions transforming every live config when it is added, in registration order.
+	configTransforms     []ConfigTransform
+	configTransformsLock sync.RWMutex
+
+	// selectors stores the functions selecting the liveID injected into `selected` fields, indexed by interface type.
+	selectors     map[reflect.Type]func(ctx context.Context) string
+	selectorsLock sync.RWMutex
+
+	// cleanups stores the functions run by Shutdown after the bricks are closed, in registration order.
+	cleanups     []func() error
+	cleanupsLock sync.Mutex
+
+	// closedBricks stores the instances closed by Shutdown.
+	// The instances are kept so that their pointers are not reused by new instances.
+	closedBricks map[closedBrickKey]reflect.Value
+	// shutdownLock serializes the calls of Shutdown.
+	shutdownLock sync.Mutex
+
+	// startedBricks stores the instances started by StartAll, the instances are kept like closedBricks.
+	startedBricks map[closedBrickKey]reflect.Value
+	// startLock serializes the calls of StartAll.
+	startLock sync.Mutex
+
+	// goroutineScopes stores the scopes opened by BeginGoroutineScope, indexed by goroutine ID.
+	goroutineScopes     map[uint64]*Scope
+	goroutineScopesLock sync.RWMutex
+
+	// buildStats stores the construction statistics, indexed by TypeID.
+	buildStats     map[string]*BuildStat
+	buildStatsLock sync.Mutex
+
+	// inFlightBuilds stores the number of constructions in progress, indexed by LiveID.
+	inFlightBuilds     map[string]int
+	inFlightBuildsLock sync.Mutex
+
+	// fallbackFactories stores the factories used when NewBrick panics, indexed by TypeID.
+	fallbackFactories     map[string]func() Brick
+	fallbackFactoriesLock sync.RWMutex
+
+	// degraded stores the liveIDs whose instance was built by a fallback factory.
+	degraded     map[string]bool
+	degradedLock sync.RWMutex
+
+	// mutationDetection is a flag to control whether the shared instances are checked for mutations, see SetMutationDetection.
+	mutationDetection atomic.Bool
+	// instanceHashes stores the hash of the exported fields of each instance when it was saved, indexed by LiveID.
+	instanceHashes     map[string]uint64
+	instanceHashesLock sync.Mutex
+
+	// prototypes stores the typeIDs registered by RegisterPrototype.
+	prototypes     map[string]bool
+	prototypesLock sync.RWMutex
+
+	// configDedup shares the clones and the prototypes built with the same config, see SetConfigDedup.
+	configDedup atomic.Bool
+	// dedupInstances stores the instances shared by SetConfigDedup.
+	dedupInstances     map[dedupKey]dedupInstance
+	dedupInstancesLock sync.RWMutex
+
+	// scopedTypes stores the typeIDs registered by RegisterScoped.
+	scopedTypes     map[string]bool
+	scopedTypesLock sync.RWMutex
 }
 
 // BrickConfig holds the configuration for a single brick instance.
@@ -75,6 +327,17 @@ type BrickConfig struct {
 	// NoCheck is a flag to control whether the configuration should be checked.
 	noCheck bool
 	Config  any
+	// Weight is the relative weight of the live when a `weighted` tag picks one live of the type, 0 if not given.
+	Weight int
+	// Order is the position of the live in a `group` slice, lives are sorted by order then liveID.
+	Order int
+	// Disabled keeps the config of the live, but the live is never built, e.g. to turn off an instance temporarily.
+	// A disabled live is skipped by `group`, `all`, `weighted` and `configs` fields and by the liveID constraint.
+	Disabled bool
+	// filePath is the config file the configuration was loaded from, empty if no file backs it.
+	filePath string
+	// clone is true if the config is a copy stored under a random liveID for a clone, e.g. by CloneConfig.
+	clone bool
 }
 
 // BrickFileConfig defines the structure of a brick configuration file.
@@ -84,9 +347,18 @@ type BrickFileConfig struct {
 		TypeID  string `json:"typeID" yaml:"typeID" toml:"typeID"`
 		NoCheck bool   `json:"noCheck" yaml:"noCheck" toml:"noCheck"`
 	} `json:"metaData" yaml:"metaData" toml:"metaData"`
-	Lives []struct {
+	// SharedConfig is merged under the config of every live, the values of a live take precedence.
+	SharedConfig any `json:"sharedConfig,omitempty" yaml:"sharedConfig,omitempty" toml:"sharedConfig,omitempty"`
+	Lives        []struct {
 		LiveID string `json:"liveID" yaml:"liveID" toml:"liveID"`
 		Config any    `json:"config" yaml:"config" toml:"config"`
+		// Weight is nil if not given, a given weight must be positive. Disable the live to take it out of a `weighted` rotation.
+		Weight *int `json:"weight,omitempty" yaml:"weight,omitempty" toml:"weight,omitempty"`
+		Order  int  `json:"order,omitempty" yaml:"order,omitempty" toml:"order,omitempty"`
+		// Disabled keeps the config of the live without building it.
+		Disabled bool `json:"disabled,omitempty" yaml:"disabled,omitempty" toml:"disabled,omitempty"`
+		// Profiles are the profiles the live is active in, see SetActiveProfiles. A live without profiles is always active.
+		Profiles []string `json:"profiles,omitempty" yaml:"profiles,omitempty" toml:"profiles,omitempty"`
 	} `json:"lives" yaml:"lives" toml:"lives"`
 }
 
@@ -105,6 +377,46 @@ type BrickNewer interface {
 	NewBrick(jsonConfig []byte) Brick
 }
 
+// BrickNewerCtx is implemented by bricks whose construction needs the build context, e.g. to start a tracing span
+// as a child of the caller's span, or to respect its deadline. When implemented, NewBrickCtx is preferred over NewBrick.
+type BrickNewerCtx interface {
+	Brick
+	// NewBrickCtx parses the configuration and returns a new instance of the brick.
+	// ctx is the context passed to GetCtx/GetOrCreateCtx by the caller that triggered the build,
+	// or context.Background(). It is the build context, not a request context.
+	NewBrickCtx(ctx context.Context, jsonConfig []byte) (Brick, error)
+}
+
+// BrickCloser is implemented by bricks that release resources on Shutdown.
+type BrickCloser interface {
+	Close() error
+}
+
+// BrickReloader is implemented by bricks that apply a new config in place, keeping their pointer identity,
+// when their config file is reloaded by ReloadConfigFile. jsonConfig is the new config of the live.
+type BrickReloader interface {
+	BrickReload(jsonConfig []byte) error
+}
+
+// BrickStarter is implemented by server-like bricks that begin serving once they are built, see StartAll.
+type BrickStarter interface {
+	Start(ctx context.Context) error
+}
+
+// BrickIniter is implemented by bricks that validate their dependencies or open connections once they are injected.
+// BrickInit is called once per instance, after its dependencies are injected and before it is saved.
+// If it returns an error, the build panics, and the instance is not saved.
+type BrickIniter interface {
+	BrickInit() error
+}
+
+// BrickConfigValidator is implemented by bricks that validate their configuration before a reload is applied.
+type BrickConfigValidator interface {
+	Brick
+	// ValidateConfig validates the configuration of a live, jsonConfig is nil if the live does not provide one.
+	ValidateConfig(jsonConfig []byte) error
+}
+
 type BrickLives interface {
 	BrickNewer
 	// Used when multiple different instances of a type depend on different instances of the same type.
@@ -120,11 +432,19 @@ var brickLivesInterfaceType = reflect.TypeOf((*BrickLives)(nil)).Elem()
 
 // }
 
-func getBrickLives(typ reflect.Type) ([]Live, bool) {
+// getBrickLives returns the lives of the brick type, from its BrickLives method and from RegisterWithLives.
+func (b *BrickManager) getBrickLives(typ reflect.Type) ([]Live, bool) {
+	var lives []Live
+	ok := false
+	if typeID, registered := b.typeIDOf(typ); registered {
+		b.brickLivesLock.RLock()
+		lives, ok = b.brickLives[typeID]
+		b.brickLivesLock.RUnlock()
+	}
 	if typ.Kind() != reflect.Ptr {
 		if typ.Implements(brickLivesInterfaceType) {
 			instance := reflect.New(typ).Interface().(BrickLives)
-			return instance.BrickLives(), true
+			return append(instance.BrickLives(), lives...), true
 		}
 	}
 	for typ.Kind() == reflect.Ptr {
@@ -132,10 +452,10 @@ func getBrickLives(typ reflect.Type) ([]Live, bool) {
 	}
 	typPtr := reflect.PointerTo(typ)
 	if !typPtr.Implements(brickLivesInterfaceType) {
-		return nil, false
+		return lives, ok
 	}
 	instance := reflect.New(typ).Interface().(BrickLives)
-	return instance.BrickLives(), true
+	return append(instance.BrickLives(), lives...), true
 }
 
 // SetLiveIDConstraint sets the constraint that all instances of the same brick type must have one liveID set to typeID.
@@ -143,7 +463,144 @@ func SetLiveIDConstraint(constraint bool) {
 	brickManager.liveIDConstraint = constraint
 }
 
+// SetActiveProfiles sets the active profiles, e.g. `prod`. The lives of a config whose profiles are all inactive
+// are ignored when the config is added or reloaded, as if they were not in it, so they can share the liveID of
+// the lives of another profile. Lives without profiles are always active. Configs added before are not affected.
+func SetActiveProfiles(profiles ...string) {
+	brickManager.activeProfiles = profiles
+}
+
+// BrickTag is the parsed value of a `brick` tag.
+type BrickTag struct {
+	// LiveID is the liveID of the dependency, empty for the default live.
+	LiveID string
+	// LiveIDs is the liveID list of a slice field, e.g. `brick:"logger1;logger2"`.
+	LiveIDs []string
+	// TypeID is the typeID of the dependency, or of the lives to pick from if Weighted is true.
+	TypeID string
+	// Clone is true if the dependency is a clone, e.g. `brick:"clone:liveID"`.
+	Clone bool
+	// DeepClone is true if the dependencies of the clone are cloned too, e.g. `brick:"deepclone:liveID"`, Clone is also true.
+	DeepClone bool
+	// Random is true if the dependency has a random liveID, e.g. `brick:"random"`.
+	Random bool
+	// Group is true if the slice is filled with every live of the element type, e.g. `brick:"group"`.
+	Group bool
+	// All is true if the slice is filled with every configured live of the element type, e.g. `brick:"all"`.
+	All bool
+	// Optional is true if the field is left zero when the dependency is missing, e.g. `brick:"liveID,optional"`.
+	Optional bool
+	// NonEmpty is true if the group requires at least one member, e.g. `brick:"group,nonempty"`.
+	NonEmpty bool
+	// Weighted is true if one live of the type is picked by weight, e.g. `brick:"typeID,weighted"`.
+	Weighted bool
+	// Provider is true if the value comes from a provider, e.g. `brick:"liveID,provider"`.
+	Provider bool
+	// Deferred is true if the construction of the dependency is deferred, e.g. `brick:"liveID,deferred"`.
+	Deferred bool
+	// Configs is true if the slice is filled with the live configs of the type, e.g. `brick:"typeID,configs"`.
+	Configs bool
+	// TypeLiveIDs is true if the slice is filled with the configured liveIDs of the type, e.g. `brick:"typeID,liveids"`.
+	TypeLiveIDs bool
+	// NoDefault is true if the liveIDs exclude the default live, e.g. `brick:"typeID,liveids,nodefault"`.
+	NoDefault bool
+	// Rewireable is true if the field is updated when its dependency is replaced, e.g. `brick:"liveID,rewireable"`.
+	Rewireable bool
+	// Selected is true if the live is chosen by the selector of the interface type, e.g. `brick:",selected"`.
+	Selected bool
+	// From is the name of the string field holding the liveID, e.g. `brick:"from:Driver"`.
+	From string
+	// Method is the name of the method of the brick computing the field, e.g. `brick:"method:BuildCache"`.
+	Method string
+	// Secret is the key of an injected secret, e.g. `brick:"secret:DB_TOKEN"`.
+	Secret string
+	// Refresh is true if the secret is resolved on every access, e.g. `brick:"secret:DB_TOKEN,refresh"`.
+	Refresh bool
+}
+
+// ParseBrickTag parses the value of a `brick` tag.
+func ParseBrickTag(tag string) BrickTag {
+	tag, optional := splitOptionalTag(tag)
+	liveID, typeID, isClone, isRandomLiveID := brickManager.parseTag(tag)
+	ret := BrickTag{
+		LiveID:    liveID,
+		TypeID:    typeID,
+		Clone:     isClone,
+		DeepClone: isDeepCloneTag(tag),
+		Random:    isRandomLiveID,
+		Optional:  optional,
+	}
+	switch typeID {
+	case weightedTag:
+		ret.Weighted = true
+		ret.TypeID, ret.LiveID = liveID, ""
+	case providerTag:
+		ret.Provider = true
+		ret.TypeID = ""
+	case nonemptyTag:
+		ret.NonEmpty = true
+		ret.TypeID = ""
+	case deferredTag:
+		ret.Deferred = true
+		ret.TypeID = ""
+	case configsTag:
+		ret.Configs = true
+		ret.TypeID, ret.LiveID = liveID, ""
+	case liveIDsTag:
+		ret.TypeLiveIDs = true
+		ret.NoDefault = hasTagOption(tag, noDefaultTag)
+		ret.TypeID, ret.LiveID = liveID, ""
+	case rewireableTag:
+		ret.Rewireable = true
+		ret.TypeID = ""
+	case selectedTag:
+		ret.Selected = true
+		ret.TypeID, ret.LiveID = "", ""
+	}
+	if typeID == refreshTag {
+		ret.Refresh = true
+		ret.TypeID = ""
+	}
+	if key, ok := secretTagKey(liveID); ok {
+		ret.Secret = key
+		ret.LiveID = ""
+	} else if field, ok := fromTagField(liveID); ok {
+		ret.From = field
+		ret.LiveID = ""
+	} else if method, ok := methodTagName(liveID); ok {
+		ret.Method = method
+		ret.LiveID = ""
+	} else if liveID == groupTag {
+		ret.Group = true
+		ret.LiveID = ""
+	} else if liveID == allTag {
+		ret.All = true
+		ret.LiveID = ""
+	} else if strings.Contains(liveID, ";") {
+		ret.LiveIDs = splitLiveIDList(liveID)
+		ret.LiveID = ""
+	}
+	return ret
+}
+
+// SetFailFastOnConfig sets whether adding a config fails immediately, instead of at the first Get,
+// when a live provides config for a brick type that is not registered.
+// Configs of brick types registered without a config parser are always rejected when they are added.
+// Configs with `noCheck: true` are not checked.
+// It also makes the first Get panic on the errors of CheckInterfaceBindings, which are otherwise logged once.
+func SetFailFastOnConfig(failFast bool) {
+	brickManager.failFastOnConfig.Store(failFast)
+}
+
+// SetValueCopyChecks sets whether registering a value-type brick panics if the brick holds a value unsafe to copy,
+// e.g. a sync.Mutex, a sync.WaitGroup or any type with a noCopy marker, since Get returns a copy of a value-type brick.
+// The check is done on the registration, bricks registered before are not checked.
+func SetValueCopyChecks(check bool) {
+	brickManager.valueCopyChecks.Store(check)
+}
+
 func (b *BrickManager) parseTag(tag string) (liveID string, typeID string, isClone bool, isRandomLiveID bool) {
+	tag, _ = splitTagModifiers(tag)
 	if tag == "random" {
 		isRandomLiveID = true
 		return
@@ -161,9 +618,74 @@ func (b *BrickManager) parseTag(tag string) (liveID string, typeID string, isClo
 			liveID = ""
 		}
 	}
+	if isDeepCloneTag(tag) {
+		isClone = true
+		liveID = strings.TrimPrefix(liveID, deepCloneTagPrefix)
+		if liveID == strings.TrimSuffix(deepCloneTagPrefix, ":") {
+			liveID = ""
+		}
+	}
 	return
 }
 
+// isDeepCloneTag reports whether the tag clones the dependency with its dependency subtree, e.g. `brick:"deepclone:liveID"`.
+func isDeepCloneTag(tag string) bool {
+	return strings.HasPrefix(tag, deepCloneTagPrefix) || tag == strings.TrimSuffix(deepCloneTagPrefix, ":")
+}
+
+// fromTagField returns the field name of a `from:Field` liveID.
+func fromTagField(liveID string) (string, bool) {
+	return strings.CutPrefix(liveID, fromTagPrefix)
+}
+
+// methodTagName returns the method name of a `method:Name` liveID.
+func methodTagName(liveID string) (string, bool) {
+	return strings.CutPrefix(liveID, methodTagPrefix)
+}
+
+// isTagOption reports whether the second component of a `brick` tag is an option rather than a typeID.
+func isTagOption(typeID string) bool {
+	switch typeID {
+	case weightedTag, providerTag, deferredTag, configsTag, liveIDsTag, rewireableTag, selectedTag, nonemptyTag, refreshTag, optionalTag:
+		return true
+	}
+	return false
+}
+
+// asInterface returns the instance as an I, dereferencing its pointer layers until one implements I.
+func asInterface[I any](instance reflect.Value) (I, bool) {
+	for {
+		if i, ok := instance.Interface().(I); ok {
+			return i, true
+		}
+		if instance.Kind() != reflect.Ptr || instance.IsNil() {
+			return *new(I), false
+		}
+		instance = instance.Elem()
+	}
+}
+
+// isBrickType reports whether the type, or a pointer to it, implements Brick.
+func isBrickType(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	brickType := reflect.TypeOf((*Brick)(nil)).Elem()
+	return typ.Implements(brickType) || reflect.PointerTo(typ).Implements(brickType)
+}
+
+// splitLiveIDList splits a semicolon-separated liveID list, e.g. `brick:"logger1;logger2"`.
+func splitLiveIDList(liveIDs string) []string {
+	var ret []string
+	for _, liveID := range strings.Split(liveIDs, ";") {
+		liveID = strings.TrimSpace(liveID)
+		if liveID != "" {
+			ret = append(ret, liveID)
+		}
+	}
+	return ret
+}
+
 func (b *BrickManager) getTypeIDByReflectType(typ reflect.Type) string {
 	for typ.Kind() == reflect.Ptr {
 		typ = typ.Elem()
@@ -177,6 +699,10 @@ func (b *BrickManager) getTypeIDByReflectType(typ reflect.Type) string {
 	if ok {
 		return brick.BrickTypeID()
 	}
+	// A config brick registered by RegisterConfigBrick.
+	if typeID, ok := b.typeIDOf(typ); ok {
+		return typeID
+	}
 	panic(fmt.Errorf("type %s is not a brick", typ))
 }
 
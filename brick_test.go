@@ -1,11 +1,17 @@
 package brick
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
 	"os/exec"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func Test1(t *testing.T) {
@@ -33,6 +39,17 @@ func Test1(t *testing.T) {
 	if err != nil {
 		t.Fatal(string(out))
 	}
+	out, err = exec.Command("go", "test", "github.com/doraemonkeys/brick/test/test7").CombinedOutput()
+	if err != nil {
+		t.Fatal(string(out))
+	}
+	// brickplugin is a module of its own, its tests run from its directory.
+	cmd := exec.Command("go", "test", "./...")
+	cmd.Dir = "brickplugin"
+	out, err = cmd.CombinedOutput()
+	if err != nil {
+		t.Fatal(string(out))
+	}
 }
 
 type TestBrick1 struct {
@@ -203,7 +220,16 @@ func (t TestBrick52) BrickTypeID() string {
 
 func Test_RecursiveBrick(t *testing.T) {
 	t.Run("TestBrick5", func(t *testing.T) {
-		Register[*TestBrick5]()
+		func() {
+			defer func() {
+				r := recover()
+				err, _ := r.(error)
+				if !errors.Is(err, ErrCircularDependency) || !strings.Contains(err.Error(), "TestBrick5 -> TestBrick5") {
+					t.Errorf("Register() panic = %v, want the cycle TestBrick5 -> TestBrick5", r)
+				}
+			}()
+			Register[*TestBrick5]()
+		}()
 
 		defer func() {
 			r := recover()
@@ -244,6 +270,62 @@ func Test_RecursiveBrick(t *testing.T) {
 
 }
 
+type TestBrick76 struct {
+	T761 *TestBrick761 `brick:""`
+}
+
+func (t *TestBrick76) BrickTypeID() string {
+	return "TestBrick76"
+}
+
+type TestBrick761 struct {
+	T1  *TestBrick1  `brick:""`
+	T76 *TestBrick76 `brick:"TestBrick76 other"`
+}
+
+func (t *TestBrick761) BrickTypeID() string {
+	return "TestBrick761"
+}
+
+type TestBrick762 struct {
+	T763 Lazy[*TestBrick763] `brick:""`
+}
+
+func (t *TestBrick762) BrickTypeID() string {
+	return "TestBrick762"
+}
+
+type TestBrick763 struct {
+	T762 *TestBrick762 `brick:""`
+}
+
+func (t *TestBrick763) BrickTypeID() string {
+	return "TestBrick763"
+}
+
+func TestRegisterCircularDependency(t *testing.T) {
+	m := NewBrickManager()
+	func() {
+		defer func() {
+			r := recover()
+			err, _ := r.(error)
+			if !errors.Is(err, ErrCircularDependency) || !strings.Contains(err.Error(), "TestBrick76 -> TestBrick761 -> TestBrick76") {
+				t.Errorf("register() panic = %v, want the cycle TestBrick76 -> TestBrick761 -> TestBrick76", r)
+			}
+		}()
+		m.register2("TestBrick76", reflect.TypeOf(&TestBrick76{}))
+	}()
+	for _, typeID := range []string{"TestBrick76", "TestBrick761", "TestBrick1"} {
+		if typ, ok := m.getBrickType(typeID); ok {
+			t.Errorf("brick(%s) = %v is registered after the rejected registration", typeID, typ)
+		}
+	}
+
+	// A random or lazy dependency is not built with its dependent.
+	m.register2("TestBrick51", reflect.TypeOf(&TestBrick51{}))
+	m.register2("TestBrick762", reflect.TypeOf(&TestBrick762{}))
+}
+
 type TestBrick6 struct {
 	T1 *TestBrick1 `brick:""`
 	T3 TestBrick3  `brick:""`
@@ -447,3 +529,1117 @@ func Test_BaseBrick(t *testing.T) {
 		t.Errorf("b1.BrickLiveID = %v, want %v", b1.BrickLiveID(), GetBrickTypeID[*TestBrick71]())
 	}
 }
+
+type TestBrick10 struct {
+	BrickBase[*TestBrick10]
+}
+
+func (t *TestBrick10) BrickTypeID() string {
+	return "TestBrick10"
+}
+
+type TestBrick101 struct {
+	Chain  []*TestBrick10 `brick:"TestBrick10 auth;TestBrick10 log;TestBrick10 recover"`
+	Movers []TestMover    `brick:"TestBrick10 log;TestBrick10,TestBrick10"`
+}
+
+func (t *TestBrick101) BrickTypeID() string {
+	return "TestBrick101"
+}
+
+type TestMover interface {
+	BrickLiveID() string
+}
+
+func Test_SliceBrick(t *testing.T) {
+	Register[*TestBrick101]()
+	b := Get[*TestBrick101]()
+
+	want := []string{"TestBrick10 auth", "TestBrick10 log", "TestBrick10 recover"}
+	if len(b.Chain) != len(want) {
+		t.Fatalf("len(Chain) = %v, want %v", len(b.Chain), len(want))
+	}
+	for i, m := range b.Chain {
+		if m.BrickLiveID() != want[i] {
+			t.Errorf("Chain[%d] = %v, want %v", i, m.BrickLiveID(), want[i])
+		}
+	}
+	if b.Chain[1] != Get[*TestBrick10]("TestBrick10 log") {
+		t.Errorf("Chain[1] is not the singleton of its liveID")
+	}
+	if len(b.Movers) != 2 || b.Movers[0].BrickLiveID() != "TestBrick10 log" || b.Movers[1].BrickLiveID() != "TestBrick10" {
+		t.Errorf("Movers = %v", b.Movers)
+	}
+}
+
+type TestBrick12 struct {
+	Name string `json:"name"`
+}
+
+func (t *TestBrick12) BrickTypeID() string {
+	return "TestBrick12"
+}
+
+func (t *TestBrick12) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick12{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+type TestBrick121 struct {
+	T12 *TestBrick12 `brick:"TestBrick12,weighted"`
+	I12 any          `brick:"TestBrick12,weighted"`
+}
+
+func (t *TestBrick121) BrickTypeID() string {
+	return "TestBrick121"
+}
+
+func Test_WeightedBrick(t *testing.T) {
+	RegisterNewer[*TestBrick12]()
+	Register[*TestBrick121]()
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick12"},
+		"lives": [
+			{"liveID": "TestBrick12", "config": {"name": "a"}},
+			{"liveID": "TestBrick12 b", "config": {"name": "b"}, "weight": 2},
+			{"liveID": "TestBrick12 c", "config": {"name": "c"}, "weight": 7}
+		]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 5000
+	counts := map[string]int{}
+	for i := 0; i < n; i++ {
+		b := GetOrCreate[*TestBrick121](RandomLiveID())
+		counts[b.T12.Name]++
+		if _, ok := b.I12.(*TestBrick12); !ok {
+			t.Fatalf("I12 = %T, want *TestBrick12", b.I12)
+		}
+	}
+	want := map[string]float64{"a": 0.1, "b": 0.2, "c": 0.7}
+	for name, ratio := range want {
+		expected := ratio * n
+		if got := float64(counts[name]); got < expected*0.75 || got > expected*1.25 {
+			t.Errorf("live %s picked %v times, want about %v", name, got, expected)
+		}
+	}
+}
+
+func Test_WeightedBrickZeroWeight(t *testing.T) {
+	RegisterNewer[*TestBrick12]()
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick12"},
+		"lives": [
+			{"liveID": "TestBrick12", "config": {"name": "a"}},
+			{"liveID": "TestBrick12 zero", "config": {"name": "zero"}, "weight": 0}
+		]
+	}]`))
+	if err == nil || !strings.Contains(err.Error(), "must be positive") {
+		t.Errorf("addConfigFileJson() with a weight of 0 = %v, want an error", err)
+	}
+}
+
+type TestBrick14 struct{}
+
+func (t *TestBrick14) BrickTypeID() string {
+	return "TestBrick14"
+}
+
+func TestGetOrZero(t *testing.T) {
+	if got := GetOrZero[*TestBrick14](); got != nil {
+		t.Errorf("GetOrZero() = %v for an unregistered type, want nil", got)
+	}
+	Register[*TestBrick14]()
+	got := GetOrZero[*TestBrick14]()
+	if got == nil {
+		t.Fatalf("GetOrZero() = nil for a registered type")
+	}
+	if got != Get[*TestBrick14]() {
+		t.Errorf("GetOrZero() did not cache the instance")
+	}
+	if got := GetOrZero[*TestBrick14]("TestBrick14 undeclared"); got != nil {
+		t.Errorf("GetOrZero() = %v for an undeclared liveID, want nil", got)
+	}
+}
+
+type TestBrick141 struct{}
+
+func (t *TestBrick141) BrickTypeID() string {
+	return "TestBrick141"
+}
+
+func (t *TestBrick141) BrickInit() error {
+	return errors.New("init failed")
+}
+
+func TestGetOrZeroInitError(t *testing.T) {
+	Register[*TestBrick141]()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("GetOrZero() of a brick whose BrickInit fails did not panic")
+		}
+	}()
+	GetOrZero[*TestBrick141]()
+}
+
+type TestBrick15 struct {
+	Now       time.Time        `brick:"TestBrick15 clock,provider"`
+	RequestID string           `brick:"TestBrick15 requestID,provider"`
+	Clock     func() time.Time `brick:"TestBrick15 func clock,provider"`
+}
+
+func (t *TestBrick15) BrickTypeID() string {
+	return "TestBrick15"
+}
+
+func TestProvider(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	RegisterProvider("TestBrick15 clock", func() time.Time { return now }, true)
+	RegisterProvider("TestBrick15 func clock", func() func() time.Time {
+		return func() time.Time { return now }
+	}, true)
+	var id int
+	RegisterProvider("TestBrick15 requestID", func() string {
+		id++
+		return fmt.Sprintf("request-%d", id)
+	}, false)
+	Register[*TestBrick15]()
+
+	b := Get[*TestBrick15]()
+	if !b.Now.Equal(now) || !b.Clock().Equal(now) {
+		t.Errorf("Now = %v, Clock() = %v, want %v", b.Now, b.Clock(), now)
+	}
+	b2 := GetOrCreate[*TestBrick15]("TestBrick15 2")
+	if b.RequestID != "request-1" || b2.RequestID != "request-2" {
+		t.Errorf("RequestID = %v, %v, want request-1, request-2", b.RequestID, b2.RequestID)
+	}
+}
+
+type TestHandler17 interface {
+	Handle17() string
+}
+
+type TestPlugin17 interface {
+	Plugin17()
+}
+
+type TestBrick17 struct{}
+
+func (t *TestBrick17) BrickTypeID() string {
+	return "TestBrick17"
+}
+
+func (t *TestBrick17) Handle17() string {
+	return "TestBrick17"
+}
+
+type TestBrick171 struct{}
+
+func (t TestBrick171) BrickTypeID() string {
+	return "TestBrick171"
+}
+
+func (t TestBrick171) Handle17() string {
+	return "TestBrick171"
+}
+
+type TestBrick172 struct {
+	Handlers []TestHandler17 `brick:"group,nonempty"`
+	Bricks   []*TestBrick17  `brick:"group"`
+}
+
+func (t *TestBrick172) BrickTypeID() string {
+	return "TestBrick172"
+}
+
+type TestBrick173 struct {
+	Plugins []TestPlugin17 `brick:"group,nonempty"`
+}
+
+func (t *TestBrick173) BrickTypeID() string {
+	return "TestBrick173"
+}
+
+func Test_GroupBrick(t *testing.T) {
+	Register[*TestBrick17]()
+	Register[TestBrick171]()
+	Register[*TestBrick172]()
+	Register[*TestBrick173]()
+
+	b := Get[*TestBrick172]()
+	if len(b.Handlers) != 2 || b.Handlers[0].Handle17() != "TestBrick17" || b.Handlers[1].Handle17() != "TestBrick171" {
+		t.Errorf("Handlers = %v", b.Handlers)
+	}
+	if len(b.Bricks) != 1 || b.Bricks[0] != Get[*TestBrick17]() {
+		t.Errorf("Bricks = %v", b.Bricks)
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatalf("expected panic, but no panic")
+		}
+		if !strings.Contains(fmt.Sprint(r), "TestPlugin17") {
+			t.Errorf("panic %v does not name the element type", r)
+		}
+	}()
+	Get[*TestBrick173]()
+}
+
+type TestBrick19 struct {
+	DB     *TestBrick191 `brick:""`
+	Logger *TestBrick192 `brick:"TestBrick19 logger"`
+	Cache  *TestBrick192 `brick:""`
+}
+
+func (t *TestBrick19) BrickTypeID() string {
+	return "TestBrick19"
+}
+
+type TestBrick191 struct {
+	Mock bool
+}
+
+func (t *TestBrick191) BrickTypeID() string {
+	return "TestBrick191"
+}
+
+type TestBrick192 struct {
+	Name string
+}
+
+func (t *TestBrick192) BrickTypeID() string {
+	return "TestBrick192"
+}
+
+func TestGetWith(t *testing.T) {
+	Register[*TestBrick19]()
+	mockDB := &TestBrick191{Mock: true}
+	mockLogger := &TestBrick192{Name: "mock logger"}
+
+	b := GetWith[*TestBrick19](map[string]Brick{
+		"DB":                 mockDB,
+		"TestBrick19 logger": mockLogger,
+	})
+	if b.DB != mockDB {
+		t.Errorf("DB = %v, want the mock", b.DB)
+	}
+	if b.Logger != mockLogger {
+		t.Errorf("Logger = %v, want the mock", b.Logger)
+	}
+	if b.Cache != Get[*TestBrick192]() {
+		t.Errorf("Cache is not the singleton")
+	}
+
+	singleton := Get[*TestBrick19]()
+	if singleton == b {
+		t.Errorf("GetWith() instance was cached")
+	}
+	if singleton.DB == mockDB || singleton.Logger == mockLogger {
+		t.Errorf("overrides leaked into the singleton")
+	}
+	if b2 := GetWith[*TestBrick19](nil); b2 == b || b2 == singleton {
+		t.Errorf("GetWith() returned a cached instance")
+	}
+}
+
+func TestConvertInstance(t *testing.T) {
+	instance := &TestBrick1{Test: "1"}
+	var nilInstance *TestBrick1
+	tests := []struct {
+		name      string
+		instance  reflect.Value
+		target    reflect.Type
+		wantPanic string
+	}{
+		{name: "remove layer", instance: reflect.ValueOf(instance), target: reflect.TypeOf(TestBrick1{})},
+		{name: "add layers", instance: reflect.ValueOf(instance), target: reflect.TypeOf((***TestBrick1)(nil))},
+		{name: "interface", instance: reflect.ValueOf(&instance), target: reflect.TypeOf((*Brick)(nil)).Elem()},
+		{name: "different base type", instance: reflect.ValueOf(instance), target: reflect.TypeOf((**TestBrick2)(nil)), wantPanic: "different base types"},
+		{name: "nil layer", instance: reflect.ValueOf(&nilInstance), target: reflect.TypeOf(TestBrick1{}), wantPanic: "nil pointer layer"},
+		{name: "not implemented", instance: reflect.ValueOf(instance), target: reflect.TypeOf((*TestMover)(nil)).Elem(), wantPanic: "interface not implemented"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if tt.wantPanic == "" {
+					if r != nil {
+						t.Errorf("unexpected panic: %v", r)
+					}
+					return
+				}
+				msg := fmt.Sprint(r)
+				if !strings.Contains(msg, tt.wantPanic) || !strings.Contains(msg, "liveID(test liveID)") || !strings.Contains(msg, "cannot convert stored instance") {
+					t.Errorf("panic = %v, want %v", r, tt.wantPanic)
+				}
+			}()
+			got := convertInstance(tt.instance, tt.target, "test liveID")
+			if tt.target.Kind() != reflect.Interface && got.Type() != tt.target {
+				t.Errorf("convertInstance() type = %v, want %v", got.Type(), tt.target)
+			}
+		})
+	}
+}
+
+type TestBrick22 struct {
+	Name string `json:"name"`
+}
+
+func (t *TestBrick22) BrickTypeID() string {
+	return "TestBrick22"
+}
+
+func (t *TestBrick22) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick22{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+type TestBrick22Config struct {
+	Name  string `json:"name"`
+	Quota int    `json:"quota"`
+}
+
+type TestBrick221 struct {
+	Tenants    []TestBrick22Config  `brick:"TestBrick22,configs"`
+	TenantPtrs []*TestBrick22Config `brick:"TestBrick22,configs"`
+}
+
+func (t *TestBrick221) BrickTypeID() string {
+	return "TestBrick221"
+}
+
+type TestBrick222 struct {
+	Tenants []struct {
+		Quota string `json:"quota"`
+	} `brick:"TestBrick22,configs"`
+}
+
+func (t *TestBrick222) BrickTypeID() string {
+	return "TestBrick222"
+}
+
+func Test_ConfigsInjection(t *testing.T) {
+	t.Setenv("TEST_BRICK22_NAME", "from env")
+	RegisterNewer[*TestBrick22]()
+	Register[*TestBrick221]()
+	Register[*TestBrick222]()
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick22"},
+		"lives": [
+			{"liveID": "TestBrick22 b", "config": {"name": "b", "quota": 2}},
+			{"liveID": "TestBrick22", "config": {"name": "a", "quota": 1}},
+			{"liveID": "TestBrick22 c", "config": {"name": "${TEST_BRICK22_NAME}", "quota": 3}}
+		]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := Get[*TestBrick221]()
+	want := []TestBrick22Config{{"a", 1}, {"b", 2}, {"from env", 3}}
+	if !reflect.DeepEqual(b.Tenants, want) {
+		t.Errorf("Tenants = %v, want %v", b.Tenants, want)
+	}
+	if len(b.TenantPtrs) != len(want) {
+		t.Fatalf("TenantPtrs = %v, want %d configs", b.TenantPtrs, len(want))
+	}
+	for i, p := range b.TenantPtrs {
+		if *p != want[i] {
+			t.Errorf("TenantPtrs[%d] = %v, want %v", i, *p, want[i])
+		}
+	}
+
+	defer func() {
+		err := recover()
+		if err == nil || !strings.Contains(fmt.Sprint(err), "live(TestBrick22)") {
+			t.Errorf("recover() = %v, want an error naming the live", err)
+		}
+	}()
+	Get[*TestBrick222]()
+}
+
+type TestBrick32 struct {
+	T1 *TestBrick1 `brick:""`
+}
+
+func (t *TestBrick32) BrickTypeID() string {
+	return "TestBrick32"
+}
+
+type TestBrick32Alias = TestBrick32
+
+type TestBrick32Defined TestBrick32
+
+type TestBrick321 struct {
+	Alias        *TestBrick32Alias   `brick:""`
+	Defined      *TestBrick32Defined `brick:",TestBrick32"`
+	DefinedValue TestBrick32Defined  `brick:",TestBrick32"`
+}
+
+func (t *TestBrick321) BrickTypeID() string {
+	return "TestBrick321"
+}
+
+func Test_WrapperTypeBrick(t *testing.T) {
+	Register[*TestBrick32]()
+	Register[*TestBrick321]()
+	b := Get[*TestBrick321]()
+	base := Get[*TestBrick32]()
+	if b.Alias != base {
+		t.Errorf("Alias = %p, want the instance %p", b.Alias, base)
+	}
+	if (*TestBrick32)(b.Defined) != base {
+		t.Errorf("Defined = %p, want the instance %p", b.Defined, base)
+	}
+	if b.DefinedValue.T1 == nil || b.DefinedValue.T1 != base.T1 {
+		t.Errorf("DefinedValue = %+v, want a copy of the instance", b.DefinedValue)
+	}
+}
+
+type TestBrick34 struct {
+	Name string `json:"name"`
+}
+
+func (t *TestBrick34) BrickTypeID() string {
+	return "TestBrick34"
+}
+
+func (t *TestBrick34) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick34{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+type TestBrick341 struct {
+	Chain []*TestBrick34 `brick:"group"`
+}
+
+func (t *TestBrick341) BrickTypeID() string {
+	return "TestBrick341"
+}
+
+func Test_GroupOrder(t *testing.T) {
+	RegisterNewer[*TestBrick34]()
+	Register[*TestBrick341]()
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick34"},
+		"lives": [
+			{"liveID": "TestBrick34", "config": {"name": "auth"}, "order": 2},
+			{"liveID": "TestBrick34 a", "config": {"name": "recover"}, "order": -1},
+			{"liveID": "TestBrick34 b", "config": {"name": "logging"}},
+			{"liveID": "TestBrick34 c", "config": {"name": "metrics"}}
+		]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, h := range Get[*TestBrick341]().Chain {
+		got = append(got, h.Name)
+	}
+	if want := []string{"recover", "logging", "metrics", "auth"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Chain = %v, want %v", got, want)
+	}
+}
+
+type TestBrick40 struct {
+	Name string      `json:"name"`
+	T1   *TestBrick1 `brick:""`
+}
+
+func (t *TestBrick40) BrickTypeID() string {
+	return "TestBrick40"
+}
+
+func (t *TestBrick40) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick40{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func Test_RegisterLiveFactory(t *testing.T) {
+	RegisterNewer[*TestBrick40]()
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick40"},
+		"lives": [
+			{"liveID": "TestBrick40", "config": {"name": "normal"}},
+			{"liveID": "TestBrick40 double", "config": {"name": "configured"}}
+		]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	RegisterLiveFactory("TestBrick40 double", func(config []byte) Brick {
+		var c struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(config, &c); err != nil {
+			panic(err)
+		}
+		return &TestBrick40{Name: "double of " + c.Name}
+	})
+	RegisterLiveFactory("TestBrick40 mismatch", func(config []byte) Brick {
+		return &TestBrick1{}
+	})
+
+	if got := Get[*TestBrick40]().Name; got != "normal" {
+		t.Errorf("Name = %s, want normal", got)
+	}
+	double := Get[*TestBrick40]("TestBrick40 double")
+	if double.Name != "double of configured" {
+		t.Errorf("Name = %s, want the live factory result", double.Name)
+	}
+	if double.T1 == nil {
+		t.Errorf("the dependencies of the live factory result are not injected")
+	}
+
+	defer func() {
+		err := recover()
+		if err == nil || !strings.Contains(fmt.Sprint(err), "factory of live(TestBrick40 mismatch)") {
+			t.Errorf("recover() = %v, want a type mismatch error", err)
+		}
+	}()
+	Get[*TestBrick40]("TestBrick40 mismatch")
+}
+
+type TestBrick64 struct{}
+
+func (t *TestBrick64) BrickTypeID() string {
+	return "TestBrick64"
+}
+
+// TestBrick641 copies the typeID of TestBrick64 by mistake.
+type TestBrick641 struct{}
+
+func (t *TestBrick641) BrickTypeID() string {
+	return "TestBrick64"
+}
+
+type TestBrick642 struct{}
+
+func (t *TestBrick642) BrickTypeID() string {
+	return ""
+}
+
+func TestRegisterDuplicateTypeID(t *testing.T) {
+	Register[*TestBrick64]()
+	// Registering the same type again is fine.
+	Register[*TestBrick64]()
+
+	expectPanic := func(name string, want string, fn func()) {
+		t.Helper()
+		defer func() {
+			r := recover()
+			if r == nil || !strings.Contains(fmt.Sprint(r), want) {
+				t.Errorf("%s: panic = %v, want a panic containing %q", name, r, want)
+			}
+		}()
+		fn()
+	}
+	expectPanic("duplicate typeID", "brick typeID(TestBrick64) is used by both *brick.TestBrick64 and *brick.TestBrick641", Register[*TestBrick641])
+	expectPanic("empty typeID", "the BrickTypeID of brick type *brick.TestBrick642 is empty", Register[*TestBrick642])
+}
+
+// TestBrick67 and TestBrick671 are registered as value types by register2,
+// since a value receiver copying a lock is reported by `go vet`.
+type TestBrick67 struct {
+	sync.Mutex
+	Count int
+}
+
+func (t *TestBrick67) BrickTypeID() string {
+	return "TestBrick67"
+}
+
+type TestBrick671 struct {
+	Inner struct {
+		wg [1]sync.WaitGroup
+	}
+}
+
+func (t *TestBrick671) BrickTypeID() string {
+	return "TestBrick671"
+}
+
+type TestBrick672 struct {
+	mu *sync.Mutex
+}
+
+func (t TestBrick672) BrickTypeID() string {
+	return "TestBrick672"
+}
+
+func TestValueCopyChecks(t *testing.T) {
+	m := NewBrickManager()
+	m.valueCopyChecks.Store(true)
+
+	expectPanic := func(want string, fn func()) {
+		t.Helper()
+		defer func() {
+			r := recover()
+			if r == nil || !strings.Contains(fmt.Sprint(r), want) {
+				t.Errorf("panic = %v, want a panic containing %q", r, want)
+			}
+		}()
+		fn()
+	}
+	expectPanic("the value-type brick brick.TestBrick67 can't be copied safely, its field Mutex holds a lock", func() {
+		m.register2("TestBrick67", reflect.TypeOf(TestBrick67{}))
+	})
+	expectPanic("its field Inner.wg[] holds a lock", func() {
+		m.register2("TestBrick671", reflect.TypeOf(TestBrick671{}))
+	})
+	if _, ok := m.getBrickType("TestBrick67"); ok {
+		t.Error("the rejected brick type is registered")
+	}
+	// The pointer type and a pointer to a lock are safe to copy.
+	m.register2("TestBrick67", reflect.TypeOf(&TestBrick67{}))
+	m.register2("TestBrick672", reflect.TypeOf(TestBrick672{}))
+}
+
+type TestLogger68 interface {
+	LoggerName() string
+}
+
+type TestBrick68 struct {
+	Name string `json:"name"`
+}
+
+func (t *TestBrick68) BrickTypeID() string {
+	return "TestBrick68"
+}
+
+func (t *TestBrick68) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick68{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func (t *TestBrick68) LoggerName() string {
+	return t.Name
+}
+
+// TestBrick681 implements TestLogger68 without configured live.
+type TestBrick681 struct{}
+
+func (t *TestBrick681) BrickTypeID() string {
+	return "TestBrick681"
+}
+
+func (t *TestBrick681) LoggerName() string {
+	return "TestBrick681"
+}
+
+type TestBrick682 struct {
+	Loggers    []*TestBrick68 `brick:"all"`
+	Interfaces []TestLogger68 `brick:"all"`
+}
+
+func (t *TestBrick682) BrickTypeID() string {
+	return "TestBrick682"
+}
+
+// addTestBrick68Configs registers TestBrick68 and adds its three lives once.
+var addTestBrick68Configs = sync.OnceValue(func() error {
+	RegisterNewer[*TestBrick68]()
+	Register[*TestBrick681]()
+	return brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick68"},
+		"lives": [
+			{"liveID": "TestBrick68 b", "config": {"name": "b"}, "order": -1},
+			{"liveID": "TestBrick68", "config": {"name": "default"}},
+			{"liveID": "TestBrick68 a", "config": {"name": "a"}}
+		]
+	}]`))
+})
+
+func Test_AllBrick(t *testing.T) {
+	if err := addTestBrick68Configs(); err != nil {
+		t.Fatal(err)
+	}
+	Register[*TestBrick682]()
+	b := Get[*TestBrick682]()
+	var got []string
+	for _, l := range b.Loggers {
+		got = append(got, l.Name)
+	}
+	if want := []string{"default", "a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Loggers = %v, want %v", got, want)
+	}
+	got = nil
+	for _, l := range b.Interfaces {
+		got = append(got, l.LoggerName())
+	}
+	if want := []string{"default", "a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Interfaces = %v, want %v", got, want)
+	}
+	if b.Loggers[1] != Get[*TestBrick68]("TestBrick68 a") {
+		t.Error("the slice does not hold the shared instances")
+	}
+}
+
+type TestBrick70 struct {
+	DSN string        `json:"dsn"`
+	T1  *TestBrick1   `brick:""`
+	T70 *TestBrick701 `brick:"TestBrick70 dep"`
+}
+
+func (t *TestBrick70) BrickTypeID() string {
+	return "TestBrick70"
+}
+
+func (t *TestBrick70) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick70{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+type TestBrick701 struct{}
+
+func (t *TestBrick701) BrickTypeID() string {
+	return "TestBrick701"
+}
+
+func (t *TestBrick701) BrickInit() error {
+	return errors.New("TestBrick701 is not expected to be built")
+}
+
+func Test_GetUninjected(t *testing.T) {
+	RegisterNewer[*TestBrick70]()
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick70"},
+		"lives": [{"liveID": "TestBrick70", "config": {"dsn": "postgres://localhost"}}]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := GetUninjected[*TestBrick70]()
+	if b.DSN != "postgres://localhost" {
+		t.Errorf("DSN = %q, want the config field", b.DSN)
+	}
+	if b.T1 != nil || b.T70 != nil {
+		t.Errorf("the dependencies are injected: %+v", b)
+	}
+	if _, ok := brickManager.getBrickFromExist("TestBrick70"); ok {
+		t.Error("the uninjected instance is cached")
+	}
+	if GetUninjected[*TestBrick70]() == b {
+		t.Error("GetUninjected returns the same instance twice")
+	}
+}
+
+type TestBrick683 struct {
+	Loggers    map[string]*TestBrick68 `brick:"all"`
+	Interfaces map[string]TestLogger68 `brick:"all"`
+}
+
+func (t *TestBrick683) BrickTypeID() string {
+	return "TestBrick683"
+}
+
+func Test_AllMapBrick(t *testing.T) {
+	if err := addTestBrick68Configs(); err != nil {
+		t.Fatal(err)
+	}
+	Register[*TestBrick683]()
+	b := Get[*TestBrick683]()
+	want := map[string]string{"TestBrick68": "default", "TestBrick68 a": "a", "TestBrick68 b": "b"}
+	if len(b.Loggers) != len(want) || len(b.Interfaces) != len(want) {
+		t.Fatalf("Loggers = %v, Interfaces = %v, want the lives %v", b.Loggers, b.Interfaces, want)
+	}
+	for liveID, name := range want {
+		if b.Loggers[liveID] != Get[*TestBrick68](liveID) || b.Loggers[liveID].Name != name {
+			t.Errorf("Loggers[%s] = %v, want the instance named %s", liveID, b.Loggers[liveID], name)
+		}
+		if b.Interfaces[liveID] == nil || b.Interfaces[liveID].LoggerName() != name {
+			t.Errorf("Interfaces[%s] = %v, want the instance named %s", liveID, b.Interfaces[liveID], name)
+		}
+	}
+}
+
+type TestBrick107 struct {
+	Name string `json:"name"`
+}
+
+func (t *TestBrick107) BrickTypeID() string {
+	return "TestBrick107"
+}
+
+func (t *TestBrick107) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick107{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+type TestBrick1071 struct {
+	All     []*TestBrick107          `brick:"all"`
+	AllMap  map[string]*TestBrick107 `brick:"all"`
+	Configs []struct {
+		Name string `json:"name"`
+	} `brick:"TestBrick107,configs"`
+}
+
+func (t *TestBrick1071) BrickTypeID() string {
+	return "TestBrick1071"
+}
+
+func Test_AllBrickSkipsClones(t *testing.T) {
+	RegisterNewer[*TestBrick107]()
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick107"},
+		"lives": [{"liveID": "TestBrick107", "config": {"name": "only"}}]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		CloneConfig[*TestBrick107]()
+	}
+	Register[*TestBrick1071]()
+	b := Get[*TestBrick1071]()
+	if len(b.All) != 1 || len(b.AllMap) != 1 || len(b.Configs) != 1 {
+		t.Errorf("all = %d, all map = %d, configs = %d bricks after 3 clones, want the single live", len(b.All), len(b.AllMap), len(b.Configs))
+	}
+	if lives := ConfigsForType("TestBrick107").Lives; len(lives) != 1 {
+		t.Errorf("ConfigsForType() = %d lives after 3 clones, want 1", len(lives))
+	}
+}
+
+type TestCache72 interface {
+	Cache72()
+}
+
+type TestBrick72 struct {
+	Cache   TestCache72   `brick:"TestBrick72 cache,optional"`
+	Logger  *TestBrick721 `brick:"TestBrick72 logger,optional"`
+	Present *TestBrick721 `brick:"TestBrick72 present,,optional"`
+}
+
+func (t *TestBrick72) BrickTypeID() string {
+	return "TestBrick72"
+}
+
+type TestBrick721 struct {
+	Level string `json:"level"`
+}
+
+func (t *TestBrick721) BrickTypeID() string {
+	return "TestBrick721"
+}
+
+func (t *TestBrick721) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick721{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func Test_OptionalBrick(t *testing.T) {
+	Register[*TestBrick72]()
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick721"},
+		"lives": [{"liveID": "TestBrick721", "config": {}}, {"liveID": "TestBrick72 present", "config": {"level": "debug"}}]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := Get[*TestBrick72]()
+	if b.Cache != nil {
+		t.Errorf("Cache = %v, want the optional interface field to stay nil", b.Cache)
+	}
+	if b.Logger != nil {
+		t.Errorf("Logger = %v, want nil for a dependency that is not configured", b.Logger)
+	}
+	if b.Present == nil || b.Present.Level != "debug" {
+		t.Errorf("Present = %v, want the configured dependency", b.Present)
+	}
+}
+
+type TestBrick74 struct {
+	Name string `json:"name"`
+}
+
+func (t *TestBrick74) BrickTypeID() string {
+	return "TestBrick74"
+}
+
+func (t *TestBrick74) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick74{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func TestSetRandSource(t *testing.T) {
+	RegisterNewer[*TestBrick74]()
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick74"},
+		"lives": [{"liveID": "TestBrick74", "config": {"name": "origin"}}]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer SetRandSource(nil)
+	run := func() []string {
+		SetRandSource(rand.NewSource(7))
+		return []string{CloneConfig[*TestBrick74](), CloneConfig[*TestBrick74]()}
+	}
+	first := run()
+	second := []string{RandomLiveID()}
+	if first[0] == first[1] || first[0] == second[0] {
+		t.Errorf("the liveIDs of one run = %v, %v, want them to differ", first, second)
+	}
+	if again := run(); !slices.Equal(first, again) {
+		t.Errorf("the clone liveIDs of two runs = %v, %v, want them identical", first, again)
+	}
+	if len(first[0]) != 25 {
+		t.Errorf("liveID %q, want 25 characters", first[0])
+	}
+}
+
+type TestBrick78 struct {
+	T1 *TestBrick1 `brick:""`
+}
+
+func (t *TestBrick78) BrickTypeID() string {
+	return "TestBrick78"
+}
+
+func Test_GetAll(t *testing.T) {
+	Register[*TestBrick78]()
+	if got := GetAll[*TestBrick78](); len(got) != 0 {
+		t.Errorf("GetAll() before any build = %v, want empty", got)
+	}
+	a := GetOrCreate[*TestBrick78]("TestBrick78 a")
+	def := Get[*TestBrick78]()
+
+	got := GetAll[*TestBrick78]()
+	want := map[string]*TestBrick78{"TestBrick78 a": a, "TestBrick78": def}
+	if !reflect.DeepEqual(got, want) || got["TestBrick78 a"] != a {
+		t.Errorf("GetAll() = %v, want %v", got, want)
+	}
+	if _, ok := GetAll[*TestBrick1]()["TestBrick78"]; ok {
+		t.Error("GetAll() returns the instances of another type")
+	}
+}
+
+type TestBrick82 struct {
+	T1 *TestBrick1 `brick:""`
+}
+
+func (t *TestBrick82) BrickTypeID() string {
+	return "TestBrick82"
+}
+
+func (t *TestBrick82) NewBrick(_ []byte) Brick {
+	return &TestBrick82{}
+}
+
+func Test_RegisterWithLives(t *testing.T) {
+	RegisterWithLives[*TestBrick82]([]Live{
+		{LiveID: "TestBrick82 custom", RelyLives: map[string]string{"T1": "TestBrick1 custom"}},
+	})
+
+	def := Get[*TestBrick82]()
+	custom := Get[*TestBrick82]("TestBrick82 custom")
+	if def.T1 != Get[*TestBrick1]() {
+		t.Error("the default live does not depend on the default TestBrick1")
+	}
+	if custom.T1 != Get[*TestBrick1]("TestBrick1 custom") || custom.T1 == def.T1 {
+		t.Error("the dependency override of the registered live is not applied")
+	}
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "field Missing in brick.TestBrick82 is not a brick component") {
+			t.Errorf("RegisterWithLives() with an unknown field panic = %v", r)
+		}
+	}()
+	RegisterWithLives[*TestBrick82]([]Live{{LiveID: "TestBrick82 bad", RelyLives: map[string]string{"Missing": "x"}}})
+}
+
+type TestLogger85 interface {
+	Log85() string
+}
+
+type TestBrick85 struct {
+	T1     *TestBrick1  `brick:""`
+	Logger TestLogger85 `brick:"TestBrick85 runtime logger,optional"`
+}
+
+func (t *TestBrick85) BrickTypeID() string {
+	return "TestBrick85"
+}
+
+type TestBrick851 struct{}
+
+func (t *TestBrick851) BrickTypeID() string {
+	return "TestBrick851"
+}
+
+func (t *TestBrick851) Log85() string {
+	return "manual"
+}
+
+func Test_GetPartial(t *testing.T) {
+	Register[*TestBrick85]()
+
+	b, set := GetPartial[*TestBrick85]([]string{"Logger"})
+	if b.T1 != Get[*TestBrick1]() {
+		t.Error("the field not supplied by the caller is not injected")
+	}
+	if b.Logger != nil {
+		t.Errorf("Logger = %v before it is set, want nil", b.Logger)
+	}
+	logger := &TestBrick851{}
+	if err := set("Logger", logger); err != nil {
+		t.Fatal(err)
+	}
+	if b.Logger != logger {
+		t.Errorf("Logger = %v, want the manual dependency", b.Logger)
+	}
+	if b == Get[*TestBrick85]() {
+		t.Error("GetPartial() returns the cached instance")
+	}
+
+	if b, _ := GetPartial[*TestBrick85]([]string{"T1"}); b.T1 != nil {
+		t.Errorf("T1 = %v, want the manual field not injected", b.T1)
+	}
+
+	if err := set("Missing", logger); err == nil {
+		t.Error("set() of a missing field succeeded")
+	}
+	if err := set("T1", Get[*TestBrick1]()); err == nil {
+		t.Error("set() of a field that is not manual succeeded")
+	}
+	if err := set("Logger", Get[*TestBrick1]()); err == nil {
+		t.Error("set() of a mismatched dependency type succeeded")
+	}
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "field Missing does not exist") {
+			t.Errorf("GetPartial() with an unknown manual field panic = %v", r)
+		}
+	}()
+	GetPartial[*TestBrick85]([]string{"Missing"})
+}
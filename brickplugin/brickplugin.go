@@ -0,0 +1,67 @@
+// Package brickplugin registers the bricks of Go plugins into brick.
+//
+// It is a module of its own, so that the users of brick do not link the dynamic loader of the std plugin package.
+//
+// Its go.mod builds it against brick of the parent directory, it can't be used outside this repository
+// until the root module is tagged and the go.mod requires that tag.
+package brickplugin
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// ErrPluginUnsupported is the error of LoadPlugin on a platform without Go plugin support.
+var ErrPluginUnsupported = errors.New("go plugins are not supported on this platform")
+
+// PluginSymbol is the name of the symbol that LoadPlugin looks up in a plugin.
+const PluginSymbol = "BrickPlugin"
+
+// BrickPlugin is implemented by the exported `BrickPlugin` variable of a Go plugin loaded by LoadPlugin, e.g.
+//
+//	var BrickPlugin plugin
+//
+//	type plugin struct{}
+//
+//	func (plugin) RegisterBricks() {
+//		brick.Register[*MyBrick]()
+//	}
+type BrickPlugin interface {
+	// RegisterBricks registers the bricks of the plugin with the package functions of brick, like Register.
+	RegisterBricks()
+}
+
+// LoadPlugin opens the Go plugin at path, and registers its bricks by calling RegisterBricks
+// of its exported `BrickPlugin` symbol, so that third-party bricks join the container without recompiling the host.
+// The panics of the registration are returned as errors, e.g. a typeID already used by another brick type.
+//
+// Go plugins are only supported on Linux, FreeBSD and macOS with cgo, LoadPlugin returns ErrPluginUnsupported elsewhere.
+// A plugin must be built with `go build -buildmode=plugin` by the same Go toolchain, with the same build flags
+// and the same versions of the packages shared with the host, including brick. A plugin can't be unloaded,
+// loading the same path again doesn't open it twice, but calls RegisterBricks again.
+func LoadPlugin(path string) (err error) {
+	symbol, err := lookupPluginSymbol(path)
+	if err != nil {
+		return err
+	}
+	p, ok := symbol.(BrickPlugin)
+	if !ok {
+		return fmt.Errorf("plugin(%s): the symbol %s of type %T doesn't implement BrickPlugin", path, PluginSymbol, symbol)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			var runtimeErr runtime.Error
+			if e, ok := r.(error); ok && errors.As(e, &runtimeErr) {
+				// A bug of the plugin, not a registration error.
+				panic(r)
+			} else if ok {
+				err = fmt.Errorf("plugin(%s): %w", path, e)
+			} else {
+				err = fmt.Errorf("plugin(%s): %v", path, r)
+			}
+		}
+	}()
+	p.RegisterBricks()
+	return nil
+}
@@ -0,0 +1,49 @@
+package brickplugin
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/doraemonkeys/brick"
+)
+
+// The test builds the plugin of testdata/plugin, which shares package brick with the test binary.
+// It can't be a test of package brick, whose test binary compiles brick with its tests,
+// which would not match the brick package of the plugin.
+
+type Host struct {
+	Greeter fmt.Stringer `brick:",PluginGreeter"`
+}
+
+func (h *Host) BrickTypeID() string {
+	return "Host"
+}
+
+func TestLoadPlugin(t *testing.T) {
+	if err := LoadPlugin(filepath.Join(t.TempDir(), "missing.so")); errors.Is(err, ErrPluginUnsupported) {
+		t.Skip(err)
+	}
+	path := filepath.Join(t.TempDir(), "plugin.so")
+	cmd := exec.Command("go", "build", "-buildmode=plugin", "-o", path, "./testdata/plugin")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("build the plugin: %v\n%s", err, out)
+	}
+
+	brick.Register[*Host]()
+	err := LoadPlugin(path)
+	if err == nil || !strings.Contains(err.Error(), "brick typeID(Host) is used by both") {
+		t.Fatalf("LoadPlugin() error = %v, want the typeID collision", err)
+	}
+	// The bricks registered before the collision are kept.
+	if got := brick.Get[*Host]().Greeter.String(); got != "hello from plugin" {
+		t.Errorf("Greeter.String() = %q, want the brick of the plugin", got)
+	}
+
+	if err := LoadPlugin(filepath.Join(t.TempDir(), "missing.so")); err == nil {
+		t.Error("LoadPlugin() of a missing file should fail")
+	}
+}
@@ -0,0 +1,17 @@
+module github.com/doraemonkeys/brick/brickplugin
+
+go 1.23.1
+
+require github.com/doraemonkeys/brick v0.0.0
+
+require (
+	github.com/BurntSushi/toml v1.4.0 // indirect
+	github.com/doraemonkeys/doraemon v0.6.1 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// brick v0.0.0 is the root module of this repository, built from the parent directory.
+// Tag the root module and require that tag, dropping the replace, before releasing this module:
+// outside this repository, v0.0.0 can't be resolved.
+replace github.com/doraemonkeys/brick => ../
@@ -0,0 +1,21 @@
+//go:build (linux || freebsd || darwin) && cgo
+
+package brickplugin
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// lookupPluginSymbol opens the plugin at path and looks up its PluginSymbol.
+func lookupPluginSymbol(path string) (any, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("plugin(%s): %w", path, err)
+	}
+	symbol, err := p.Lookup(PluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("plugin(%s): %w", path, err)
+	}
+	return symbol, nil
+}
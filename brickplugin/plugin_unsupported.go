@@ -0,0 +1,10 @@
+//go:build !((linux || freebsd || darwin) && cgo)
+
+package brickplugin
+
+import "fmt"
+
+// lookupPluginSymbol returns ErrPluginUnsupported.
+func lookupPluginSymbol(path string) (any, error) {
+	return nil, fmt.Errorf("plugin(%s): %w", path, ErrPluginUnsupported)
+}
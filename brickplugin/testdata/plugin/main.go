@@ -0,0 +1,31 @@
+// Command plugin is the plugin loaded by the tests of LoadPlugin, built with `go build -buildmode=plugin`.
+package main
+
+import "github.com/doraemonkeys/brick"
+
+type PluginGreeter struct{}
+
+func (g *PluginGreeter) BrickTypeID() string {
+	return "PluginGreeter"
+}
+
+func (g *PluginGreeter) String() string {
+	return "hello from plugin"
+}
+
+// PluginConflict has the typeID of a brick type of the host.
+type PluginConflict struct{}
+
+func (c *PluginConflict) BrickTypeID() string {
+	return "Host"
+}
+
+type brickPlugin struct{}
+
+func (brickPlugin) RegisterBricks() {
+	brick.Register[*PluginGreeter]()
+	brick.Register[*PluginConflict]()
+}
+
+// BrickPlugin is looked up by brickplugin.LoadPlugin.
+var BrickPlugin brickPlugin
@@ -0,0 +1,58 @@
+// Package brickprom exports the construction statistics of brick in the Prometheus format.
+//
+// It is a module of its own, so that the users of brick do not depend on the Prometheus client.
+//
+// Its go.mod builds it against brick of the parent directory, it can't be used outside this repository
+// until the root module is tagged and the go.mod requires that tag.
+package brickprom
+
+import (
+	"github.com/doraemonkeys/brick"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	buildsDesc = prometheus.NewDesc(
+		"brick_builds_total",
+		"Number of successful brick constructions.",
+		[]string{"type_id"}, nil,
+	)
+	failuresDesc = prometheus.NewDesc(
+		"brick_build_failures_total",
+		"Number of brick constructions that panicked.",
+		[]string{"type_id"}, nil,
+	)
+	durationDesc = prometheus.NewDesc(
+		"brick_build_duration_seconds_total",
+		"Total time spent in brick constructions, including the construction of dependencies.",
+		[]string{"type_id"}, nil,
+	)
+	instancesDesc = prometheus.NewDesc(
+		"brick_instances",
+		"Number of cached brick instances.",
+		[]string{"type_id"}, nil,
+	)
+)
+
+type collector struct{}
+
+// PrometheusCollector returns a collector exposing brick.BuildStats per typeID.
+func PrometheusCollector() prometheus.Collector {
+	return collector{}
+}
+
+func (collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- buildsDesc
+	ch <- failuresDesc
+	ch <- durationDesc
+	ch <- instancesDesc
+}
+
+func (collector) Collect(ch chan<- prometheus.Metric) {
+	for _, stat := range brick.BuildStats() {
+		ch <- prometheus.MustNewConstMetric(buildsDesc, prometheus.CounterValue, float64(stat.Builds), stat.TypeID)
+		ch <- prometheus.MustNewConstMetric(failuresDesc, prometheus.CounterValue, float64(stat.Failures), stat.TypeID)
+		ch <- prometheus.MustNewConstMetric(durationDesc, prometheus.CounterValue, stat.TotalDuration.Seconds(), stat.TypeID)
+		ch <- prometheus.MustNewConstMetric(instancesDesc, prometheus.GaugeValue, float64(stat.Instances), stat.TypeID)
+	}
+}
@@ -0,0 +1,61 @@
+package brickprom
+
+import (
+	"testing"
+
+	"github.com/doraemonkeys/brick"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type testDB struct{}
+
+func (t *testDB) BrickTypeID() string {
+	return "testDB"
+}
+
+type testService struct {
+	DB *testDB `brick:""`
+}
+
+func (t *testService) BrickTypeID() string {
+	return "testService"
+}
+
+func TestPrometheusCollector(t *testing.T) {
+	brick.Register[*testService]()
+	brick.Get[*testService]()
+	brick.GetOrCreate[*testService]("testService other")
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(PrometheusCollector())
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]map[string]float64)
+	for _, family := range families {
+		values[family.GetName()] = make(map[string]float64)
+		for _, metric := range family.GetMetric() {
+			typeID := metric.GetLabel()[0].GetValue()
+			if metric.GetCounter() != nil {
+				values[family.GetName()][typeID] = metric.GetCounter().GetValue()
+			} else {
+				values[family.GetName()][typeID] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+	for _, name := range []string{"brick_builds_total", "brick_build_failures_total", "brick_build_duration_seconds_total", "brick_instances"} {
+		if _, ok := values[name]; !ok {
+			t.Errorf("metric family %s is missing", name)
+		}
+	}
+	if got := values["brick_builds_total"]["testService"]; got != 2 {
+		t.Errorf("brick_builds_total{type_id=testService} = %v, want 2", got)
+	}
+	if got := values["brick_builds_total"]["testDB"]; got != 1 {
+		t.Errorf("brick_builds_total{type_id=testDB} = %v, want 1", got)
+	}
+	if got := values["brick_instances"]["testService"]; got != 2 {
+		t.Errorf("brick_instances{type_id=testService} = %v, want 2", got)
+	}
+}
@@ -0,0 +1,29 @@
+module github.com/doraemonkeys/brick/brickprom
+
+go 1.23.1
+
+require (
+	github.com/doraemonkeys/brick v0.0.0
+	github.com/prometheus/client_golang v1.20.5
+)
+
+require (
+	github.com/BurntSushi/toml v1.4.0 // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/doraemonkeys/doraemon v0.6.1 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_model v0.6.1 // indirect
+	github.com/prometheus/common v0.55.0 // indirect
+	github.com/prometheus/procfs v0.15.1 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	google.golang.org/protobuf v1.35.2 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// brick v0.0.0 is the root module of this repository, built from the parent directory.
+// Tag the root module and require that tag, dropping the replace, before releasing this module:
+// outside this repository, v0.0.0 can't be resolved.
+replace github.com/doraemonkeys/brick => ../
@@ -0,0 +1,139 @@
+// Package brickwatch reloads the config files of brick when they change on disk.
+//
+// It is a module of its own, so that the users of brick do not depend on fsnotify.
+//
+// Its go.mod builds it against brick of the parent directory, it can't be used outside this repository
+// until the root module is tagged and the go.mod requires that tag.
+package brickwatch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/doraemonkeys/brick"
+	"github.com/fsnotify/fsnotify"
+)
+
+// debounce is the quiet period after the last write of a watched config file before it is reloaded,
+// so that a file written in several chunks is reloaded once.
+const debounce = 100 * time.Millisecond
+
+// errorsBuffer is the capacity of the errors channel.
+const errorsBuffer = 16
+
+var (
+	// watchers stores the watchers started by WatchConfigFile, indexed by file path.
+	watchers     = make(map[string]*fsnotify.Watcher)
+	watchersLock sync.Mutex
+
+	errs = make(chan error, errorsBuffer)
+)
+
+// WatchConfigFile watches a config file previously added by brick.AddConfigFile, and reloads it when it changes
+// with brick.ReloadConfigFile, which gives the new config to the bricks of the changed lives implementing brick.BrickReloader.
+//
+// Successive writes are debounced. An empty file is considered partially written and is not reloaded,
+// a file that fails to parse or validate is rejected by the reload: the old configuration stays active until the next write.
+// Every reload sends a brick.ReloadEvent, see brick.ConfigReloadEvents.
+func WatchConfigFile(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// The directory is watched, since editors often replace the file by a rename.
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return err
+	}
+	watchersLock.Lock()
+	if _, ok := watchers[path]; ok {
+		watchersLock.Unlock()
+		watcher.Close()
+		return fmt.Errorf("config file(%s) is already watched", path)
+	}
+	watchers[path] = watcher
+	watchersLock.Unlock()
+	go watch(path, watcher)
+	return nil
+}
+
+// UnwatchConfigFile stops watching a config file watched by WatchConfigFile.
+func UnwatchConfigFile(path string) error {
+	watchersLock.Lock()
+	watcher, ok := watchers[path]
+	delete(watchers, path)
+	watchersLock.Unlock()
+	if !ok {
+		return fmt.Errorf("config file(%s) is not watched", path)
+	}
+	return watcher.Close()
+}
+
+// Errors returns the channel receiving the errors of the watchers, e.g. when the watched directory is removed.
+// The errors of the reloads are sent as brick.ReloadEvent. The channel is buffered, errors are dropped if it is full.
+func Errors() <-chan error {
+	return errs
+}
+
+func watch(path string, watcher *fsnotify.Watcher) {
+	target, err := filepath.Abs(path)
+	if err != nil {
+		target = filepath.Clean(path)
+	}
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			name, err := filepath.Abs(event.Name)
+			if err != nil {
+				name = filepath.Clean(event.Name)
+			}
+			if name != target || !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			if timer == nil {
+				timer = time.AfterFunc(debounce, func() { reload(path, watcher) })
+			} else {
+				timer.Reset(debounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			select {
+			case errs <- fmt.Errorf("watch config file(%s): %w", path, err):
+			default:
+			}
+		}
+	}
+}
+
+// reload reloads the watched config file, unless it is no longer watched or is being written.
+func reload(path string, watcher *fsnotify.Watcher) {
+	watchersLock.Lock()
+	watching := watchers[path] == watcher
+	watchersLock.Unlock()
+	if !watching {
+		return
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		// The file is being replaced or truncated, the next write reloads it.
+		return
+	}
+	// The error is sent as a ReloadEvent.
+	_ = brick.ReloadConfigFile(path)
+}
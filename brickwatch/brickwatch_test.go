@@ -0,0 +1,104 @@
+package brickwatch
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/doraemonkeys/brick"
+)
+
+type testServer struct {
+	mu   sync.Mutex
+	name string
+}
+
+func (t *testServer) BrickTypeID() string {
+	return "testServer"
+}
+
+func (t *testServer) NewBrick(config []byte) brick.Brick {
+	newBrick := &testServer{}
+	if err := newBrick.BrickReload(config); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func (t *testServer) BrickReload(config []byte) error {
+	var c struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(config, &c); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.name = c.Name
+	return nil
+}
+
+func (t *testServer) Name() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.name
+}
+
+func waitReloadEvent(t *testing.T, path string) brick.ReloadEvent {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-brick.ConfigReloadEvents():
+			if event.FilePath == path {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("no reload event for %s", path)
+		}
+	}
+}
+
+func TestWatchConfigFile(t *testing.T) {
+	brick.RegisterNewer[*testServer]()
+	path := filepath.Join(t.TempDir(), "bricks.json")
+	writeConfig := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig(`[{"metaData": {"typeID": "testServer"}, "lives": [{"liveID": "testServer", "config": {"name": "old"}}]}]`)
+	if err := brick.AddConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	instance := brick.Get[*testServer]()
+	if err := WatchConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	defer UnwatchConfigFile(path)
+	if err := WatchConfigFile(path); err == nil {
+		t.Error("WatchConfigFile() of a watched file succeeded")
+	}
+
+	writeConfig(`[{"metaData": {"typeID": "testServer"}, "lives": [{"liveID": "testServer", "config": {"name": "new"}}]}]`)
+	event := waitReloadEvent(t, path)
+	if event.Err != nil || !slices.Equal(event.ChangedLiveIDs, []string{"testServer"}) {
+		t.Errorf("event = %+v, want testServer changed", event)
+	}
+	if got := brick.Get[*testServer](); got != instance || got.Name() != "new" {
+		t.Errorf("Get() = %p with name %s, want %p reloaded with name new", got, got.Name(), instance)
+	}
+
+	// A partially written file is rejected, and the old configuration stays active.
+	writeConfig(`[{"metaData": {"typeID": "testServer"}, "lives": [{"liveID": "testServer", "con`)
+	if event := waitReloadEvent(t, path); event.Err == nil {
+		t.Errorf("event = %+v, want the parse error", event)
+	}
+	if got := instance.Name(); got != "new" {
+		t.Errorf("Name() = %s after a partial write, want new", got)
+	}
+}
@@ -1,59 +1,353 @@
 package brick
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 
+	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v3"
 )
 
-// AddConfigFile adds brick configurations from a file, supporting JSON and YAML formats.
+// ErrConfigFileAlreadyAdded is returned when a config file is added twice.
+var ErrConfigFileAlreadyAdded = errors.New("config file already added")
+
+// AddConfigFile adds brick configurations from a file, supporting JSON, YAML and TOML formats.
+// It returns an error wrapping ErrConfigFileAlreadyAdded if the file has already been added.
 func AddConfigFile(path string) error {
 	return brickManager.AddConfigFile(path)
 }
 
-// AddConfigFile adds brick configurations from a file, supporting JSON and YAML formats.
+// AddConfigFile adds brick configurations from a file, supporting JSON, YAML and TOML formats.
 func (b *BrickManager) AddConfigFile(path string) error {
+	return b.AddConfigFileWithOptions(path, ConfigOptions{})
+}
+
+// ConfigOptions are the options applied only to the configurations of one file,
+// including when the file is reloaded.
+type ConfigOptions struct {
+	// DisableLiveIDConstraint disables the liveID constraint (see SetLiveIDConstraint) for the file,
+	// e.g. to migrate a legacy file while the constraint stays on for the others.
+	DisableLiveIDConstraint bool
+}
+
+// AddConfigFileWithOptions like AddConfigFile, but it applies the options to the configurations of the file.
+func AddConfigFileWithOptions(path string, opts ConfigOptions) error {
+	return brickManager.AddConfigFileWithOptions(path, opts)
+}
+
+// AddConfigFileWithOptions like AddConfigFile, but it applies the options to the configurations of the file.
+func (b *BrickManager) AddConfigFileWithOptions(path string, opts ConfigOptions) error {
+	if b.hasConfigFile(path) {
+		return fmt.Errorf("%w: %s", ErrConfigFileAlreadyAdded, path)
+	}
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		b.configsLock.Lock()
-		for i := 0; i < len(b.configs); i++ {
-			if b.configs[i].filePath == path {
-				panic(fmt.Errorf("config file(%s) already exists", path))
-			}
+	configs, err := parseConfigFile(path, content)
+	if err != nil {
+		return fmt.Errorf("config file(%s): %w", path, err)
+	}
+	liveIDTypes, err := parseLiveIDTypes(path, content)
+	if err != nil {
+		return fmt.Errorf("config file(%s): %w", path, err)
+	}
+	if err := b.checkLiveIDTypes(liveIDTypes); err != nil {
+		return fmt.Errorf("config file(%s): %w", path, err)
+	}
+	if err := b.addConfigManager(path, opts); err != nil {
+		return err
+	}
+	if err := b.addConfigFrom(configs, path, opts); err != nil {
+		b.removeConfigManager(path)
+		return fmt.Errorf("config file(%s): %w", path, err)
+	}
+	b.setLiveIDTypes(liveIDTypes)
+	return nil
+}
+
+// addConfigManager adds the ConfigManager of the config file, it fails if the file has already been added.
+func (b *BrickManager) addConfigManager(path string, opts ConfigOptions) error {
+	b.configsLock.Lock()
+	defer b.configsLock.Unlock()
+	for i := 0; i < len(b.configs); i++ {
+		if b.configs[i].filePath == path {
+			return fmt.Errorf("%w: %s", ErrConfigFileAlreadyAdded, path)
+		}
+	}
+	configManager := NewConfigManager(path)
+	configManager.options = opts
+	b.configs = append(b.configs, configManager)
+	return nil
+}
+
+// removeConfigManager removes the ConfigManager of the config file.
+func (b *BrickManager) removeConfigManager(path string) {
+	b.configsLock.Lock()
+	defer b.configsLock.Unlock()
+	b.configs = slices.DeleteFunc(b.configs, func(c *ConfigManager) bool { return c.filePath == path })
+}
+
+// liveIDTypesKey is the top-level key of a config file declaring the types of liveIDs like RegisterLiveIDType,
+// e.g. `"liveIDTypes": {"cache": "RedisCache"}` maps the liveID cache to the brick typeID RedisCache.
+// It only exists in the `bricks` map form of a file, and is applied when the file is added.
+const liveIDTypesKey = "liveIDTypes"
+
+// parseLiveIDTypes parses the liveIDTypes section of a config file, indexed by liveID.
+func parseLiveIDTypes(path string, content []byte) (map[string]string, error) {
+	var file any
+	var err error
+	switch filepath.Ext(path) {
+	case ".json":
+		err = json.Unmarshal(content, &file)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &file)
+	case ".toml":
+		var tomlFile map[string]any
+		_, err = toml.Decode(string(content), &tomlFile)
+		file = tomlFile
+	}
+	if err != nil {
+		return nil, err
+	}
+	fileMap, ok := file.(map[string]any)
+	if !ok || fileMap[liveIDTypesKey] == nil {
+		return nil, nil
+	}
+	section, ok := fileMap[liveIDTypesKey].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must map liveIDs to typeIDs", liveIDTypesKey)
+	}
+	liveIDTypes := make(map[string]string, len(section))
+	for liveID, typeID := range section {
+		typeIDStr, ok := typeID.(string)
+		if !ok || typeIDStr == "" {
+			return nil, fmt.Errorf("the typeID of liveID(%s) in %s must be a string", liveID, liveIDTypesKey)
 		}
-		b.configs = append(b.configs, NewConfigManager(path))
-		b.configsLock.Unlock()
-	}()
+		liveIDTypes[liveID] = typeIDStr
+	}
+	return liveIDTypes, nil
+}
+
+// checkLiveIDTypes checks that the typeIDs declared by a liveIDTypes section are registered.
+func (b *BrickManager) checkLiveIDTypes(liveIDTypes map[string]string) error {
+	for liveID, typeID := range liveIDTypes {
+		if _, ok := b.getBrickType(typeID); !ok {
+			return fmt.Errorf("the typeID(%s) of liveID(%s) in %s is not registered", typeID, liveID, liveIDTypesKey)
+		}
+	}
+	return nil
+}
+
+// setLiveIDTypes registers the types of the liveIDs declared by a liveIDTypes section, and declares the liveIDs.
+func (b *BrickManager) setLiveIDTypes(liveIDTypes map[string]string) {
+	for liveID, typeID := range liveIDTypes {
+		typ, _ := b.getBrickType(typeID)
+		b.RegisterLiveIDType(liveID, typ)
+		b.setDeclaredLiveID(liveID)
+	}
+}
+
+// parseConfigFile parses the content of a config file according to its extension.
+func parseConfigFile(path string, content []byte) ([]BrickFileConfig, error) {
 	ext := filepath.Ext(path)
 	switch ext {
 	case ".json":
-		return b.addConfigFileJson(content)
+		return parseConfigJson(content)
 	case ".yaml", ".yml":
-		return b.addConfigFileYaml(content)
+		return parseConfigYaml(content)
+	case ".toml":
+		return parseConfigToml(content)
 	default:
-		return fmt.Errorf("unsupported file type: %s", ext)
+		return nil, fmt.Errorf("unsupported file type: %s", ext)
 	}
 }
 
+// AddConfig adds brick configurations built in code, e.g. from Consul or etcd, without a config file.
+// They are validated as the configurations of a file. SaveBrickConfig on their lives only updates the
+// configuration in memory, since no file backs it.
+func AddConfig(configs []BrickFileConfig) error {
+	return brickManager.AddConfig(configs)
+}
+
+// AddConfig adds brick configurations built in code, without a config file.
+func (b *BrickManager) AddConfig(configs []BrickFileConfig) error {
+	return b.addConfig(configs)
+}
+
+// AddLive adds the config of a single live of the brick type, as AddConfig.
+func AddLive(typeID, liveID string, config any) error {
+	return brickManager.AddLive(typeID, liveID, config)
+}
+
+// AddLive adds the config of a single live of the brick type, as AddConfig.
+func (b *BrickManager) AddLive(typeID, liveID string, config any) error {
+	var fileConfig BrickFileConfig
+	fileConfig.MetaData.TypeID = typeID
+	fileConfig.Lives = slices.Grow(fileConfig.Lives, 1)[:1]
+	fileConfig.Lives[0].LiveID = liveID
+	fileConfig.Lives[0].Config = config
+	return b.addConfig([]BrickFileConfig{fileConfig})
+}
+
 // addConfig adds brick configurations from a slice of BrickFileConfig.
 func (b *BrickManager) addConfig(configs []BrickFileConfig) error {
+	return b.addConfigFrom(configs, "", ConfigOptions{})
+}
+
+// addConfigFrom adds brick configurations loaded from the file path, path is empty if no file backs them.
+func (b *BrickManager) addConfigFrom(configs []BrickFileConfig, path string, opts ConfigOptions) error {
+	b.prepareFileConfigs(configs)
+	if err := b.checkFileConfigs(configs, nil, opts); err != nil {
+		return err
+	}
+	b.applyFileConfigs(configs, path)
+	return nil
+}
 
+// ConfigTransform transforms the config of a live, and returns the new config.
+type ConfigTransform func(typeID, liveID string, config any) any
+
+// AddConfigTransform adds a transform run on the config of every live when the config is added or reloaded.
+// Transforms run in the order they are added, the output of one feeds the next.
+// They run before environment variables are expanded, so they see `${VAR}` placeholders as written,
+// and placeholders they produce are expanded when the brick is built.
+// Configs added before the transform are not transformed.
+func AddConfigTransform(transform ConfigTransform) {
+	brickManager.AddConfigTransform(transform)
+}
+
+// AddConfigTransform adds a transform run on the config of every live when the config is added or reloaded.
+func (b *BrickManager) AddConfigTransform(transform ConfigTransform) {
+	b.configTransformsLock.Lock()
+	defer b.configTransformsLock.Unlock()
+	b.configTransforms = append(b.configTransforms, transform)
+}
+
+// prepareFileConfigs drops the lives of the inactive profiles, merges the shared config of each brick under its lives,
+// then runs the config transforms.
+func (b *BrickManager) prepareFileConfigs(configs []BrickFileConfig) {
+	b.dropInactiveLives(configs)
+	for i := range configs {
+		if configs[i].SharedConfig == nil {
+			continue
+		}
+		mergeKeys := b.getConfigMergeKeys(configs[i].MetaData.TypeID)
+		for j := range configs[i].Lives {
+			live := &configs[i].Lives[j]
+			live.Config = mergeConfig(copyConfig(configs[i].SharedConfig), live.Config, mergeKeys)
+		}
+	}
+	b.transformFileConfigs(configs)
+}
+
+// dropInactiveLives removes the lives whose profiles are all inactive from the configs.
+func (b *BrickManager) dropInactiveLives(configs []BrickFileConfig) {
+	for i := range configs {
+		// The active lives are copied to a new slice, the lives may be owned by the caller of AddConfig.
+		lives := configs[i].Lives[:0:0]
+		for _, live := range configs[i].Lives {
+			if b.isProfileActive(live.Profiles) {
+				lives = append(lives, live)
+			}
+		}
+		if len(lives) < len(configs[i].Lives) {
+			configs[i].Lives = lives
+		}
+	}
+}
+
+// isProfileActive reports whether a live of the profiles is active, a live without profiles is always active.
+func (b *BrickManager) isProfileActive(profiles []string) bool {
+	if len(profiles) == 0 {
+		return true
+	}
+	for _, profile := range profiles {
+		if slices.Contains(b.activeProfiles, profile) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeConfig merges override into base, maps are merged recursively and other values of override replace those of base.
+// The arrays at the paths of mergeKeys are merged element by element, see SetConfigMergeKey.
+func mergeConfig(base any, override any, mergeKeys map[string]string) any {
+	return mergeConfigAt(base, override, "", mergeKeys)
+}
+
+// mergeConfigAt is mergeConfig for the values at the dot-separated path of the config.
+func mergeConfigAt(base any, override any, path string, mergeKeys map[string]string) any {
+	if override == nil {
+		return base
+	}
+	if key, ok := mergeKeys[path]; ok {
+		baseList, ok1 := base.([]any)
+		overrideList, ok2 := override.([]any)
+		if ok1 && ok2 {
+			return mergeKeyedList(baseList, overrideList, key, path, mergeKeys)
+		}
+	}
+	baseMap, ok1 := base.(map[string]any)
+	overrideMap, ok2 := override.(map[string]any)
+	if !ok1 || !ok2 {
+		return override
+	}
+	for k, v := range overrideMap {
+		childPath := k
+		if path != "" {
+			childPath = path + "." + k
+		}
+		baseMap[k] = mergeConfigAt(baseMap[k], v, childPath, mergeKeys)
+	}
+	return baseMap
+}
+
+// transformFileConfigs runs the config transforms on every live config in place.
+func (b *BrickManager) transformFileConfigs(configs []BrickFileConfig) {
+	b.configTransformsLock.RLock()
+	defer b.configTransformsLock.RUnlock()
+	if len(b.configTransforms) == 0 {
+		return
+	}
+	for i := range configs {
+		for j := range configs[i].Lives {
+			live := &configs[i].Lives[j]
+			for _, transform := range b.configTransforms {
+				live.Config = transform(configs[i].MetaData.TypeID, live.LiveID, live.Config)
+			}
+		}
+	}
+}
+
+// checkFileConfigs validates brick configurations before they are added.
+// The liveIDs in replacing are allowed to already exist, since their configurations will be replaced.
+func (b *BrickManager) checkFileConfigs(configs []BrickFileConfig, replacing map[string]bool, opts ConfigOptions) error {
 	liveIDMap := make(map[string]bool)
+	// The enabled liveIDs of each typeID, the lives of a typeID may be split across entries and files.
+	typeLiveIDs := make(map[string]map[string]bool)
+	var typeIDs []string
 	for _, config := range configs {
 		if config.MetaData.TypeID == "" {
 			return fmt.Errorf("typeID is required")
 		}
-		singleTypeliveIDs := make(map[string]bool, len(config.Lives))
+		singleTypeliveIDs, ok := typeLiveIDs[config.MetaData.TypeID]
+		if !ok {
+			singleTypeliveIDs = make(map[string]bool, len(config.Lives))
+			typeLiveIDs[config.MetaData.TypeID] = singleTypeliveIDs
+			typeIDs = append(typeIDs, config.MetaData.TypeID)
+		}
 		for _, live := range config.Lives {
 			if live.LiveID == "" {
 				return fmt.Errorf("the liveID of brick(%s) is required", config.MetaData.TypeID)
@@ -61,52 +355,93 @@ func (b *BrickManager) addConfig(configs []BrickFileConfig) error {
 			if _, ok := liveIDMap[live.LiveID]; ok {
 				return fmt.Errorf("liveID duplicate: %s", live.LiveID)
 			}
+			if canonical, ok := b.ResolveAlias(live.LiveID); ok {
+				return fmt.Errorf("liveID(%s) is an alias of liveID(%s)", live.LiveID, canonical)
+			}
+			if live.Weight != nil && *live.Weight <= 0 {
+				return fmt.Errorf("the weight of brick(%s) must be positive, disable the live to take it out of rotation", live.LiveID)
+			}
 			liveIDMap[live.LiveID] = true
-			singleTypeliveIDs[live.LiveID] = true
+			if !live.Disabled {
+				singleTypeliveIDs[live.LiveID] = true
+			}
 		}
-		if b.liveIDConstraint && len(singleTypeliveIDs) > 0 && !singleTypeliveIDs[config.MetaData.TypeID] {
-			return fmt.Errorf("the liveID of all instances of the brick must have one set to the typeID(%s) of the brick", config.MetaData.TypeID)
+	}
+	for _, typeID := range typeIDs {
+		liveIDs := typeLiveIDs[typeID]
+		if !b.liveIDConstraint || opts.DisableLiveIDConstraint || len(liveIDs) == 0 || liveIDs[typeID] {
+			continue
 		}
+		// The default live may come from a config added before.
+		if config, ok := b.getBrickConfig(typeID); ok && config.TypeID == typeID && !config.Disabled && !replacing[typeID] {
+			continue
+		}
+		return fmt.Errorf("the liveID of all instances of the brick must have one set to the typeID(%s) of the brick", typeID)
 	}
 
-	b.brickConfigLock.RLock()
 	for _, config := range configs {
 		for _, live := range config.Lives {
-			if _, ok := b.brickConfigs[live.LiveID]; ok {
+			if _, ok := b.getBrickConfig(live.LiveID); ok && !replacing[live.LiveID] {
 				return fmt.Errorf("liveID duplicate: %s", live.LiveID)
 			}
 		}
 	}
-	b.brickConfigLock.RUnlock()
 
 	for _, config := range configs {
-		checked := false
 		if config.MetaData.NoCheck {
-			checked = true
+			continue
 		}
 		for _, live := range config.Lives {
-			if !checked && live.Config != nil {
+			if live.Config != nil {
 				if _, ok := b.getBrickType(config.MetaData.TypeID); ok {
 					if _, ok = b.getBrickFactory(config.MetaData.TypeID); !ok {
 						return fmt.Errorf("the brick(%s) provides config, but no config parser, please use `brick.RegisterNewer` to register the brick", config.MetaData.TypeID)
 					}
+				} else if b.failFastOnConfig.Load() {
+					return fmt.Errorf("the brick(%s) provides config in live(%s), but it is not registered, please use `brick.RegisterNewer` to register the brick before adding the config", config.MetaData.TypeID, live.LiveID)
 				}
-				checked = true
+				break
 			}
-			if live.LiveID != config.MetaData.TypeID {
-				b.setDeclaredLiveID(live.LiveID)
+		}
+	}
+	return nil
+}
+
+// applyFileConfigs stores validated brick configurations.
+func (b *BrickManager) applyFileConfigs(configs []BrickFileConfig, path string) {
+	b.setBrickConfigs(fileBrickConfigs(configs, path))
+}
+
+// fileBrickConfigs returns the configurations of the lives of the file configs, indexed by LiveID.
+func fileBrickConfigs(configs []BrickFileConfig, path string) map[string]BrickConfig {
+	ret := make(map[string]BrickConfig)
+	for _, config := range configs {
+		for _, live := range config.Lives {
+			ret[live.LiveID] = BrickConfig{
+				TypeID:   config.MetaData.TypeID,
+				LiveID:   live.LiveID,
+				Config:   live.Config,
+				noCheck:  config.MetaData.NoCheck,
+				Weight:   fileLiveWeight(live.Weight),
+				Order:    live.Order,
+				Disabled: live.Disabled,
+				filePath: path,
 			}
-			b.setBrickConfig(live.LiveID, BrickConfig{
-				TypeID:  config.MetaData.TypeID,
-				LiveID:  live.LiveID,
-				Config:  live.Config,
-				noCheck: config.MetaData.NoCheck,
-			})
 		}
 	}
+	return ret
+}
+
+// setBrickConfigs stores the configurations, indexed by LiveID.
+func (b *BrickManager) setBrickConfigs(configs map[string]BrickConfig) {
+	for liveID, config := range configs {
+		if liveID != config.TypeID {
+			b.setDeclaredLiveID(liveID)
+		}
+		b.setBrickConfig(liveID, config)
+	}
 	// reset
 	b.brickConfigCheckOnce = sync.Once{}
-	return nil
 }
 
 func (b *BrickManager) checkConfig() {
@@ -128,54 +463,194 @@ func (b *BrickManager) checkConfig() {
 		}
 	}
 	b.brickConfigLock.RUnlock()
+	if err := b.validateStoredConfigKeys(); err != nil {
+		panic(err)
+	}
+	b.checkBindings()
+}
+
+// checkBindings panics on the errors of CheckInterfaceBindings if the config fails fast, or logs each of them once.
+func (b *BrickManager) checkBindings() {
+	errs := b.interfaceBindingErrors()
+	if len(errs) == 0 {
+		return
+	}
+	if b.failFastOnConfig.Load() {
+		panic(errors.Join(errs...))
+	}
+	b.bindingWarningsLock.Lock()
+	defer b.bindingWarningsLock.Unlock()
+	for _, err := range errs {
+		if !b.bindingWarnings[err.Error()] {
+			b.bindingWarnings[err.Error()] = true
+			log.Printf("brick: %v", err)
+		}
+	}
 }
 
 // addConfigFileYaml adds brick configurations from YAML content.
 func (b *BrickManager) addConfigFileYaml(yamlContent []byte) error {
+	configs, err := parseConfigYaml(yamlContent)
+	if err != nil {
+		return err
+	}
+	return b.addConfig(configs)
+}
+
+// parseConfigYaml parses brick configurations from YAML content.
+func parseConfigYaml(yamlContent []byte) ([]BrickFileConfig, error) {
 	var configs1 struct {
 		Bricks []BrickFileConfig `yaml:"bricks"`
 	}
 	err := yaml.Unmarshal(yamlContent, &configs1)
 	if err == nil {
-		return b.addConfig(configs1.Bricks)
+		return configs1.Bricks, nil
 	}
 	var configs2 []BrickFileConfig
 	err2 := yaml.Unmarshal(yamlContent, &configs2)
 	if err2 == nil {
-		return b.addConfig(configs2)
+		return configs2, nil
 	}
-	return errors.New("invalid config file format")
+	return nil, errors.New("invalid config file format")
 }
 
 // addConfigFileJson adds brick configurations from JSON content.
 func (b *BrickManager) addConfigFileJson(jsonContent []byte) error {
+	configs, err := parseConfigJson(jsonContent)
+	if err != nil {
+		return err
+	}
+	return b.addConfig(configs)
+}
+
+// parseConfigToml parses brick configurations from TOML content.
+// Since TOML has no top-level array, the bricks are either an array of tables `[[bricks]]`,
+// or a single brick at the top level.
+func parseConfigToml(tomlContent []byte) ([]BrickFileConfig, error) {
+	configs, _, err := decodeConfigToml(tomlContent)
+	return configs, err
+}
+
+// decodeConfigToml parses brick configurations from TOML content, single is true if the content is a single brick.
+func decodeConfigToml(tomlContent []byte) (configs []BrickFileConfig, single bool, err error) {
+	var configs1 struct {
+		Bricks []BrickFileConfig `toml:"bricks"`
+	}
+	_, err1 := toml.Decode(string(tomlContent), &configs1)
+	if err1 == nil && configs1.Bricks != nil {
+		configs = configs1.Bricks
+	} else {
+		var config2 BrickFileConfig
+		_, err2 := toml.Decode(string(tomlContent), &config2)
+		if err2 != nil || config2.MetaData.TypeID == "" {
+			return nil, false, errors.New("invalid config file format")
+		}
+		configs, single = []BrickFileConfig{config2}, true
+	}
+	for i := range configs {
+		configs[i].SharedConfig = normalizeTomlValue(configs[i].SharedConfig)
+		for j := range configs[i].Lives {
+			configs[i].Lives[j].Config = normalizeTomlValue(configs[i].Lives[j].Config)
+		}
+	}
+	return configs, single, nil
+}
+
+// normalizeTomlValue converts the arrays of tables decoded from TOML, []map[string]any, to []any like JSON and YAML,
+// so that the env placeholders they contain are expanded.
+func normalizeTomlValue(value any) any {
+	switch val := value.(type) {
+	case map[string]any:
+		for k, v := range val {
+			val[k] = normalizeTomlValue(v)
+		}
+	case []map[string]any:
+		ret := make([]any, len(val))
+		for i, v := range val {
+			ret[i] = normalizeTomlValue(v)
+		}
+		return ret
+	case []any:
+		for i, v := range val {
+			val[i] = normalizeTomlValue(v)
+		}
+	}
+	return value
+}
+
+// parseConfigJson parses brick configurations from JSON content.
+func parseConfigJson(jsonContent []byte) ([]BrickFileConfig, error) {
 	var configs1 struct {
 		Bricks []BrickFileConfig `json:"bricks"`
 	}
 	err1 := json.Unmarshal(jsonContent, &configs1)
 	if err1 == nil && configs1.Bricks != nil {
-		return b.addConfig(configs1.Bricks)
+		return configs1.Bricks, nil
 	}
 	var configs2 []BrickFileConfig
 	err2 := json.Unmarshal(jsonContent, &configs2)
 	if err2 == nil {
-		return b.addConfig(configs2)
+		return configs2, nil
+	}
+	return nil, errors.New("invalid config file format")
+}
+
+// marshalBrickConfig replaces environment variables in a configuration and marshals it to json.
+// A nil configuration is marshaled to nil.
+func marshalBrickConfig(config any) ([]byte, error) {
+	config = handleConfig(config)
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
 	}
-	return errors.New("invalid config file format")
+	if bytes.Equal(configBytes, []byte("null")) {
+		configBytes = nil
+	}
+	return configBytes, nil
 }
 
-// handleConfig replaces environment variables in a configuration.
+// handleConfig replaces environment variables in a copy of the configuration,
+// the stored configuration keeps its placeholders.
 func handleConfig(config any) any {
-	c, _ := handleConfigHelper(config)
+	c, _ := handleConfigHelper(copyConfig(config))
 	return c
 }
 
+// copyConfig deep copies the maps and slices of a configuration.
+func copyConfig(config any) any {
+	switch val := config.(type) {
+	case map[string]any:
+		ret := make(map[string]any, len(val))
+		for k, v := range val {
+			ret[k] = copyConfig(v)
+		}
+		return ret
+	case map[string]string:
+		ret := make(map[string]string, len(val))
+		for k, v := range val {
+			ret[k] = v
+		}
+		return ret
+	case []any:
+		ret := make([]any, len(val))
+		for i, v := range val {
+			ret[i] = copyConfig(v)
+		}
+		return ret
+	case []string:
+		ret := make([]string, len(val))
+		copy(ret, val)
+		return ret
+	}
+	return config
+}
+
 // handleConfigHelper is a recursive helper function for handleConfig.
 func handleConfigHelper(config any) (conf any, maybeReplaced bool) {
 	switch val := config.(type) {
 	case string:
 		if isEnvConfigItem(val) {
-			conf, _ = handleConfigHelper(os.ExpandEnv(val))
+			conf, _ = handleConfigHelper(expandEnvConfigItem(val))
 			return conf, true
 		}
 	case map[string]any:
@@ -216,8 +691,27 @@ func (b *BrickManager) saveBrickInstance(liveID string, brick reflect.Value) {
 		panic(fmt.Errorf("internal error: brick(%s) instance is not a pointer", liveID))
 	}
 	b.instancesLock.Lock()
-	defer b.instancesLock.Unlock()
+	old, ok := b.instances[liveID]
+	if !ok {
+		b.buildOrder = append(b.buildOrder, liveID)
+	}
+	if !ok || old.Pointer() != brick.Pointer() || old.Type() != brick.Type() {
+		b.generations[liveID]++
+	}
 	b.instances[liveID] = brick
+	b.instancesLock.Unlock()
+	b.recordInstanceHash(liveID, brick)
+}
+
+// deleteBrickInstance removes the instance of the liveID if it is still the given instance.
+func (b *BrickManager) deleteBrickInstance(liveID string, brick reflect.Value) {
+	b.instancesLock.Lock()
+	defer b.instancesLock.Unlock()
+	if stored, ok := b.instances[liveID]; !ok || stored.Pointer() != brick.Pointer() {
+		return
+	}
+	delete(b.instances, liveID)
+	b.buildOrder = slices.DeleteFunc(b.buildOrder, func(id string) bool { return id == liveID })
 }
 
 // getBrickFromExist retrieves an existing brick instance by LiveID.
@@ -228,6 +722,71 @@ func (b *BrickManager) getBrickFromExist(liveID string) (reflect.Value, bool) {
 	return brick, ok
 }
 
+// getEnabledBrickConfigsByTypeID like getBrickConfigsByTypeID, but the disabled lives are skipped.
+func (b *BrickManager) getEnabledBrickConfigsByTypeID(typeID string) []BrickConfig {
+	var configs []BrickConfig
+	for _, config := range b.getBrickConfigsByTypeID(typeID) {
+		if !config.Disabled {
+			configs = append(configs, config)
+		}
+	}
+	return configs
+}
+
+// getBrickConfigsByTypeID retrieves the configurations of all lives of a brick type, sorted by LiveID.
+// The configs copied for clones are not lives of their own, they are excluded.
+func (b *BrickManager) getBrickConfigsByTypeID(typeID string) []BrickConfig {
+	b.brickConfigLock.RLock()
+	defer b.brickConfigLock.RUnlock()
+	var configs []BrickConfig
+	for _, config := range b.brickConfigs {
+		if config.TypeID == typeID && !config.clone {
+			configs = append(configs, config)
+		}
+	}
+	sort.Slice(configs, func(i, j int) bool { return configs[i].LiveID < configs[j].LiveID })
+	return configs
+}
+
+// getBrickConfigsByFile retrieves the configurations loaded from the config file, indexed by LiveID.
+func (b *BrickManager) getBrickConfigsByFile(path string) map[string]BrickConfig {
+	b.brickConfigLock.RLock()
+	defer b.brickConfigLock.RUnlock()
+	configs := make(map[string]BrickConfig)
+	for liveID, config := range b.brickConfigs {
+		if config.filePath == path {
+			configs[liveID] = config
+		}
+	}
+	return configs
+}
+
+// deleteBrickConfig deletes a brick's configuration by LiveID.
+func (b *BrickManager) deleteBrickConfig(liveID string) {
+	b.brickConfigLock.Lock()
+	defer b.brickConfigLock.Unlock()
+	delete(b.brickConfigs, liveID)
+}
+
+func (b *BrickManager) setBuiltConfig(liveID string, config []byte) {
+	b.builtConfigsLock.Lock()
+	defer b.builtConfigsLock.Unlock()
+	b.builtConfigs[liveID] = config
+}
+
+func (b *BrickManager) deleteBuiltConfig(liveID string) {
+	b.builtConfigsLock.Lock()
+	defer b.builtConfigsLock.Unlock()
+	delete(b.builtConfigs, liveID)
+}
+
+func (b *BrickManager) getBuiltConfig(liveID string) ([]byte, bool) {
+	b.builtConfigsLock.RLock()
+	defer b.builtConfigsLock.RUnlock()
+	config, ok := b.builtConfigs[liveID]
+	return config, ok
+}
+
 // getBrickConfig retrieves a brick's configuration by LiveID.
 func (b *BrickManager) getBrickConfig(liveID string) (BrickConfig, bool) {
 	b.brickConfigLock.RLock()
@@ -244,12 +803,20 @@ func (b *BrickManager) setBrickConfig(liveID string, brickConfig BrickConfig) {
 	b.brickConfigs[liveID] = brickConfig
 }
 
+// setBrickTypeID binds the type to the typeID, it returns false if the type is already registered.
+// It panics if the typeID is empty or already used by another type.
 func (b *BrickManager) setBrickTypeID(typ reflect.Type, typeID string) (success bool) {
 	b.brickTypeIDMapLock.Lock()
 	defer b.brickTypeIDMapLock.Unlock()
 	if _, ok := b.brickTypeIDMap1[typ]; ok {
 		return false
 	}
+	if typeID == "" {
+		panic(fmt.Errorf("the BrickTypeID of brick type %s is empty", typ))
+	}
+	if typ2, ok := b.brickTypeIDMap2[typeID]; ok {
+		panic(fmt.Errorf("brick typeID(%s) is used by both %s and %s, the BrickTypeID of each brick type must be unique", typeID, typ2, typ))
+	}
 	// fmt.Println("setBrickTypeID", typ, typeID)
 	b.brickTypeIDMap1[typ] = typeID
 	b.brickTypeIDMap2[typeID] = typ
@@ -276,6 +843,20 @@ func (b *BrickManager) getBrickFactory(typeID string) (func(config any) Brick, b
 	return factory, ok
 }
 
+func (b *BrickManager) getLiveFactory(liveID string) (func(jsonConfig []byte) Brick, bool) {
+	b.liveFactoriesLock.RLock()
+	defer b.liveFactoriesLock.RUnlock()
+	factory, ok := b.liveFactories[liveID]
+	return factory, ok
+}
+
+func (b *BrickManager) getBrickCtxFactory(typeID string) (func(ctx context.Context, config any) (Brick, error), bool) {
+	b.brickCtxFactoriesLock.RLock()
+	defer b.brickCtxFactoriesLock.RUnlock()
+	factory, ok := b.brickCtxFactories[typeID]
+	return factory, ok
+}
+
 // func (b *BrickManager) setBrickFactory(typeID string, factory func(config any) Brick) {
 // 	b.brickFactoriesLock.Lock()
 // 	defer b.brickFactoriesLock.Unlock()
@@ -295,6 +876,9 @@ func (b *BrickManager) setDeclaredLiveID(liveID string) {
 }
 
 func (b *BrickManager) saveBrickConfig(typeID string, brickLiveID string, brickConfig []byte) error {
+	if config, ok := b.getBrickConfig(brickLiveID); ok && config.TypeID == typeID && config.filePath == "" {
+		return b.saveMemoryBrickConfig(config, brickConfig)
+	}
 	var err error
 	for i := 0; i < len(b.configs); i++ {
 		b.configsLock.RLock()
@@ -307,6 +891,21 @@ func (b *BrickManager) saveBrickConfig(typeID string, brickLiveID string, brickC
 	return err
 }
 
+// saveMemoryBrickConfig updates the config of a live added by AddConfig, the env config items are retained as in a file.
+func (b *BrickManager) saveMemoryBrickConfig(config BrickConfig, brickConfig []byte) error {
+	var brickConfigParsed any
+	if err := json.Unmarshal(brickConfig, &brickConfigParsed); err != nil {
+		return err
+	}
+	newEnvs := make(map[string]string)
+	config.Config, _ = retainEnvConfigItem(config.Config, brickConfigParsed, newEnvs)
+	for k, v := range newEnvs {
+		setEnvConfigItem(k, v)
+	}
+	b.setBrickConfig(config.LiveID, config)
+	return nil
+}
+
 type TypeLives struct {
 	TypeID string
 	Lives  []Live
@@ -363,7 +962,10 @@ type ConfigManager struct {
 	// used to determine if the config has changed
 	// lastLoadedConfig []byte
 	configIsArray bool
-	filePath      string
+	// configIsSingle is true if the TOML file holds a single brick at the top level, see parseConfigToml.
+	configIsSingle bool
+	filePath       string
+	options        ConfigOptions
 }
 
 func NewConfigManager(filePath string) *ConfigManager {
@@ -382,7 +984,10 @@ func (c *ConfigManager) Load() ([]BrickFileConfig, error) {
 	switch ext {
 	case ".json":
 		return c.loadJson(content)
-	// case ".yaml", ".yml":
+	case ".yaml", ".yml":
+		return c.loadYaml(content)
+	case ".toml":
+		return c.loadToml(content)
 	default:
 		return nil, fmt.Errorf("unsupported file type: %s", ext)
 	}
@@ -411,6 +1016,32 @@ func (c *ConfigManager) loadJson(content []byte) ([]BrickFileConfig, error) {
 	return nil, errors.New("invalid config file format")
 }
 
+func (c *ConfigManager) loadYaml(content []byte) ([]BrickFileConfig, error) {
+	var configs1 struct {
+		Bricks []BrickFileConfig `yaml:"bricks"`
+	}
+	err1 := yaml.Unmarshal(content, &configs1)
+	if err1 == nil {
+		return configs1.Bricks, nil
+	}
+	var configs2 []BrickFileConfig
+	err2 := yaml.Unmarshal(content, &configs2)
+	if err2 == nil {
+		c.configIsArray = true
+		return configs2, nil
+	}
+	return nil, errors.New("invalid config file format")
+}
+
+func (c *ConfigManager) loadToml(content []byte) ([]BrickFileConfig, error) {
+	configs, single, err := decodeConfigToml(content)
+	if err != nil {
+		return nil, err
+	}
+	c.configIsSingle = single
+	return configs, nil
+}
+
 func (c *ConfigManager) saveBrickConfig(typeID string, brickLiveID string, brickConfig []byte) (err error) {
 	// oldLastLoadedConfig := c.lastLoadedConfig
 	configs, err := c.Load()
@@ -426,7 +1057,7 @@ func (c *ConfigManager) saveBrickConfig(typeID string, brickLiveID string, brick
 	for i, config := range configs {
 		if config.MetaData.TypeID == typeID {
 			for j, live := range config.Lives {
-				if live.LiveID == brickLiveID {
+				if live.LiveID == brickLiveID && brickManager.isProfileActive(live.Profiles) {
 					newEnvs := make(map[string]string)
 					configs[i].Lives[j].Config, _ = retainEnvConfigItem(configs[i].Lives[j].Config, brickConfigParsed, newEnvs)
 					c.configMu.Lock()
@@ -484,6 +1115,47 @@ func (c *ConfigManager) saveChanged(brickLiveID string, configs []BrickFileConfi
 			}
 			err2 = WriteFilePerm(c.filePath, content)
 		}
+	case ".yaml", ".yml":
+		if c.configIsArray {
+			content, err := yaml.Marshal(configs)
+			if err != nil {
+				return err
+			}
+			err2 = WriteFilePerm(c.filePath, content)
+		} else {
+			fileContent, err := os.ReadFile(c.filePath)
+			if err != nil {
+				return err
+			}
+			var allConfigs map[string]any
+			err = yaml.Unmarshal(fileContent, &allConfigs)
+			if err != nil {
+				return err
+			}
+			allConfigs["bricks"] = configs
+			content, err := yaml.Marshal(allConfigs)
+			if err != nil {
+				return err
+			}
+			err2 = WriteFilePerm(c.filePath, content)
+		}
+	case ".toml":
+		var content bytes.Buffer
+		if c.configIsSingle {
+			if err := toml.NewEncoder(&content).Encode(configs[0]); err != nil {
+				return err
+			}
+		} else {
+			var allConfigs map[string]any
+			if _, err := toml.DecodeFile(c.filePath, &allConfigs); err != nil {
+				return err
+			}
+			allConfigs["bricks"] = configs
+			if err := toml.NewEncoder(&content).Encode(allConfigs); err != nil {
+				return err
+			}
+		}
+		err2 = WriteFilePerm(c.filePath, content.Bytes())
 	default:
 		return fmt.Errorf("unsupported file type: %s", ext)
 	}
@@ -494,10 +1166,14 @@ func (c *ConfigManager) saveChanged(brickLiveID string, configs []BrickFileConfi
 		setEnvConfigItem(k, v)
 	}
 	brickManager.setBrickConfig(brickLiveID, BrickConfig{
-		TypeID:  configs[i].MetaData.TypeID,
-		LiveID:  brickLiveID,
-		noCheck: configs[i].MetaData.NoCheck,
-		Config:  configs[i].Lives[j].Config,
+		TypeID:   configs[i].MetaData.TypeID,
+		LiveID:   brickLiveID,
+		noCheck:  configs[i].MetaData.NoCheck,
+		Config:   configs[i].Lives[j].Config,
+		Weight:   fileLiveWeight(configs[i].Lives[j].Weight),
+		Order:    configs[i].Lives[j].Order,
+		Disabled: configs[i].Lives[j].Disabled,
+		filePath: c.filePath,
 	})
 	return nil
 }
@@ -506,10 +1182,30 @@ func isEnvConfigItem(item string) bool {
 	return strings.HasPrefix(item, "${") && strings.HasSuffix(item, "}")
 }
 
+// expandEnvConfigItem replaces the placeholders `${NAME}` and `${NAME:default}` of an env config item
+// with the value of the environment variable, or the default if the variable is empty.
+func expandEnvConfigItem(item string) string {
+	return os.Expand(item, func(placeholder string) string {
+		if flagPlaceholder, ok := strings.CutPrefix(placeholder, flagPlaceholderPrefix); ok {
+			return expandFlagPlaceholder(flagPlaceholder)
+		}
+		name, defaultValue, hasDefault := parseEnvPlaceholder(placeholder)
+		if value := os.Getenv(name); value != "" || !hasDefault {
+			return value
+		}
+		return defaultValue
+	})
+}
+
 func setEnvConfigItem(item string, value string) {
 	item = strings.TrimPrefix(item, "${")
 	item = strings.TrimSuffix(item, "}")
-	_ = os.Setenv(item, value)
+	if strings.HasPrefix(item, flagPlaceholderPrefix) {
+		// The flags are read-only, the placeholder is retained without changing the flag.
+		return
+	}
+	name, _, _ := parseEnvPlaceholder(item)
+	_ = os.Setenv(name, value)
 }
 
 func retainEnvConfigItem(oldConfig any, newConfig any, newEnvs map[string]string) (conf any, maybeReplaced bool) {
@@ -571,3 +1267,11 @@ func retainEnvConfigItem(oldConfig any, newConfig any, newEnvs map[string]string
 	}
 	return newConfig, false
 }
+
+// fileLiveWeight returns the weight of a live in a config file, 0 if the weight is not given.
+func fileLiveWeight(weight *int) int {
+	if weight == nil {
+		return 0
+	}
+	return *weight
+}
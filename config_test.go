@@ -1,6 +1,15 @@
 package brick
 
-import "testing"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
 
 func TestBrickManager_addConfigFileJson(t *testing.T) {
 	tests := []struct {
@@ -53,3 +62,612 @@ func TestBrickManager_addConfigFileJson(t *testing.T) {
 		})
 	}
 }
+
+type TestBrick21 struct{}
+
+func (t *TestBrick21) BrickTypeID() string {
+	return "TestBrick21"
+}
+
+func TestSetFailFastOnConfig(t *testing.T) {
+	Register[*TestBrick21]()
+	SetFailFastOnConfig(true)
+	defer SetFailFastOnConfig(false)
+	t.Cleanup(func() {
+		brickManager.deleteBrickConfig("TestBrick21 noCheck")
+		brickManager.deleteBrickConfig("TestBrick21 noConfig")
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "registered without parser",
+			content: `[{"metaData": {"typeID": "TestBrick21"}, "lives": [{"liveID": "TestBrick21", "config": {"a": 1}}]}]`,
+			wantErr: "no config parser",
+		},
+		{
+			name:    "unregistered",
+			content: `[{"metaData": {"typeID": "TestBrick21 unregistered"}, "lives": [{"liveID": "TestBrick21 unregistered", "config": {"a": 1}}]}]`,
+			wantErr: "not registered",
+		},
+		{
+			name:    "unregistered without check",
+			content: `[{"metaData": {"typeID": "TestBrick21 noCheck", "noCheck": true}, "lives": [{"liveID": "TestBrick21 noCheck", "config": {"a": 1}}]}]`,
+		},
+		{
+			name:    "unregistered without config",
+			content: `[{"metaData": {"typeID": "TestBrick21 noConfig"}, "lives": [{"liveID": "TestBrick21 noConfig"}]}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bricks.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			err := AddConfigFile(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("AddConfigFile() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), path) {
+				t.Errorf("AddConfigFile() error = %v, want %q naming the file", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type TestBrick24 struct {
+	Name   string `json:"name"`
+	Region string `json:"region"`
+}
+
+func (t *TestBrick24) BrickTypeID() string {
+	return "TestBrick24"
+}
+
+func (t *TestBrick24) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick24{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func TestAddConfigTransform(t *testing.T) {
+	dropUnregisteredConfigs()
+	RegisterNewer[*TestBrick24]()
+	t.Cleanup(func() {
+		brickManager.configTransformsLock.Lock()
+		brickManager.configTransforms = nil
+		brickManager.configTransformsLock.Unlock()
+	})
+	var seen []string
+	AddConfigTransform(func(typeID, liveID string, config any) any {
+		seen = append(seen, typeID+"/"+liveID)
+		m, _ := config.(map[string]any)
+		if m == nil {
+			m = map[string]any{}
+		}
+		if _, ok := m["region"]; !ok {
+			m["region"] = "${TEST_BRICK24_REGION}"
+		}
+		return m
+	})
+	AddConfigTransform(func(typeID, liveID string, config any) any {
+		m := config.(map[string]any)
+		if m["region"] != "${TEST_BRICK24_REGION}" {
+			m["region"] = strings.ToUpper(m["region"].(string))
+		}
+		return m
+	})
+	t.Setenv("TEST_BRICK24_REGION", "default")
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick24"},
+		"lives": [
+			{"liveID": "TestBrick24", "config": {"name": "a"}},
+			{"liveID": "TestBrick24 b", "config": {"name": "b", "region": "eu"}},
+			{"liveID": "TestBrick24 c"}
+		]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"TestBrick24/TestBrick24", "TestBrick24/TestBrick24 b", "TestBrick24/TestBrick24 c"}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("transformed lives = %v, want %v", seen, want)
+	}
+	for liveID, region := range map[string]string{"TestBrick24": "default", "TestBrick24 b": "EU", "TestBrick24 c": "default"} {
+		if got := Get[*TestBrick24](liveID).Region; got != region {
+			t.Errorf("Get(%s).Region = %q, want %q", liveID, got, region)
+		}
+	}
+}
+
+type TestBrick30 struct {
+	Format string `json:"format"`
+	Prefix string `json:"prefix"`
+	Level  struct {
+		Min string `json:"min"`
+		Max string `json:"max"`
+	} `json:"level"`
+}
+
+func (t *TestBrick30) BrickTypeID() string {
+	return "TestBrick30"
+}
+
+func (t *TestBrick30) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick30{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func TestSharedConfig(t *testing.T) {
+	RegisterNewer[*TestBrick30]()
+	t.Setenv("TEST_BRICK30_FORMAT", "json")
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick30"},
+		"sharedConfig": {"format": "${TEST_BRICK30_FORMAT}", "prefix": "shared", "level": {"min": "debug", "max": "error"}},
+		"lives": [
+			{"liveID": "TestBrick30", "config": {"prefix": "a", "level": {"min": "info"}}},
+			{"liveID": "TestBrick30 b", "config": {"prefix": "b", "format": "text"}},
+			{"liveID": "TestBrick30 c"}
+		]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		liveID                             string
+		format, prefix, minLevel, maxLevel string
+	}{
+		{"TestBrick30", "json", "a", "info", "error"},
+		{"TestBrick30 b", "text", "b", "debug", "error"},
+		{"TestBrick30 c", "json", "shared", "debug", "error"},
+	}
+	for _, tt := range tests {
+		got := Get[*TestBrick30](tt.liveID)
+		if got.Format != tt.format || got.Prefix != tt.prefix || got.Level.Min != tt.minLevel || got.Level.Max != tt.maxLevel {
+			t.Errorf("Get(%s) = %+v, want format %s, prefix %s, level %s-%s", tt.liveID, got, tt.format, tt.prefix, tt.minLevel, tt.maxLevel)
+		}
+	}
+	config, _ := brickManager.getBrickConfig("TestBrick30")
+	if format := config.Config.(map[string]any)["format"]; format != "${TEST_BRICK30_FORMAT}" {
+		t.Errorf("stored format = %v, want the placeholder kept", format)
+	}
+}
+
+type TestBrick45 struct{}
+
+func (t *TestBrick45) BrickTypeID() string {
+	return "TestBrick45"
+}
+
+func TestAddConfigFileWithOptions(t *testing.T) {
+	Register[*TestBrick45]()
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, "legacy.json")
+	current := filepath.Join(dir, "current.json")
+	if err := os.WriteFile(legacy, []byte(`[{"metaData": {"typeID": "TestBrick45"}, "lives": [{"liveID": "TestBrick45 legacy"}]}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(current, []byte(`[{"metaData": {"typeID": "TestCurrent45"}, "lives": [{"liveID": "TestCurrent45 a"}]}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AddConfigFileWithOptions(legacy, ConfigOptions{DisableLiveIDConstraint: true}); err != nil {
+		t.Errorf("AddConfigFileWithOptions() error = %v, want the constraint disabled", err)
+	}
+	if err := ReloadConfigFile(legacy); err != nil {
+		t.Errorf("ReloadConfigFile() error = %v, want the options kept on reload", err)
+	}
+	err := AddConfigFile(current)
+	if err == nil || !strings.Contains(err.Error(), "must have one set to the typeID") {
+		t.Errorf("AddConfigFile() error = %v, want the liveID constraint error", err)
+	}
+}
+
+type TestBrick60 struct {
+	BrickBase[*TestBrick60]
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Replicas []struct {
+		Host string `json:"host"`
+	} `json:"replicas"`
+}
+
+func (t *TestBrick60) BrickTypeID() string {
+	return "TestBrick60"
+}
+
+func (t *TestBrick60) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick60{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func TestAddConfigFileToml(t *testing.T) {
+	t.Setenv("TEST_BRICK60_HOST", "")
+	t.Setenv("TEST_BRICK60_REPLICA", "replica1")
+	RegisterNewer[*TestBrick60]()
+	path := filepath.Join(t.TempDir(), "db.toml")
+	content := `
+[[bricks]]
+[bricks.metaData]
+typeID = "TestBrick60"
+
+[[bricks.lives]]
+liveID = "TestBrick60"
+[bricks.lives.config]
+host = "${TEST_BRICK60_HOST:localhost}"
+port = 5432
+[[bricks.lives.config.replicas]]
+host = "${TEST_BRICK60_REPLICA}"
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	b := Get[*TestBrick60]()
+	if b.Host != "localhost" || b.Port != 5432 || len(b.Replicas) != 1 || b.Replicas[0].Host != "replica1" {
+		t.Errorf("brick = %+v, want the nested config with the env placeholders expanded", b)
+	}
+
+	if err := b.SaveBrickConfig(map[string]any{"host": "localhost", "port": 5433}); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs, err := parseConfigToml(saved)
+	if err != nil {
+		t.Fatalf("parse the saved file: %v\n%s", err, saved)
+	}
+	config := configs[0].Lives[0].Config.(map[string]any)
+	if fmt.Sprint(config["port"]) != "5433" || config["host"] != "${TEST_BRICK60_HOST:localhost}" {
+		t.Errorf("saved config = %v, want the new port and the placeholder kept", config)
+	}
+}
+
+func TestParseConfigTomlSingleBrick(t *testing.T) {
+	configs, err := parseConfigToml([]byte(`
+[metaData]
+typeID = "TestBrick60"
+
+[[lives]]
+liveID = "TestBrick60"
+config = { host = "a", tags = ["x", "y"] }
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) != 1 || configs[0].MetaData.TypeID != "TestBrick60" || len(configs[0].Lives) != 1 {
+		t.Fatalf("parseConfigToml() = %+v, want a single brick", configs)
+	}
+	if _, err := parseConfigToml([]byte(`name = "no brick"`)); err == nil {
+		t.Error("parseConfigToml() of a file without brick succeeded, want an error")
+	}
+}
+
+type TestBrick63 struct {
+	BrickBase[*TestBrick63]
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func (t *TestBrick63) BrickTypeID() string {
+	return "TestBrick63"
+}
+
+func (t *TestBrick63) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick63{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func TestSaveBrickConfigYaml(t *testing.T) {
+	t.Setenv("TEST_BRICK63_HOST", "localhost")
+	RegisterNewer[*TestBrick63]()
+	path := filepath.Join(t.TempDir(), "db.yaml")
+	content := `
+version: 2
+bricks:
+  - metaData:
+      typeID: TestBrick63
+    lives:
+      - liveID: TestBrick63
+        config:
+          host: ${TEST_BRICK63_HOST}
+          port: 5432
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	b := Get[*TestBrick63]()
+	if err := b.SaveBrickConfig(map[string]any{"host": "localhost", "port": 5433}); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs, err := parseConfigYaml(saved)
+	if err != nil {
+		t.Fatalf("parse the saved file: %v\n%s", err, saved)
+	}
+	config := configs[0].Lives[0].Config.(map[string]any)
+	if fmt.Sprint(config["port"]) != "5433" || config["host"] != "${TEST_BRICK63_HOST}" {
+		t.Errorf("saved config = %v, want the new port and the placeholder kept", config)
+	}
+	if !strings.Contains(string(saved), "version: 2") {
+		t.Errorf("the other top-level keys are not kept:\n%s", saved)
+	}
+}
+
+type testCache65 interface {
+	CacheName() string
+}
+
+type TestBrick65 struct {
+	Cache testCache65 `brick:"cache65"`
+}
+
+func (t *TestBrick65) BrickTypeID() string {
+	return "TestBrick65"
+}
+
+type TestBrick651 struct{}
+
+func (t *TestBrick651) BrickTypeID() string {
+	return "TestBrick651"
+}
+
+func (t *TestBrick651) CacheName() string {
+	return "TestBrick651"
+}
+
+func TestConfigLiveIDTypes(t *testing.T) {
+	Register[*TestBrick65]()
+	Register[*TestBrick651]()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "types.json")
+	content := `{"bricks": [], "liveIDTypes": {"cache65": "TestBrick651"}}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if name := Get[*TestBrick65]().Cache.CacheName(); name != "TestBrick651" {
+		t.Errorf("the interface field is bound to %s, want TestBrick651", name)
+	}
+
+	path = filepath.Join(dir, "unknown.yaml")
+	content = "bricks: []\nliveIDTypes:\n  cache651: TestBrick65Unknown\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddConfigFile(path); err == nil || !strings.Contains(err.Error(), "typeID(TestBrick65Unknown) of liveID(cache651)") {
+		t.Errorf("AddConfigFile() with an unregistered typeID = %v, want an error", err)
+	}
+}
+
+type TestBrick84 struct {
+	Name string `json:"name"`
+}
+
+func (t *TestBrick84) BrickTypeID() string {
+	return "TestBrick84"
+}
+
+func (t *TestBrick84) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick84{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func TestAddConfigFilesSameTypeID(t *testing.T) {
+	RegisterNewer[*TestBrick84]()
+	dir := t.TempDir()
+	writeConfig := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	primary := writeConfig("primary.json", `[{"metaData": {"typeID": "TestBrick84"}, "lives": [
+		{"liveID": "TestBrick84", "config": {"name": "default"}},
+		{"liveID": "TestBrick84 a", "config": {"name": "a"}}
+	]}]`)
+	extra := writeConfig("extra.json", `[{"metaData": {"typeID": "TestBrick84"}, "lives": [
+		{"liveID": "TestBrick84 b", "config": {"name": "b"}}
+	]}]`)
+	duplicate := writeConfig("duplicate.json", `[{"metaData": {"typeID": "TestBrick84"}, "lives": [
+		{"liveID": "TestBrick84 a", "config": {"name": "a again"}}
+	]}]`)
+
+	// Without the default live, the first file of the type breaks the liveID constraint.
+	if err := NewBrickManager().AddConfigFileWithOptions(extra, ConfigOptions{}); err == nil {
+		t.Error("a file without the default live of a new type is accepted")
+	}
+	if err := AddConfigFile(primary); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddConfigFile(extra); err != nil {
+		t.Fatalf("AddConfigFile() of a second file of the same typeID error = %v", err)
+	}
+	for liveID, want := range map[string]string{"TestBrick84": "default", "TestBrick84 a": "a", "TestBrick84 b": "b"} {
+		if got := Get[*TestBrick84](liveID).Name; got != want {
+			t.Errorf("Get(%s).Name = %s, want %s", liveID, got, want)
+		}
+	}
+	if err := AddConfigFile(duplicate); err == nil || !strings.Contains(err.Error(), "liveID duplicate: TestBrick84 a") {
+		t.Errorf("AddConfigFile() of a duplicate liveID error = %v", err)
+	}
+}
+
+type TestBrick86 struct {
+	BrickBase[*TestBrick86]
+	Name string `json:"name"`
+}
+
+func (t *TestBrick86) BrickTypeID() string {
+	return "TestBrick86"
+}
+
+func (t *TestBrick86) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick86{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func TestAddConfig(t *testing.T) {
+	RegisterNewer[*TestBrick86]()
+	var config BrickFileConfig
+	config.MetaData.TypeID = "TestBrick86"
+	if err := AddConfig([]BrickFileConfig{config}); err != nil {
+		t.Fatalf("AddConfig() of a brick without lives error = %v", err)
+	}
+	if err := AddLive("", "TestBrick86", nil); err == nil || err.Error() != "typeID is required" {
+		t.Errorf("AddLive() without typeID error = %v", err)
+	}
+	if err := AddLive("TestBrick86", "", nil); err == nil || !strings.Contains(err.Error(), "liveID of brick(TestBrick86) is required") {
+		t.Errorf("AddLive() without liveID error = %v", err)
+	}
+	if err := AddLive("TestBrick86", "TestBrick86", map[string]any{"name": "${TEST_BRICK86_NAME:default}"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddLive("TestBrick86", "TestBrick86 a", struct {
+		Name string `json:"name"`
+	}{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddLive("TestBrick86", "TestBrick86 a", nil); err == nil || !strings.Contains(err.Error(), "liveID duplicate: TestBrick86 a") {
+		t.Errorf("AddLive() of a duplicate liveID error = %v", err)
+	}
+	for liveID, want := range map[string]string{"TestBrick86": "default", "TestBrick86 a": "a"} {
+		if got := Get[*TestBrick86](liveID).Name; got != want {
+			t.Errorf("Get(%s).Name = %s, want %s", liveID, got, want)
+		}
+	}
+
+	// No file backs the config, it is saved in memory.
+	t.Setenv("TEST_BRICK86_NAME", "")
+	if err := Get[*TestBrick86]().SaveBrickConfig(map[string]any{"name": "saved"}); err != nil {
+		t.Fatalf("SaveBrickConfig() of an in-memory config error = %v", err)
+	}
+	saved, _ := brickManager.getBrickConfig("TestBrick86")
+	if got := saved.Config.(map[string]any)["name"]; got != "${TEST_BRICK86_NAME:default}" {
+		t.Errorf("saved config name = %v, want the placeholder kept", got)
+	}
+	if got := os.Getenv("TEST_BRICK86_NAME"); got != "saved" {
+		t.Errorf("TEST_BRICK86_NAME = %s, want saved", got)
+	}
+}
+
+func TestAddConfigFileTwice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "twice.json")
+	if err := os.WriteFile(path, []byte(`[{"metaData": {"typeID": "TestBrick86"}, "lives": [{"liveID": "TestBrick86"}]}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	b := NewBrickManager()
+	if err := b.AddConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	err := b.AddConfigFile(path)
+	if !errors.Is(err, ErrConfigFileAlreadyAdded) {
+		t.Fatalf("AddConfigFile() of the same file error = %v, want ErrConfigFileAlreadyAdded", err)
+	}
+	if len(b.configs) != 1 {
+		t.Errorf("%d config files are added, want 1", len(b.configs))
+	}
+}
+
+type TestBrick90 struct {
+	BrickBase[*TestBrick90]
+	Host string `json:"host"`
+}
+
+func (t *TestBrick90) BrickTypeID() string {
+	return "TestBrick90"
+}
+
+func (t *TestBrick90) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick90{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func TestActiveProfiles(t *testing.T) {
+	RegisterNewer[*TestBrick90]()
+	SetActiveProfiles("staging", "prod")
+	t.Cleanup(func() { SetActiveProfiles() })
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	if err := os.WriteFile(path, []byte(`[{"metaData": {"typeID": "TestBrick90"}, "lives": [
+		{"liveID": "TestBrick90", "config": {"host": "always"}},
+		{"liveID": "TestBrick90 db", "profiles": ["dev"], "config": {"host": "dev"}},
+		{"liveID": "TestBrick90 db", "profiles": ["prod"], "config": {"host": "prod"}},
+		{"liveID": "TestBrick90 debug", "profiles": ["dev", "test"], "config": {"host": "debug"}}
+	]}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddConfigFile(path); err != nil {
+		t.Fatalf("AddConfigFile() error = %v, want the lives of the inactive profiles ignored", err)
+	}
+	for liveID, want := range map[string]string{"TestBrick90": "always", "TestBrick90 db": "prod"} {
+		if got := Get[*TestBrick90](liveID).Host; got != want {
+			t.Errorf("Get(%s).Host = %s, want %s", liveID, got, want)
+		}
+	}
+	if _, ok := brickManager.getBrickConfig("TestBrick90 debug"); ok {
+		t.Error("the live of the inactive profiles is added")
+	}
+
+	// The active live of the liveID is saved.
+	if err := Get[*TestBrick90]("TestBrick90 db").SaveBrickConfig(map[string]any{"host": "prod2"}); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs, err := parseConfigJson(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := make([]any, 0, len(configs[0].Lives))
+	for _, live := range configs[0].Lives {
+		hosts = append(hosts, live.Config.(map[string]any)["host"])
+	}
+	if want := []any{"always", "dev", "prod2", "debug"}; !reflect.DeepEqual(hosts, want) {
+		t.Errorf("saved hosts = %v, want %v", hosts, want)
+	}
+}
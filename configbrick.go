@@ -0,0 +1,52 @@
+package brick
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// RegisterConfigBrick registers the config struct Cfg as a brick of the typeID, so that a `*Cfg` field tagged
+// with a live of the typeID is injected with the config of the live unmarshaled into a new Cfg.
+// Like any brick, it is a singleton of its live, the bricks injecting the same live share one config object.
+// It must be registered before the bricks depending on it. Cfg is not a Brick, use GetConfigBrick to get it.
+func RegisterConfigBrick[Cfg any](typeID string) {
+	typ := reflect.TypeOf((*Cfg)(nil))
+	if typ.Elem().Kind() != reflect.Struct {
+		panic(fmt.Errorf("the config brick %s must be a struct", typ.Elem()))
+	}
+	if isBrickType(typ) {
+		panic(fmt.Errorf("the config brick %s implements Brick, please register it by Register", typ.Elem()))
+	}
+	brickManager.register(RegisterBrickParam{
+		TypeID:      typeID,
+		ReflectType: typ,
+		BrickFactory: func(jsonConfig []byte) Brick {
+			config := reflect.New(typ.Elem())
+			if len(jsonConfig) > 0 {
+				if err := json.Unmarshal(jsonConfig, config.Interface()); err != nil {
+					panic(fmt.Errorf("parse the config of config brick(%s) error: %w", typeID, err))
+				}
+			}
+			return configBrick{value: config}
+		},
+	})
+}
+
+// GetConfigBrick returns the config brick of the liveID registered by RegisterConfigBrick, like Get.
+func GetConfigBrick[Cfg any](liveID ...string) *Cfg {
+	brickManager.brickConfigCheckOnce.Do(brickManager.checkConfig)
+	ctx := getBrickInstanceCtx{
+		buildingBrick: make(map[reflect.Type]bool),
+	}
+	return getBrickInstance(reflect.TypeOf((*Cfg)(nil)), ctx, liveID...).Interface().(*Cfg)
+}
+
+// configBrick is the Brick returned by the factory of a config brick, holding the new `*Cfg`.
+type configBrick struct {
+	value reflect.Value
+}
+
+func (c configBrick) BrickTypeID() string {
+	return ""
+}
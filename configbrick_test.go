@@ -0,0 +1,51 @@
+package brick
+
+import "testing"
+
+type TestBrick93Config struct {
+	DSN     string `json:"dsn"`
+	MaxConn int    `json:"maxConn"`
+}
+
+type TestBrick93 struct {
+	Config *TestBrick93Config `brick:"TestBrick93Config"`
+}
+
+func (t *TestBrick93) BrickTypeID() string {
+	return "TestBrick93"
+}
+
+type TestBrick931 struct {
+	Config *TestBrick93Config `brick:"TestBrick93Config"`
+}
+
+func (t *TestBrick931) BrickTypeID() string {
+	return "TestBrick931"
+}
+
+func TestRegisterConfigBrick(t *testing.T) {
+	RegisterConfigBrick[TestBrick93Config]("TestBrick93Config")
+	Register[*TestBrick93]()
+	Register[*TestBrick931]()
+	if err := AddLive("TestBrick93Config", "TestBrick93Config", map[string]any{"dsn": "db:5432", "maxConn": 8}); err != nil {
+		t.Fatal(err)
+	}
+
+	a, b := Get[*TestBrick93](), Get[*TestBrick931]()
+	if a.Config == nil || a.Config != b.Config {
+		t.Fatalf("the config brick is not shared: %p, %p", a.Config, b.Config)
+	}
+	if a.Config.DSN != "db:5432" || a.Config.MaxConn != 8 {
+		t.Errorf("config = %+v, want the config of the live", *a.Config)
+	}
+	if got := GetConfigBrick[TestBrick93Config](); got != a.Config {
+		t.Errorf("GetConfigBrick() = %p, want %p", got, a.Config)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterConfigBrick() of a brick type should panic")
+		}
+	}()
+	RegisterConfigBrick[TestBrick93]("TestBrick93")
+}
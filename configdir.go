@@ -0,0 +1,214 @@
+package brick
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// localLayer is the layer suffix of the config files overriding the other layers, e.g. `db.local.yaml`.
+const localLayer = ".local"
+
+// AddConfigDir adds the config files of a directory, subdirectories and files of unsupported formats are skipped.
+//
+// The name of a config file is `[<number>-]<name>[.local].<ext>`, files with the same <name> are layers of one config:
+//   - `db.yaml` is the base layer, its number is 0.
+//   - `10-db.yaml` is a layer applied over the layers with a smaller number, e.g. an environment layer.
+//   - `db.local.yaml` and `10-db.local.yaml` are local layers, applied after every other layer, ordered by number.
+//
+// A layer is merged over the previous ones brick by brick (matched by typeID) and live by live (matched by liveID):
+// configs are merged recursively with the values of the layer taking precedence, and new bricks and lives are added.
+// Arrays are replaced, unless a merge key is set for their path by SetConfigMergeKey.
+// Different formats can be mixed, e.g. `db.yaml` and `db.local.json`.
+//
+// The configs are added in order of their smallest number, then of their name.
+// A config made of one file is added like AddConfigFile, a config merged from several files is not backed by a file,
+// so it can't be reloaded by ReloadConfigFile.
+func AddConfigDir(dir string) error {
+	return brickManager.AddConfigDir(dir)
+}
+
+// AddConfigGlob adds the config files matching the pattern (see filepath.Glob) like AddConfigFile,
+// in lexical order of their path, directories are skipped. It stops at the first file failing to be added,
+// the files added before it stay added. There is no error if no file matches.
+func AddConfigGlob(pattern string) error {
+	return brickManager.AddConfigGlob(pattern)
+}
+
+// AddConfigGlob adds the config files matching the pattern like AddConfigFile, in lexical order of their path.
+func (b *BrickManager) AddConfigGlob(pattern string) error {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return fmt.Errorf("config glob(%s): %w", pattern, err)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			continue
+		}
+		if err := b.AddConfigFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configLayer is a config file of a directory.
+type configLayer struct {
+	path   string
+	number int
+	local  bool
+}
+
+// AddConfigDir adds the config files of a directory, merging the files with the same name by layer.
+func (b *BrickManager) AddConfigDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	layers := make(map[string][]configLayer)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name, layer, ok := parseConfigLayer(entry.Name())
+		if !ok {
+			continue
+		}
+		layer.path = filepath.Join(dir, entry.Name())
+		layers[name] = append(layers[name], layer)
+	}
+	names := make([]string, 0, len(layers))
+	for name, nameLayers := range layers {
+		sort.Slice(nameLayers, func(i, j int) bool {
+			if nameLayers[i].local != nameLayers[j].local {
+				return !nameLayers[i].local
+			}
+			if nameLayers[i].number != nameLayers[j].number {
+				return nameLayers[i].number < nameLayers[j].number
+			}
+			return nameLayers[i].path < nameLayers[j].path
+		})
+		names = append(names, name)
+	}
+	minNumber := func(name string) int {
+		n := layers[name][0].number
+		for _, layer := range layers[name] {
+			n = min(n, layer.number)
+		}
+		return n
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if ni, nj := minNumber(names[i]), minNumber(names[j]); ni != nj {
+			return ni < nj
+		}
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		if len(layers[name]) == 1 {
+			if err := b.AddConfigFile(layers[name][0].path); err != nil {
+				return err
+			}
+			continue
+		}
+		var merged []BrickFileConfig
+		liveIDTypes := make(map[string]string)
+		for _, layer := range layers[name] {
+			content, err := os.ReadFile(layer.path)
+			if err != nil {
+				return err
+			}
+			configs, err := parseConfigFile(layer.path, content)
+			if err != nil {
+				return fmt.Errorf("config file(%s): %w", layer.path, err)
+			}
+			// A live of an inactive profile doesn't override the live of the previous layers.
+			b.dropInactiveLives(configs)
+			merged = b.mergeFileConfigs(merged, configs)
+			layerLiveIDTypes, err := parseLiveIDTypes(layer.path, content)
+			if err != nil {
+				return fmt.Errorf("config file(%s): %w", layer.path, err)
+			}
+			maps.Copy(liveIDTypes, layerLiveIDTypes)
+		}
+		if err := b.checkLiveIDTypes(liveIDTypes); err != nil {
+			return fmt.Errorf("config(%s) in %s: %w", name, dir, err)
+		}
+		if err := b.addConfigFrom(merged, "", ConfigOptions{}); err != nil {
+			return fmt.Errorf("config(%s) in %s: %w", name, dir, err)
+		}
+		b.setLiveIDTypes(liveIDTypes)
+	}
+	return nil
+}
+
+// parseConfigLayer parses the name of a config file `[<number>-]<name>[.local].<ext>`.
+func parseConfigLayer(fileName string) (name string, layer configLayer, ok bool) {
+	ext := filepath.Ext(fileName)
+	switch ext {
+	case ".json", ".yaml", ".yml", ".toml":
+	default:
+		return "", configLayer{}, false
+	}
+	name = strings.TrimSuffix(fileName, ext)
+	if strings.HasSuffix(name, localLayer) {
+		layer.local = true
+		name = strings.TrimSuffix(name, localLayer)
+	}
+	if prefix, rest, found := strings.Cut(name, "-"); found {
+		if n, err := strconv.Atoi(prefix); err == nil {
+			layer.number = n
+			name = rest
+		}
+	}
+	return name, layer, name != ""
+}
+
+// mergeFileConfigs merges the configs of a layer over the base configs, bricks are matched by typeID and lives by liveID.
+func (b *BrickManager) mergeFileConfigs(base []BrickFileConfig, layer []BrickFileConfig) []BrickFileConfig {
+	for _, config := range layer {
+		i := 0
+		for i < len(base) && base[i].MetaData.TypeID != config.MetaData.TypeID {
+			i++
+		}
+		if i == len(base) {
+			base = append(base, config)
+			continue
+		}
+		target := &base[i]
+		mergeKeys := b.getConfigMergeKeys(config.MetaData.TypeID)
+		if config.MetaData.Name != "" {
+			target.MetaData.Name = config.MetaData.Name
+		}
+		target.MetaData.NoCheck = target.MetaData.NoCheck || config.MetaData.NoCheck
+		target.SharedConfig = mergeConfig(target.SharedConfig, config.SharedConfig, mergeKeys)
+		for _, live := range config.Lives {
+			j := 0
+			for j < len(target.Lives) && target.Lives[j].LiveID != live.LiveID {
+				j++
+			}
+			if j == len(target.Lives) {
+				target.Lives = append(target.Lives, live)
+				continue
+			}
+			targetLive := &target.Lives[j]
+			targetLive.Config = mergeConfig(targetLive.Config, live.Config, mergeKeys)
+			if live.Weight != nil {
+				targetLive.Weight = live.Weight
+			}
+			if live.Order != 0 {
+				targetLive.Order = live.Order
+			}
+		}
+	}
+	return base
+}
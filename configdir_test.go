@@ -0,0 +1,193 @@
+package brick
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type TestBrick36 struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+func (t *TestBrick36) BrickTypeID() string {
+	return "TestBrick36"
+}
+
+func (t *TestBrick36) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick36{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+type TestBrick361 struct {
+	Size int `json:"size"`
+}
+
+func (t *TestBrick361) BrickTypeID() string {
+	return "TestBrick361"
+}
+
+func (t *TestBrick361) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick361{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func TestAddConfigDir(t *testing.T) {
+	RegisterNewer[*TestBrick36]()
+	RegisterNewer[*TestBrick361]()
+	dir := t.TempDir()
+	files := map[string]string{
+		"db.yaml": `
+- metaData:
+    typeID: TestBrick36
+  lives:
+    - liveID: TestBrick36
+      config:
+        host: base
+        port: 1
+    - liveID: TestBrick36 replica
+      config:
+        host: replica
+        port: 1
+`,
+		"10-db.json": `[{"metaData": {"typeID": "TestBrick36"}, "lives": [{"liveID": "TestBrick36", "config": {"port": 2}}]}]`,
+		"db.local.yaml": `
+- metaData:
+    typeID: TestBrick36
+  lives:
+    - liveID: TestBrick36
+      config:
+        host: localhost
+    - liveID: TestBrick36 local
+      config:
+        host: local only
+`,
+		"05-cache.json": `[{"metaData": {"typeID": "TestBrick361"}, "lives": [{"liveID": "TestBrick361", "config": {"size": 64}}]}]`,
+		"README.md":     `not a config`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "nested", "db.json"), []byte(`{`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AddConfigDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		liveID string
+		want   TestBrick36
+	}{
+		{"TestBrick36", TestBrick36{Host: "localhost", Port: 2}},
+		{"TestBrick36 replica", TestBrick36{Host: "replica", Port: 1}},
+		{"TestBrick36 local", TestBrick36{Host: "local only"}},
+	}
+	for _, tt := range tests {
+		if got := Get[*TestBrick36](tt.liveID); *got != tt.want {
+			t.Errorf("Get(%s) = %+v, want %+v", tt.liveID, *got, tt.want)
+		}
+	}
+	if got := Get[*TestBrick361]().Size; got != 64 {
+		t.Errorf("Size = %d, want 64", got)
+	}
+}
+
+func TestParseConfigLayer(t *testing.T) {
+	tests := []struct {
+		fileName string
+		name     string
+		layer    configLayer
+		ok       bool
+	}{
+		{"db.yaml", "db", configLayer{}, true},
+		{"10-db.yml", "db", configLayer{number: 10}, true},
+		{"db.local.json", "db", configLayer{local: true}, true},
+		{"20-db.local.yaml", "db", configLayer{number: 20, local: true}, true},
+		{"my-db.yaml", "my-db", configLayer{}, true},
+		{"db.txt", "", configLayer{}, false},
+	}
+	for _, tt := range tests {
+		name, layer, ok := parseConfigLayer(tt.fileName)
+		if name != tt.name || layer != tt.layer || ok != tt.ok {
+			t.Errorf("parseConfigLayer(%s) = %s, %+v, %v, want %s, %+v, %v", tt.fileName, name, layer, ok, tt.name, tt.layer, tt.ok)
+		}
+	}
+}
+
+func TestConfigMergeKey(t *testing.T) {
+	m := NewBrickManager()
+	m.SetConfigMergeKey("TestBrick73", "server.routes", "name")
+	m.SetConfigMergeKey("TestBrick73", "server.routes.filters", "id")
+	base := `[{"metaData": {"typeID": "TestBrick73", "noCheck": true}, "lives": [{"liveID": "TestBrick73", "config": {"server": {"routes": [
+		{"name": "users", "path": "/users", "timeout": 1, "filters": [{"id": "auth", "on": true}]},
+		{"name": "orders", "path": "/orders", "timeout": 1}
+	], "tags": ["a", "b"]}}}]}]`
+	layer := `[{"metaData": {"typeID": "TestBrick73"}, "lives": [{"liveID": "TestBrick73", "config": {"server": {"routes": [
+		{"name": "users", "timeout": 5, "filters": [{"id": "auth", "on": false}, {"id": "log"}]},
+		{"name": "health", "path": "/health"}
+	], "tags": ["c"]}}}]}]`
+	dir := t.TempDir()
+	for name, content := range map[string]string{"app.json": base, "app.local.json": layer} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.AddConfigDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	config, _ := m.getBrickConfig("TestBrick73")
+	got, _ := json.Marshal(config.Config)
+	want := `{"server":{"routes":[` +
+		`{"filters":[{"id":"auth","on":false},{"id":"log"}],"name":"users","path":"/users","timeout":5},` +
+		`{"name":"orders","path":"/orders","timeout":1},` +
+		`{"name":"health","path":"/health"}],` +
+		`"tags":["c"]}}`
+	if string(got) != want {
+		t.Errorf("merged config = %s\nwant %s", got, want)
+	}
+}
+
+func TestAddConfigGlob(t *testing.T) {
+	RegisterNewer[*TestBrick36]()
+	dir := t.TempDir()
+	files := map[string]string{
+		"b.json": `[{"metaData": {"typeID": "TestBrick36"}, "lives": [{"liveID": "TestBrick36 b", "config": {"host": "b"}}]}]`,
+		"a.json": `[{"metaData": {"typeID": "TestBrick36"}, "lives": [{"liveID": "TestBrick36", "config": {"host": "a"}}]}]`,
+		"c.json": `[{"metaData": {"typeID": "TestBrick36"}, "lives": [{"liveID": "TestBrick36 b", "config": {"host": "c"}}]}]`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "d.json"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBrickManager()
+	err := b.AddConfigGlob(filepath.Join(dir, "*.json"))
+	if err == nil || !strings.Contains(err.Error(), "c.json") || !strings.Contains(err.Error(), "liveID duplicate: TestBrick36 b") {
+		t.Fatalf("AddConfigGlob() error = %v, want the duplicate liveID of c.json", err)
+	}
+	if config, _ := b.getBrickConfig("TestBrick36 b"); config.Config.(map[string]any)["host"] != "b" {
+		t.Errorf("the config of TestBrick36 b = %v, want the one of b.json", config.Config)
+	}
+	if err := b.AddConfigGlob("["); err == nil {
+		t.Error("AddConfigGlob() of a malformed pattern succeeds")
+	}
+}
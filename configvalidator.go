@@ -0,0 +1,94 @@
+package brick
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type configKeyValidator struct {
+	keyPath  string
+	validate func(value any) error
+}
+
+// RegisterConfigValidator registers a validator of the config key of every live of the brick type,
+// e.g. to check that a port is in range. The keyPath is separated by dots, e.g. "server.port".
+//
+// The validator receives the value after the environment variables are expanded, decoded as JSON
+// (numbers are float64), and it is not called if the key is absent.
+// Validators run at the first Get, which panics with the errors of every invalid live, and on reload,
+// which is rejected if a validator fails.
+func RegisterConfigValidator(typeID, keyPath string, fn func(value any) error) {
+	brickManager.RegisterConfigValidator(typeID, keyPath, fn)
+}
+
+// RegisterConfigValidator registers a validator of the config key of every live of the brick type.
+func (b *BrickManager) RegisterConfigValidator(typeID, keyPath string, fn func(value any) error) {
+	b.configValidatorsLock.Lock()
+	defer b.configValidatorsLock.Unlock()
+	b.configValidators[typeID] = append(b.configValidators[typeID], configKeyValidator{keyPath: keyPath, validate: fn})
+}
+
+// validateStoredConfigKeys runs the config validators against every stored config, sorted by liveID.
+func (b *BrickManager) validateStoredConfigKeys() error {
+	b.brickConfigLock.RLock()
+	configs := make([]BrickConfig, 0, len(b.brickConfigs))
+	for _, config := range b.brickConfigs {
+		if !config.noCheck {
+			configs = append(configs, config)
+		}
+	}
+	b.brickConfigLock.RUnlock()
+	sort.Slice(configs, func(i, j int) bool { return configs[i].LiveID < configs[j].LiveID })
+	var errs []error
+	for _, config := range configs {
+		errs = append(errs, b.validateConfigKeys(config.TypeID, config.LiveID, config.Config)...)
+	}
+	return errors.Join(errs...)
+}
+
+// validateConfigKeys runs the config validators of the brick type against the config of a live.
+func (b *BrickManager) validateConfigKeys(typeID, liveID string, config any) []error {
+	b.configValidatorsLock.RLock()
+	validators := b.configValidators[typeID]
+	b.configValidatorsLock.RUnlock()
+	if len(validators) == 0 || config == nil {
+		return nil
+	}
+	configBytes, err := marshalBrickConfig(config)
+	if err != nil {
+		return []error{fmt.Errorf("invalid config of live(%s): %w", liveID, err)}
+	}
+	var decoded any
+	if err := json.Unmarshal(configBytes, &decoded); err != nil {
+		return []error{fmt.Errorf("invalid config of live(%s): %w", liveID, err)}
+	}
+	var errs []error
+	for _, validator := range validators {
+		value, ok := lookupConfigKey(decoded, validator.keyPath)
+		if !ok {
+			continue
+		}
+		if err := validator.validate(value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid config key(%s) of live(%s): %w", validator.keyPath, liveID, err))
+		}
+	}
+	return errs
+}
+
+// lookupConfigKey returns the value of the dot-separated key path in a decoded JSON config.
+func lookupConfigKey(config any, keyPath string) (any, bool) {
+	value := config
+	for _, key := range strings.Split(keyPath, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
@@ -0,0 +1,72 @@
+package brick
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type TestBrick48 struct {
+	Server struct {
+		Port int `json:"port"`
+	} `json:"server"`
+}
+
+func (t *TestBrick48) BrickTypeID() string {
+	return "TestBrick48"
+}
+
+func (t *TestBrick48) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick48{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func TestRegisterConfigValidator(t *testing.T) {
+	RegisterNewer[*TestBrick48]()
+	RegisterConfigValidator("TestBrick48", "server.port", func(value any) error {
+		port, ok := value.(float64)
+		if !ok || port < 1 || port > 65535 {
+			return fmt.Errorf("port %v out of range", value)
+		}
+		return nil
+	})
+	path := filepath.Join(t.TempDir(), "validator.json")
+	write := func(port int) {
+		content := fmt.Sprintf(`[{"metaData": {"typeID": "TestBrick48"}, "lives": [
+			{"liveID": "TestBrick48", "config": {"server": {"port": 8080}}},
+			{"liveID": "TestBrick48 admin", "config": {"server": {"port": %d}}},
+			{"liveID": "TestBrick48 noport", "config": {}}
+		]}]`, port)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(70000)
+	if err := AddConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	err := brickManager.validateStoredConfigKeys()
+	if err == nil || !strings.Contains(err.Error(), "key(server.port) of live(TestBrick48 admin)") {
+		t.Errorf("validateStoredConfigKeys() error = %v, want the out of range port of TestBrick48 admin", err)
+	} else if strings.Contains(err.Error(), "live(TestBrick48)") || strings.Contains(err.Error(), "noport") {
+		t.Errorf("validateStoredConfigKeys() error = %v, want only TestBrick48 admin rejected", err)
+	}
+
+	write(9090)
+	if err := ReloadConfigFile(path); err != nil {
+		t.Errorf("ReloadConfigFile() error = %v, want the valid config accepted", err)
+	}
+	write(0)
+	if err := ReloadConfigFile(path); err == nil || !strings.Contains(err.Error(), "port 0 out of range") {
+		t.Errorf("ReloadConfigFile() error = %v, want the reload rejected", err)
+	}
+	if got := Get[*TestBrick48]("TestBrick48 admin").Server.Port; got != 9090 {
+		t.Errorf("port = %d, want the config before the rejected reload", got)
+	}
+}
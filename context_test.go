@@ -0,0 +1,128 @@
+package brick
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type TestBrick37 struct {
+	TraceID string
+	Name    string `json:"name"`
+}
+
+func (t *TestBrick37) BrickTypeID() string {
+	return "TestBrick37"
+}
+
+type testTraceKey struct{}
+
+func (t *TestBrick37) NewBrickCtx(ctx context.Context, config []byte) (Brick, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var newBrick = &TestBrick37{}
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, newBrick); err != nil {
+			return nil, err
+		}
+	}
+	if newBrick.Name == "invalid" {
+		return nil, errors.New("invalid name")
+	}
+	newBrick.TraceID, _ = ctx.Value(testTraceKey{}).(string)
+	return newBrick, nil
+}
+
+func TestNewBrickCtx(t *testing.T) {
+	Register[*TestBrick37]()
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick37"},
+		"lives": [
+			{"liveID": "TestBrick37", "config": {"name": "default"}},
+			{"liveID": "TestBrick37 invalid", "config": {"name": "invalid"}}
+		]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), testTraceKey{}, "trace-1")
+	b := GetCtx[*TestBrick37](ctx)
+	if b.TraceID != "trace-1" || b.Name != "default" {
+		t.Errorf("GetCtx() = %+v, want the trace ID of the build context", b)
+	}
+	if got := GetOrCreate[*TestBrick37](RandomLiveID()); got.TraceID != "" {
+		t.Errorf("GetOrCreate() = %+v, want no trace ID without a build context", got)
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := TryGetOrCreateCtx[*TestBrick37](canceled, RandomLiveID()); !errors.Is(err, context.Canceled) {
+		t.Errorf("TryGetOrCreateCtx() with a canceled context error = %v, want context.Canceled", err)
+	}
+	if _, err := TryGetCtx[*TestBrick37](ctx, "TestBrick37 invalid"); err == nil || !strings.Contains(err.Error(), "invalid name") {
+		t.Errorf("TryGetCtx() error = %v, want the error of NewBrickCtx", err)
+	}
+
+	defer func() {
+		err := recover()
+		if err == nil || !strings.Contains(fmt.Sprint(err), "liveID(TestBrick37 invalid): invalid name") {
+			t.Errorf("recover() = %v, want the error naming the liveID", err)
+		}
+	}()
+	Get[*TestBrick37]("TestBrick37 invalid")
+}
+
+var testBrick371Cancel context.CancelFunc
+
+type TestBrick371 struct {
+	First  *TestBrick372 `brick:""`
+	Second *TestBrick373 `brick:""`
+}
+
+func (t *TestBrick371) BrickTypeID() string {
+	return "TestBrick371"
+}
+
+type TestBrick372 struct{}
+
+func (t *TestBrick372) BrickTypeID() string {
+	return "TestBrick372"
+}
+
+func (t *TestBrick372) NewBrickCtx(ctx context.Context, config []byte) (Brick, error) {
+	// The build is canceled once the first dependency is built.
+	testBrick371Cancel()
+	return &TestBrick372{}, nil
+}
+
+var testBrick373Builds int
+
+type TestBrick373 struct{}
+
+func (t *TestBrick373) BrickTypeID() string {
+	return "TestBrick373"
+}
+
+func (t *TestBrick373) NewBrick(config []byte) Brick {
+	testBrick373Builds++
+	return &TestBrick373{}
+}
+
+func TestBuildCanceledBetweenDependencies(t *testing.T) {
+	Register[*TestBrick371]()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	testBrick371Cancel = cancel
+
+	_, err := TryGetCtx[*TestBrick371](ctx)
+	if !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), "field Second") {
+		t.Errorf("TryGetCtx() canceled in the middle of the build error = %v, want context.Canceled before field Second", err)
+	}
+	if testBrick373Builds != 0 {
+		t.Errorf("the dependency after the cancellation is built %d times, want 0", testBrick373Builds)
+	}
+}
@@ -1,11 +1,36 @@
 package brick
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand/v2"
 	"reflect"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 	"unsafe"
 )
 
+var (
+	// ErrTypeNotRegistered is the error of a brick type that is not registered.
+	ErrTypeNotRegistered = errors.New("this brick type is not registered")
+	// ErrCircularDependency is the error of a brick depending on itself through its dependencies.
+	ErrCircularDependency = errors.New("circular dependency detected")
+	// ErrUnknownLiveID is the error of a liveID that is neither configured nor declared by a tag.
+	ErrUnknownLiveID = errors.New("unknown liveID")
+	// ErrLiveDisabled is the error of a live whose config is marked `disabled`.
+	ErrLiveDisabled = errors.New("this live is disabled")
+	// ErrBrickDisabled is the error of a brick type disabled by Disable without a fallback.
+	ErrBrickDisabled = errors.New("this brick type is disabled")
+	// ErrDependencyNotFound is the error of an interface dependency whose brick type can't be determined.
+	ErrDependencyNotFound = errors.New("dependency not found")
+)
+
 // GetOrCreate like Get, but it will create a new instance for unknown liveID.
 func GetOrCreate[T Brick](liveID ...string) T {
 	brickManager.brickConfigCheckOnce.Do(brickManager.checkConfig)
@@ -16,6 +41,65 @@ func GetOrCreate[T Brick](liveID ...string) T {
 	return getBrickInstance(reflect.TypeOf((*(new(T)))), ctx, liveID...).Interface().(T)
 }
 
+// TryGet like Get, but it returns the error instead of panicking, e.g. for library code embedding brick.
+// The known failures wrap ErrTypeNotRegistered, ErrCircularDependency, ErrUnknownLiveID, ErrLiveDisabled,
+// ErrBrickDisabled or ErrDependencyNotFound.
+func TryGet[T Brick](liveID ...string) (ret T, err error) {
+	defer recoverError(&err)
+	return Get[T](liveID...), nil
+}
+
+// TryGetOrCreate like GetOrCreate, but it returns the error instead of panicking.
+func TryGetOrCreate[T Brick](liveID ...string) (ret T, err error) {
+	defer recoverError(&err)
+	return GetOrCreate[T](liveID...), nil
+}
+
+// TryGetCtx like GetCtx, but it returns the error instead of panicking,
+// the errors returned by NewBrickCtx are wrapped, e.g. the error of ctx when its deadline is exceeded.
+func TryGetCtx[T Brick](ctx context.Context, liveID ...string) (ret T, err error) {
+	defer recoverError(&err)
+	return GetCtx[T](ctx, liveID...), nil
+}
+
+// TryGetOrCreateCtx like GetOrCreateCtx, but it returns the error instead of panicking.
+func TryGetOrCreateCtx[T Brick](ctx context.Context, liveID ...string) (ret T, err error) {
+	defer recoverError(&err)
+	return GetOrCreateCtx[T](ctx, liveID...), nil
+}
+
+// recoverError recovers a panic into *err, it must be deferred directly.
+func recoverError(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	e, ok := r.(error)
+	if !ok {
+		*err = fmt.Errorf("%v", r)
+		return
+	}
+	// A runtime error is a bug rather than a failure of the call, it keeps panicking.
+	var runtimeErr runtime.Error
+	if errors.As(e, &runtimeErr) {
+		panic(r)
+	}
+	*err = e
+}
+
+// GetOrCreateCtx like GetOrCreate, but ctx is passed to NewBrickCtx of the bricks (see BrickNewerCtx)
+// and to the selectors of the `selected` fields built by this call.
+// The build panics with an error wrapping ctx.Err() if ctx is done before a dependency is built.
+func GetOrCreateCtx[T Brick](ctx context.Context, liveID ...string) T {
+	brickManager.brickConfigCheckOnce.Do(brickManager.checkConfig)
+	buildCtx := getBrickInstanceCtx{
+		buildingBrick: make(map[reflect.Type]bool),
+		createUnknown: true,
+		buildCtx:      ctx,
+	}
+	return getBrickInstance(reflect.TypeOf((*(new(T)))), buildCtx, liveID...).Interface().(T)
+}
+
 // Get retrieves a brick instance, creating it if necessary.
 // If liveID is not provided, it will use the typeID as the LiveID.
 //
@@ -24,7 +108,8 @@ func GetOrCreate[T Brick](liveID ...string) T {
 // An unknown liveID is one that has not been declared in the configuration or tag,
 // and has not been explicitly registered as a non-default liveID. The default instance's liveID is the same as the typeID.
 //
-// When retrieving a non-pointer Brick instance, be aware of whether the type can be copied safely. No checks are performed for this.
+// When retrieving a non-pointer Brick instance, be aware of whether the type can be copied safely.
+// No checks are performed for this, unless enabled by SetValueCopyChecks.
 func Get[T Brick](liveID ...string) T {
 	brickManager.brickConfigCheckOnce.Do(brickManager.checkConfig)
 	ctx := getBrickInstanceCtx{
@@ -35,10 +120,228 @@ func Get[T Brick](liveID ...string) T {
 	return getBrickInstance(reflect.TypeOf((*(new(T)))), ctx, liveID...).Interface().(T)
 }
 
+// GetCtx like Get, but ctx is passed to NewBrickCtx of the bricks (see BrickNewerCtx)
+// and to the selectors of the `selected` fields built by this call.
+// The build panics with an error wrapping ctx.Err() if ctx is done before a dependency is built.
+// Instances that already exist are returned as they are.
+func GetCtx[T Brick](ctx context.Context, liveID ...string) T {
+	brickManager.brickConfigCheckOnce.Do(brickManager.checkConfig)
+	buildCtx := getBrickInstanceCtx{
+		buildingBrick: make(map[reflect.Type]bool),
+		createUnknown: false,
+		buildCtx:      ctx,
+	}
+	return getBrickInstance(reflect.TypeOf((*(new(T)))), buildCtx, liveID...).Interface().(T)
+}
+
+// GetOrZero like Get, but it returns the zero value of T (typically a nil pointer)
+// instead of panicking when the brick or one of its dependencies is not registered, not found or disabled.
+// Any other failure, e.g. a failed BrickInit, still panics.
+func GetOrZero[T Brick](liveID ...string) (ret T) {
+	defer func() {
+		if r := recover(); r != nil {
+			if !isMissingDependency(r) {
+				panic(r)
+			}
+			ret = *new(T)
+		}
+	}()
+	return Get[T](liveID...)
+}
+
+// GetAll returns every instance of T that has been constructed, indexed by liveID, e.g. for health checks.
+// Unlike a `brick:"all"` field, it never constructs the configured lives that are not built yet,
+// and deferred bricks whose construction is still pending are not included.
+// It returns an empty map while T is disabled by Disable.
+func GetAll[T Brick]() map[string]T {
+	brickType := reflect.TypeOf((*(new(T))))
+	ret := make(map[string]T)
+	if brickManager.IsDisabled(brickManager.getTypeIDByReflectType(brickType)) {
+		return ret
+	}
+	for liveID, instance := range brickManager.builtInstancesOf(brickManager.getTypeIDByReflectType(brickType)) {
+		ret[liveID] = convertInstance(instance, brickType, liveID).Interface().(T)
+	}
+	return ret
+}
+
+// GetWith like Get, but the dependencies of the brick are overridden for this build only.
+// The keys of overrides are field names or dependency liveIDs, field names take precedence.
+//
+// The returned instance is built every time and NOT cached, so the overrides never leak into the container.
+// Dependencies that are not overridden are resolved as usual.
+func GetWith[T Brick](overrides map[string]Brick, liveID ...string) T {
+	brickManager.brickConfigCheckOnce.Do(brickManager.checkConfig)
+	ctx := getBrickInstanceCtx{
+		buildingBrick: make(map[reflect.Type]bool),
+		createUnknown: false,
+		noCache:       true,
+		overrides:     overrides,
+	}
+	return getBrickInstance(reflect.TypeOf((*(new(T)))), ctx, liveID...).Interface().(T)
+}
+
+// GetPartial like GetWith, but the caller supplies the `brick` tagged fields named by manual after the build
+// with the returned setter, e.g. a dependency only known at runtime. The manual fields are not injected,
+// they are left zero until the setter replaces them with dep. The setter returns an error if the field
+// is not one of manual, or can't hold dep. The other fields are injected as usual.
+// It panics if a manual field does not exist or is not a `brick` tagged field.
+//
+// T must be a pointer type. The instance is built every time and NOT cached, like GetWith,
+// so the manual dependencies never leak into the container. The instance may be used before
+// every manual field is set, the setter must not be called concurrently with the use of the instance.
+func GetPartial[T Brick](manual []string, liveID ...string) (T, func(field string, dep Brick) error) {
+	brickType := reflect.TypeOf((*(new(T))))
+	if brickType.Kind() != reflect.Ptr {
+		panic(fmt.Errorf("GetPartial requires a pointer brick type, got %v", brickType))
+	}
+	structType := brickType
+	for structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	manualFields := make(map[string]reflect.StructField, len(manual))
+	for _, field := range manual {
+		typeField, ok := structType.FieldByName(field)
+		if !ok {
+			panic(fmt.Errorf("field %s does not exist in %v", field, structType))
+		}
+		if _, ok := typeField.Tag.Lookup(brickTag); !ok {
+			panic(fmt.Errorf("field %s in %v is not a brick component", field, structType))
+		}
+		manualFields[field] = typeField
+	}
+
+	brickManager.brickConfigCheckOnce.Do(brickManager.checkConfig)
+	ctx := getBrickInstanceCtx{
+		buildingBrick: make(map[reflect.Type]bool),
+		createUnknown: false,
+		noCache:       true,
+		manual:        make(map[string]bool, len(manual)),
+	}
+	for _, field := range manual {
+		ctx.manual[field] = true
+	}
+	brick := getBrickInstance(brickType, ctx, liveID...).Interface().(T)
+	structValue := reflect.ValueOf(brick)
+	for structValue.Kind() == reflect.Ptr {
+		structValue = structValue.Elem()
+	}
+	set := func(field string, dep Brick) error {
+		typeField, ok := manualFields[field]
+		if !ok {
+			return fmt.Errorf("field %s in %v is not a manual field", field, structType)
+		}
+		valueField, err := structValue.FieldByIndexErr(typeField.Index)
+		if err != nil {
+			return fmt.Errorf("field %s in %v can't be set: %w", field, structType, err)
+		}
+		if !valueField.CanSet() {
+			return fmt.Errorf("field %s in %v can't be set", field, structType)
+		}
+		depValue := reflect.ValueOf(dep)
+		switch {
+		case dep == nil:
+			valueField.Set(reflect.Zero(typeField.Type))
+		case depValue.Type().AssignableTo(typeField.Type):
+			valueField.Set(depValue)
+		default:
+			return fmt.Errorf("%T can't be assigned to field %s of type %v", dep, field, typeField.Type)
+		}
+		return nil
+	}
+	return brick, set
+}
+
+// GetUninjected constructs the brick with NewBrick from its config, but does NOT inject its dependencies
+// nor call BrickInit, for tooling that only reads the fields set from the config, e.g. a migration.
+//
+// The `brick` tagged fields of the returned instance are left as NewBrick set them, usually nil,
+// so calling a method using a dependency may panic. Never pass the instance to code expecting a brick from Get.
+// The instance is constructed every time and NOT cached, so it never replaces the injected instance of the liveID.
+func GetUninjected[T BrickNewer](liveID ...string) T {
+	brickManager.brickConfigCheckOnce.Do(brickManager.checkConfig)
+	ctx := getBrickInstanceCtx{
+		buildingBrick: make(map[reflect.Type]bool),
+		createUnknown: false,
+		noCache:       true,
+		uninjected:    true,
+	}
+	return getBrickInstance(reflect.TypeOf((*(new(T)))), ctx, liveID...).Interface().(T)
+}
+
 type getBrickInstanceCtx struct {
 	// Don't save the type of the dereferenced pointer, because if there is a circular dependency, it will save the same type twice, causing a panic.
 	buildingBrick map[reflect.Type]bool
 	createUnknown bool
+	// noCache builds a new instance of the requested brick without saving it, its dependencies are still cached.
+	noCache bool
+	// uninjected builds the requested brick without injecting its dependencies, with noCache.
+	uninjected bool
+	// overrides replaces the dependencies of the requested brick, indexed by field name or liveID.
+	overrides map[string]Brick
+	// manual are the names of the fields of the requested brick that are not injected, set by GetPartial.
+	manual map[string]bool
+	// buildCtx is the context of the caller, nil if the build is not started with a context.
+	buildCtx context.Context
+	// created records the instances constructed by the outermost build, to roll them back if it fails.
+	created *[]createdBrick
+	// deepClone clones every dependency of the brick being built, set by a `deepclone` tag for its subtree.
+	deepClone bool
+	// scope holds the request-scoped bricks, nil if the build is not started by GetInScope
+	// or if the brick being built is a global one.
+	scope *Scope
+}
+
+// createdBrick is an instance constructed by a build.
+type createdBrick struct {
+	liveID   string
+	instance reflect.Value
+	// published is true once the instance is saved in its manager, where other callers may already hold it.
+	published bool
+}
+
+// rollbackBuild is deferred by the outermost build. If the build panics, it closes the instances the build constructed
+// that are not published, e.g. the partially injected instance that failed or the prototypes injected into it,
+// implementing BrickCloser in reverse construction order, then re-panics.
+// The errors and the panics of these Close are joined to the panic of the build.
+func rollbackBuild(created *[]createdBrick) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	var errs []error
+	for i := len(*created) - 1; i >= 0; i-- {
+		if c := (*created)[i]; !c.published {
+			if err := closeCreated(c); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) == 0 {
+		panic(r)
+	}
+	err, ok := r.(error)
+	if !ok {
+		err = fmt.Errorf("%v", r)
+	}
+	panic(errors.Join(append([]error{err}, errs...)...))
+}
+
+// closeCreated closes the instance of a failed build if it implements BrickCloser, a panic of Close is returned as an error.
+func closeCreated(c createdBrick) (err error) {
+	closer, ok := asInterface[BrickCloser](c.instance)
+	if !ok {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to close brick(%s) of a failed build: panic: %v", c.liveID, r)
+		}
+	}()
+	if err := closer.Close(); err != nil {
+		return fmt.Errorf("failed to close brick(%s) of a failed build: %w", c.liveID, err)
+	}
+	return nil
 }
 
 // Interface type is not a brick type, but a brick can be injected into an interface type.
@@ -60,13 +363,13 @@ func getBrickInstance(brickType reflect.Type, ctx getBrickInstanceCtx, liveID ..
 				wrappedBrickType = wrappedBrickType.Elem()
 			}
 			if !ok {
-				panic(fmt.Errorf("this brick type is not registered: %s", brickType))
+				panic(fmt.Errorf("%w: %s", ErrTypeNotRegistered, brickType))
 			}
 		default:
 			typePtr := reflect.PointerTo(brickType)
 			_, ok = brickManager.getBrickTypeID(typePtr)
 			if !ok {
-				panic(fmt.Errorf("this brick type is not registered: %s", brickType))
+				panic(fmt.Errorf("%w: %s", ErrTypeNotRegistered, brickType))
 			}
 			ptrInstance := getBrickInstance(typePtr, ctx, liveID...)
 			return ptrInstance.Elem()
@@ -74,7 +377,7 @@ func getBrickInstance(brickType reflect.Type, ctx getBrickInstanceCtx, liveID ..
 	}
 	targetLiveID := ""
 	if len(liveID) > 0 && liveID[0] != "" {
-		targetLiveID = liveID[0]
+		targetLiveID = brickManager.resolveLiveID(liveID[0])
 	} else {
 		targetLiveID = typeID
 	}
@@ -85,24 +388,83 @@ func getBrickInstance(brickType reflect.Type, ctx getBrickInstanceCtx, liveID ..
 		}
 	}
 
-	brick, ok := brickManager.getBrickFromExist(targetLiveID)
-	if ok {
-		return convertInstance(brick, brickType)
+	// The instance and the config of the liveID may come from a parent manager, see SetParent.
+	owner := brickManager.ownerOf(targetLiveID)
+
+	if brickManager.IsDisabled(typeID) {
+		return brickManager.getDisabledFallback(typeID, brickType)
 	}
-	if !ctx.createUnknown && targetLiveID != typeID && !brickManager.getDeclaredLiveID(targetLiveID) {
-		panic(fmt.Sprintf("liveID(%s) is not explicitly declared in the configuration or tag, you can use GetOrCreate to create it", targetLiveID))
+	if config, ok := owner.getBrickConfig(targetLiveID); ok && config.Disabled {
+		panic(fmt.Errorf("%w: liveID(%s) is disabled in the configuration", ErrLiveDisabled, targetLiveID))
+	}
+
+	var dedup *dedupKey
+	if brickManager.isPrototype(typeID) {
+		ctx.noCache = true
+		if brickManager.configDedup.Load() && ctx.overrides == nil && ctx.manual == nil && !ctx.uninjected {
+			config, _ := owner.getBrickConfig(targetLiveID)
+			key := dedupKeyOf(typeID, config.Config)
+			if shared, ok := brickManager.getDedupInstance(key); ok {
+				return convertInstance(shared.instance, brickType, shared.liveID)
+			}
+			dedup = &key
+		}
+	}
+	scoped := brickManager.isScoped(typeID)
+	if scoped {
+		if ctx.scope == nil {
+			panic(fmt.Errorf("brick(%s) of liveID(%s) is request-scoped, it can only be got in a Scope by GetInScope", typeID, targetLiveID))
+		}
+		if instance, ok := ctx.scope.instance(targetLiveID); ok {
+			return convertInstance(instance, brickType, targetLiveID)
+		}
+		ctx.noCache = true
+	} else if !ctx.noCache {
+		// A global brick can't depend on the instances of a scope.
+		ctx.scope = nil
+	}
+	if !ctx.noCache {
+		brick, ok := owner.getBrickFromExist(targetLiveID)
+		if ok {
+			if built, ok := owner.runDeferredBuild(targetLiveID); ok {
+				brick = built
+			}
+			owner.checkMutation(targetLiveID, brick)
+			return convertInstance(brick, brickType, targetLiveID)
+		}
+	}
+	if !ctx.createUnknown && targetLiveID != typeID && !owner.getDeclaredLiveID(targetLiveID) {
+		panic(fmt.Errorf("%w: liveID(%s) is not explicitly declared in the configuration or tag, you can use GetOrCreate to create it", ErrUnknownLiveID, targetLiveID))
 	}
 
 	if ctx.buildingBrick[brickType] {
-		panic(fmt.Errorf("circular dependency detected, brickType: %v, liveID: %s", brickType, targetLiveID))
+		panic(fmt.Errorf("%w, brickType: %v, liveID: %s", ErrCircularDependency, brickType, targetLiveID))
 	}
 	ctx.buildingBrick[brickType] = true
 	defer func() {
 		ctx.buildingBrick[brickType] = false
 	}()
+	if ctx.created == nil {
+		ctx.created = new([]createdBrick)
+		defer rollbackBuild(ctx.created)
+	}
+	created := func(instance reflect.Value) int {
+		*ctx.created = append(*ctx.created, createdBrick{liveID: targetLiveID, instance: instance})
+		return len(*ctx.created) - 1
+	}
+	// A request-scoped instance is published in the scope by Scope.build.
+	published := func(i int) {
+		(*ctx.created)[i].published = true
+	}
 
-	v, _, _ := brickManager.buildingBrickGroup.Do(targetLiveID, func() (any, error) {
-		brickConfig, configExist := brickManager.getBrickConfig(targetLiveID)
+	build := func() (any, error) {
+		start, succeeded := time.Now(), false
+		brickManager.startBuild(targetLiveID)
+		defer func() {
+			brickManager.finishBuild(targetLiveID)
+			brickManager.recordBuild(typeID, time.Since(start), succeeded)
+		}()
+		brickConfig, configExist := owner.getBrickConfig(targetLiveID)
 		if configExist {
 			if brickConfig.LiveID != targetLiveID {
 				panic(fmt.Errorf("config liveID mismatch: ID(%s) != ID(%s)", brickConfig.LiveID, targetLiveID))
@@ -112,61 +474,190 @@ func getBrickInstance(brickType reflect.Type, ctx getBrickInstanceCtx, liveID ..
 			}
 		}
 		brickParser, parserExist := brickManager.getBrickFactory(typeID)
+		if ctxFactory, ok := brickManager.getBrickCtxFactory(typeID); ok {
+			buildCtx := ctx.buildCtx
+			if buildCtx == nil {
+				buildCtx = context.Background()
+			}
+			brickParser = func(config any) Brick {
+				brick, err := ctxFactory(buildCtx, config)
+				if err != nil {
+					panic(fmt.Errorf("failed to build brick(%s) of liveID(%s): %w", typeID, targetLiveID, err))
+				}
+				return brick
+			}
+		}
+		if liveFactory, ok := brickManager.getLiveFactory(targetLiveID); ok {
+			brickParser, parserExist = func(config any) Brick {
+				configBytes, err := marshalBrickConfig(config)
+				if err != nil {
+					panic(fmt.Errorf("brick config marshal error: %w", err))
+				}
+				brick := liveFactory(configBytes)
+				if brick == nil || brick.BrickTypeID() != typeID {
+					panic(fmt.Errorf("the factory of live(%s) returns %T, want a brick(%s)", targetLiveID, brick, typeID))
+				}
+				return brick
+			}, true
+		}
 		if !parserExist {
 			ret := createEmptyPtrInstance(brickType)
-			ret = injectBrick(ret, targetLiveID, ctx)
-			brickManager.saveBrickInstance(targetLiveID, ret)
-			return convertInstance(ret, brickType), nil
+			i := created(ret)
+			if !ctx.uninjected {
+				ret = injectBrick(ret, targetLiveID, ctx)
+				initBrick(ret, targetLiveID)
+			}
+			if !ctx.noCache {
+				owner.saveBrickInstance(targetLiveID, ret)
+			}
+			if !ctx.noCache || scoped {
+				published(i)
+			}
+			succeeded = true
+			return convertInstance(ret, brickType, targetLiveID), nil
 		}
-		t := brickParser(brickConfig.Config)
+		builtConfig, _ := marshalBrickConfig(brickConfig.Config)
+		t, degraded := brickManager.newBrick(typeID, targetLiveID, brickParser, brickConfig.Config)
 		ret := reflect.ValueOf(t)
+		if config, ok := t.(configBrick); ok {
+			ret = config.value
+		}
 
 		if !isSameBaseType(ret.Type(), brickType) {
 			panic(fmt.Errorf("brick(%s) %v NewBrick method return error type: %v", typeID, brickType, ret.Type()))
 		}
 		//todo: test for interface type
-		if ret.Type().Kind() == reflect.Ptr {
+		if ret.Type().Kind() != reflect.Ptr {
+			ret = wrapPointerLayer(ret)
+		}
+		i := created(ret)
+		if !ctx.uninjected {
 			ret = injectBrick(ret, targetLiveID, ctx)
-		} else {
-			ret = injectBrick(wrapPointerLayer(ret), targetLiveID, ctx)
+			initBrick(ret, targetLiveID)
 		}
 
 		// fmt.Println("injectBrick ret", ret)
-		brickManager.saveBrickInstance(targetLiveID, ret)
-		return convertInstance(ret, brickType), nil
+		if !ctx.noCache {
+			owner.saveBrickInstance(targetLiveID, ret)
+			owner.setBuiltConfig(targetLiveID, builtConfig)
+			owner.setDegraded(targetLiveID, degraded)
+		}
+		if !ctx.noCache || scoped {
+			published(i)
+		}
+		succeeded = true
+		return convertInstance(ret, brickType, targetLiveID), nil
+	}
+	if scoped {
+		return convertInstance(ctx.scope.build(targetLiveID, build), brickType, targetLiveID)
+	}
+	if ctx.noCache {
+		v, _ := build()
+		if dedup != nil {
+			shared := brickManager.storeDedupInstance(*dedup, dedupInstance{liveID: targetLiveID, instance: v.(reflect.Value)})
+			return convertInstance(shared.instance, brickType, shared.liveID)
+		}
+		return v.(reflect.Value)
+	}
+	// The panic of the build is recovered in the group with its stack, and re-panicked in every caller.
+	v, err, _ := brickManager.buildingBrickGroup.Do(targetLiveID, func() (v any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = newBuildPanic(r)
+			}
+		}()
+		return build()
 	})
+	if p, ok := err.(*buildPanic); ok {
+		panic(p)
+	}
 	return v.(reflect.Value)
 }
-func convertInstance(instance reflect.Value, targetType reflect.Type) reflect.Value {
+
+// buildPanic is the value of a panic recovered from a build, with the stack of the goroutine that panicked.
+// It unwraps to the value if the value is an error, so errors.Is still matches the sentinels.
+type buildPanic struct {
+	value any
+	stack []byte
+}
+
+// newBuildPanic returns the buildPanic of the recovered value, a panic re-panicked by a nested build keeps its first stack.
+func newBuildPanic(r any) *buildPanic {
+	if p, ok := r.(*buildPanic); ok {
+		return p
+	}
+	return &buildPanic{value: r, stack: debug.Stack()}
+}
+
+func (p *buildPanic) Error() string {
+	return fmt.Sprintf("brick build panic: %v\n\n%s", p.value, p.stack)
+}
+
+func (p *buildPanic) Unwrap() error {
+	err, _ := p.value.(error)
+	return err
+}
+
+// initBrick calls BrickInit if the injected instance implements BrickIniter, and panics if it fails.
+func initBrick(instance reflect.Value, liveID string) {
+	if initer, ok := asInterface[BrickIniter](instance); ok {
+		if err := initer.BrickInit(); err != nil {
+			panic(fmt.Errorf("failed to init brick of liveID(%s): %w", liveID, err))
+		}
+	}
+}
+
+// convertInstance converts the stored instance of liveID to the target type by adding or removing pointer layers.
+// It panics with a clear message if the conversion is not achievable.
+func convertInstance(instance reflect.Value, targetType reflect.Type, liveID string) reflect.Value {
 	// fmt.Println("convertInstance", instance.Type(), targetType)
 	if instance.Type() == targetType {
 		return instance
 	}
 	retLevel := getPointerLevel(instance.Type())
+	cannotConvert := func(reason string) {
+		panic(fmt.Errorf("cannot convert stored instance %v (level %d) to field type %v (level %d) for liveID(%s): %s",
+			instance.Type(), retLevel, targetType, getPointerLevel(targetType), liveID, reason))
+	}
+	// elem removes a pointer layer, a nil pointer can't be dereferenced.
+	elem := func(v reflect.Value) reflect.Value {
+		if v.IsNil() {
+			cannotConvert("nil pointer layer")
+		}
+		return v.Elem()
+	}
 	if targetType.Kind() == reflect.Interface {
+		converted := instance
 		if retLevel > 1 {
 			for i := 0; i < retLevel-1; i++ {
-				instance = instance.Elem()
+				converted = elem(converted)
 			}
 		}
-		if retLevel == 0 && !instance.Type().Implements(targetType) {
-			instance = wrapPointerLayer(instance)
+		if retLevel == 0 && !converted.Type().Implements(targetType) {
+			converted = wrapPointerLayer(converted)
 		}
-		return instance
+		if !converted.Type().Implements(targetType) {
+			cannotConvert("interface not implemented")
+		}
+		return converted
+	}
+	if !isSameBaseType(instance.Type(), targetType) {
+		cannotConvert("different base types")
 	}
 	brickTypeLevel := getPointerLevel(targetType)
+	converted := instance
 	if retLevel > brickTypeLevel {
 		for i := 0; i < retLevel-brickTypeLevel; i++ {
-			instance = instance.Elem()
+			converted = elem(converted)
 		}
-		return instance
+		return converted
 	}
 	if retLevel < brickTypeLevel {
 		for i := 0; i < brickTypeLevel-retLevel; i++ {
-			instance = wrapPointerLayer(instance)
+			converted = wrapPointerLayer(converted)
 		}
 	}
-	return instance
+	return converted
 }
 
 // injectBrick injects dependencies into a brick instance by looking for fields with the `brick` tag.
@@ -180,8 +671,16 @@ func injectBrick(brick reflect.Value, brickLiveID string, ctx getBrickInstanceCt
 		return brick
 	}
 	rfType := rfValue.Type()
+	// The overrides, manual fields and noCache only apply to the requested brick, not to its dependencies.
+	overrides, manual := ctx.overrides, ctx.manual
+	ctx.overrides = nil
+	ctx.manual = nil
+	ctx.noCache = false
 	var brickLive *Live
-	lives, ok := getBrickLives(rfType)
+	// The indexes and tags of the `method:Name` fields.
+	var methodFields []int
+	var methodTags []string
+	lives, ok := brickManager.getBrickLives(rfType)
 	if ok {
 		for _, live := range lives {
 			if live.LiveID == brickLiveID {
@@ -213,72 +712,232 @@ func injectBrick(brick reflect.Value, brickLiveID string, ctx getBrickInstanceCt
 			continue
 		}
 		if tag, ok := typeField.Tag.Lookup(brickTag); ok {
-			typ := valueField.Type()
+			if manual[typeField.Name] {
+				// Supplied by the caller of GetPartial.
+				continue
+			}
 			if brickLive != nil {
 				if tag2, ok := brickLive.RelyLives[typeField.Name]; ok {
 					tag = tag2
 				}
 			}
-			if typ.Kind() == reflect.Interface {
-				injectInterfaceBrick(valueField, tag, ctx)
+			if liveID, _, _, _ := brickManager.parseTag(tag); strings.HasPrefix(liveID, methodTagPrefix) {
+				// The method sees the other fields, it is called once they are injected.
+				methodFields = append(methodFields, i)
+				methodTags = append(methodTags, tag)
 				continue
 			}
-			var newCtx = ctx
-			liveID, _, isClone, isRandomLiveID := brickManager.parseTag(tag)
-			if isRandomLiveID {
-				liveID = RandomLiveID()
-				newCtx.createUnknown = true
-			}
-			if isClone {
-				if liveID == "" {
-					liveID, ok = brickManager.getBrickTypeID(typ)
-					if !ok {
-						panic(fmt.Errorf("unexpect error, brick type(%s) not found", typ))
-					}
+			if ctx.buildCtx != nil {
+				if err := ctx.buildCtx.Err(); err != nil {
+					panic(fmt.Errorf("build brick(%s) canceled before field %s: %w", brickLiveID, typeField.Name, err))
 				}
-				valueField.Set(cloneBrick2(typ, liveID))
-			} else {
-				valueField.Set(getBrickInstance(typ, newCtx, liveID))
 			}
+			injectField(rfValue, typeField, valueField, tag, overrides, ctx)
+			runTagModifiers(InjectContext{LiveID: brickLiveID, Brick: rfValue, Field: typeField, Tag: tag}, valueField)
+		}
+	}
+	for j, i := range methodFields {
+		typeField := rfType.Field(i)
+		valueField := rfValue.Field(i)
+		tag := methodTags[j]
+		if dep, ok := lookupOverride(overrides, typeField.Name, tag, valueField.Type()); ok {
+			injectOverride(valueField, typeField.Name, dep)
+		} else {
+			injectMethodValue(rfValue, typeField, valueField, tag)
 		}
+		runTagModifiers(InjectContext{LiveID: brickLiveID, Brick: rfValue, Field: typeField, Tag: tag}, valueField)
 	}
 
 	return brick
 }
 
+// injectMethodValue sets the field to the result of the method of its `method:Name` tag, called on the struct value.
+func injectMethodValue(rfValue reflect.Value, typeField reflect.StructField, valueField reflect.Value, tag string) {
+	liveID, _, _, _ := brickManager.parseTag(tag)
+	name, _ := methodTagName(liveID)
+	receiver := rfValue
+	if receiver.CanAddr() {
+		receiver = receiver.Addr()
+	}
+	method := receiver.MethodByName(name)
+	if !method.IsValid() {
+		panic(fmt.Errorf("the method %s of field %s in %s is not found", name, typeField.Name, rfValue.Type()))
+	}
+	if err := checkFieldMethod(method.Type(), typeField); err != nil {
+		panic(fmt.Errorf("the method %s of field %s in %s %w", name, typeField.Name, rfValue.Type(), err))
+	}
+	valueField.Set(method.Call(nil)[0])
+}
+
+// checkFieldMethod checks that the method type, without its receiver, takes no argument and returns the field type.
+func checkFieldMethod(methodType reflect.Type, typeField reflect.StructField) error {
+	if methodType.NumIn() != 0 {
+		return fmt.Errorf("must take no argument")
+	}
+	if methodType.NumOut() != 1 || !methodType.Out(0).AssignableTo(typeField.Type) {
+		return fmt.Errorf("must return %s", typeField.Type)
+	}
+	return nil
+}
+
+// injectField injects the dependency of a `brick` tagged field of the struct value.
+func injectField(rfValue reflect.Value, typeField reflect.StructField, valueField reflect.Value, tag string, overrides map[string]Brick, ctx getBrickInstanceCtx) {
+	typ := valueField.Type()
+	if dep, ok := lookupOverride(overrides, typeField.Name, tag, typ); ok {
+		injectOverride(valueField, typeField.Name, dep)
+		return
+	}
+	if _, ok := lazyElemType(typ); ok && valueField.CanAddr() {
+		injectLazyBrick(rfValue, typeField, valueField, tag, ctx)
+		return
+	}
+	tag = resolveFromTag(rfValue, typeField.Name, tag)
+	if isDeepCloneTag(tag) {
+		ctx.deepClone = true
+	}
+	tag, optional := splitOptionalTag(tag)
+	if optional {
+		if err := checkOptionalTag(tag); err != nil {
+			panic(fmt.Errorf("field %s in %s: %w", typeField.Name, rfValue.Type(), err))
+		}
+		// The dependency is built as an outermost build, so that its failed build is rolled back
+		// before the field is left zero.
+		ctx.created = nil
+		defer recoverMissingDependency(valueField)
+	}
+	if liveID, tagTypeID, _, _ := brickManager.parseTag(tag); strings.HasPrefix(liveID, secretTagPrefix) {
+		injectSecret(valueField, strings.TrimPrefix(liveID, secretTagPrefix), tagTypeID == refreshTag)
+		return
+	} else if tagTypeID == providerTag {
+		injectProviderValue(valueField, liveID)
+		return
+	} else if tagTypeID == deferredTag {
+		injectDeferredBrick(valueField, liveID)
+		return
+	} else if tagTypeID == configsTag {
+		injectConfigsValue(valueField, liveID)
+		return
+	} else if tagTypeID == liveIDsTag {
+		injectLiveIDsValue(valueField, liveID, hasTagOption(tag, noDefaultTag))
+		return
+	} else if tagTypeID == selectedTag {
+		injectSelectedBrick(valueField, ctx)
+		return
+	}
+	if typ.Kind() == reflect.Slice {
+		injectSliceBrick(valueField, tag, ctx, optional)
+		return
+	}
+	if typ.Kind() == reflect.Map {
+		injectMapBrick(valueField, tag, ctx)
+		return
+	}
+	if typ.Kind() == reflect.Interface {
+		injectInterfaceBrick(valueField, tag, ctx)
+		return
+	}
+	var newCtx = ctx
+	liveID, tagTypeID, isClone, isRandomLiveID := brickManager.parseTag(tag)
+	isClone = isClone || ctx.deepClone
+	if tagTypeID == weightedTag {
+		if liveID == "" {
+			liveID = brickManager.getTypeIDByReflectType(typ)
+		}
+		liveID = brickManager.pickWeightedLiveID(liveID)
+	}
+	if isRandomLiveID {
+		liveID = RandomLiveID()
+		newCtx.createUnknown = true
+	}
+	if tagTypeID != "" && !isTagOption(tagTypeID) && !isBrickType(typ) {
+		injectWrapperBrick(valueField, tagTypeID, liveID, newCtx)
+		return
+	}
+	if isClone {
+		if liveID == "" {
+			var ok bool
+			liveID, ok = brickManager.getBrickTypeID(typ)
+			if !ok {
+				panic(fmt.Errorf("unexpect error, brick type(%s) not found", typ))
+			}
+		}
+		valueField.Set(cloneDependency(typ, liveID, newCtx))
+	} else {
+		valueField.Set(getBrickInstance(typ, newCtx, liveID))
+	}
+}
+
+// resolveFromTag replaces the `from:Field` liveID of the tag with the value of the string field,
+// which is set by NewBrick before the dependencies are injected, e.g. `brick:"from:Driver"`.
+func resolveFromTag(structValue reflect.Value, fieldName string, tag string) string {
+	liveID, rest, _ := strings.Cut(tag, ",")
+	sourceName, ok := fromTagField(liveID)
+	if !ok {
+		return tag
+	}
+	source := structValue.FieldByName(sourceName)
+	if !source.IsValid() || source.Kind() != reflect.String {
+		panic(fmt.Errorf("the source field %s of field %s in %s must be a string field", sourceName, fieldName, structValue.Type()))
+	}
+	if source.String() == "" {
+		panic(fmt.Errorf("the source field %s of field %s in %s is empty", sourceName, fieldName, structValue.Type()))
+	}
+	if rest != "" {
+		return source.String() + "," + rest
+	}
+	return source.String()
+}
+
 // `brick:"liveID,typeID"`
 func injectInterfaceBrick(valueField reflect.Value, tag string, ctx getBrickInstanceCtx) {
 	liveID, typeID, cloneBrick, isRandomLiveID := brickManager.parseTag(tag)
 	if isRandomLiveID {
 		panic(fmt.Errorf("interface type brick(%s) cannot use random liveID", valueField.Type()))
 	}
+	cloneBrick = cloneBrick || ctx.deepClone
+	if typeID == rewireableTag {
+		typeID = ""
+	}
+	if typeID == weightedTag {
+		if liveID == "" {
+			panic(fmt.Errorf("interface type brick(%s) must give a typeID on tag to pick a weighted live", valueField.Type()))
+		}
+		typeID = liveID
+		liveID = brickManager.pickWeightedLiveID(typeID)
+	}
 	if liveID == "" {
 		if typeID == "" {
-			panic(fmt.Errorf("interface type brick(%s) must give a liveID on tag", valueField.Type()))
+			typeID, _ = brickManager.getImplBinding(valueField.Type())
+		}
+		if typeID == "" {
+			panic(fmt.Errorf("%w: interface type brick(%s) must give a liveID on tag", ErrDependencyNotFound, valueField.Type()))
 		}
 		liveID = typeID
 	}
-	brick, ok := brickManager.getBrickFromExist(liveID)
+	liveID = brickManager.resolveLiveID(liveID)
+	owner := brickManager.ownerOf(liveID)
+	brick, ok := owner.getBrickFromExist(liveID)
 	if ok {
+		// The existing instance is got like any other, so that a disabled type or a pending deferred build is honoured.
 		if cloneBrick {
-			valueField.Set(cloneBrick2(brick.Type(), liveID))
+			valueField.Set(cloneDependency(brick.Type(), liveID, ctx))
 		} else {
 			// fmt.Println("convertInstance", brick.Type(), valueField.Type())
-			valueField.Set(convertInstance(brick, valueField.Type()))
+			valueField.Set(convertInstance(getBrickInstance(brick.Type(), ctx, liveID), valueField.Type(), liveID))
 		}
 		return
 	}
-	brickconf, ok := brickManager.getBrickConfig(liveID)
+	brickconf, ok := owner.getBrickConfig(liveID)
 	if ok {
 		if typeID != "" && brickconf.TypeID != typeID {
 			panic(fmt.Errorf("the interface brick(%v) TypeID mismatch: config(%s) != tag(%s)", valueField.Type(), brickconf.TypeID, typeID))
 		}
 		typ, ok := brickManager.getBrickType(brickconf.TypeID)
 		if !ok {
-			panic(fmt.Errorf("the interface brick(%v) dependency not found, typeID(%s)", valueField.Type(), brickconf.TypeID))
+			panic(fmt.Errorf("%w: the interface brick(%v) of typeID(%s)", ErrDependencyNotFound, valueField.Type(), brickconf.TypeID))
 		}
 		if cloneBrick {
-			valueField.Set(cloneBrick2(typ, liveID))
+			valueField.Set(cloneDependency(typ, liveID, ctx))
 		} else {
 			valueField.Set(getBrickInstance(typ, ctx, liveID))
 		}
@@ -288,10 +947,10 @@ func injectInterfaceBrick(valueField reflect.Value, tag string, ctx getBrickInst
 	if typeID != "" {
 		typ, ok := brickManager.getBrickType(typeID)
 		if !ok {
-			panic(fmt.Errorf("the interface brick(%v) dependency not found, typeID(%s)", valueField.Type(), typeID))
+			panic(fmt.Errorf("%w: the interface brick(%v) of typeID(%s)", ErrDependencyNotFound, valueField.Type(), typeID))
 		}
 		if cloneBrick {
-			valueField.Set(cloneBrick2(typ, liveID))
+			valueField.Set(cloneDependency(typ, liveID, ctx))
 		} else {
 			valueField.Set(getBrickInstance(typ, ctx, liveID))
 		}
@@ -309,16 +968,321 @@ func injectInterfaceBrick(valueField reflect.Value, tag string, ctx getBrickInst
 	// 	// you can clearly use `brick:",typeID"` to represent the default instance of the type
 	// 	typ, ok = brickManager.getBrickType(liveID)
 	// }
+	if !ok {
+		// The brick bound to the interface by RegisterImpl.
+		if boundTypeID, bound := brickManager.getImplBinding(valueField.Type()); bound {
+			typ, ok = brickManager.getBrickType(boundTypeID)
+		}
+	}
 	if ok {
 		if cloneBrick {
-			valueField.Set(cloneBrick2(typ, liveID))
+			valueField.Set(cloneDependency(typ, liveID, ctx))
 		} else {
 			valueField.Set(getBrickInstance(typ, ctx, liveID))
 		}
 		return
 	}
 
-	panic(fmt.Errorf("the interface brick(%v) dependency not found, can't determine the type of liveID(%s)", valueField.Type(), liveID))
+	panic(fmt.Errorf("%w: the interface brick(%v) can't determine the type of liveID(%s)", ErrDependencyNotFound, valueField.Type(), liveID))
+}
+
+// injectWrapperBrick injects the brick of typeID into a field whose type wraps the brick type,
+// e.g. a field of `type MyLogger Logger` tagged with `brick:",Logger"`.
+// The instance is converted to the field type, so a pointer field shares the instance of the liveID.
+func injectWrapperBrick(valueField reflect.Value, typeID string, liveID string, ctx getBrickInstanceCtx) {
+	typ := valueField.Type()
+	brickType, ok := brickManager.getBrickType(typeID)
+	if !ok {
+		panic(fmt.Errorf("%w: the brick(%s) of field type %v", ErrTypeNotRegistered, typeID, typ))
+	}
+	for brickType.Kind() == reflect.Ptr {
+		brickType = brickType.Elem()
+	}
+	for i := getPointerLevel(typ); i > 0; i-- {
+		brickType = reflect.PointerTo(brickType)
+	}
+	instance := getBrickInstance(brickType, ctx, liveID)
+	if !instance.Type().ConvertibleTo(typ) {
+		panic(fmt.Errorf("the brick(%s) of type %v can't be converted to field type %v", typeID, instance.Type(), typ))
+	}
+	valueField.Set(instance.Convert(typ))
+}
+
+// lookupOverride looks up the override of a field by its name, then by the liveID of the dependency.
+func lookupOverride(overrides map[string]Brick, fieldName string, tag string, fieldType reflect.Type) (Brick, bool) {
+	if len(overrides) == 0 {
+		return nil, false
+	}
+	if dep, ok := overrides[fieldName]; ok {
+		return dep, true
+	}
+	liveID, _, _, _ := brickManager.parseTag(tag)
+	if liveID == "" {
+		liveID, _ = brickManager.getBrickTypeID(fieldType)
+	}
+	if liveID == "" {
+		return nil, false
+	}
+	dep, ok := overrides[brickManager.resolveLiveID(liveID)]
+	return dep, ok
+}
+
+func injectOverride(valueField reflect.Value, fieldName string, dep Brick) {
+	valueField.Set(convertInstance(reflect.ValueOf(dep), valueField.Type(), fieldName))
+}
+
+// `brick:"liveID,provider"`
+func injectProviderValue(valueField reflect.Value, liveID string) {
+	brickManager.providersLock.RLock()
+	provider, ok := brickManager.providers[liveID]
+	brickManager.providersLock.RUnlock()
+	if !ok {
+		panic(fmt.Errorf("provider(%s) is not registered", liveID))
+	}
+	value := provider()
+	if !value.IsValid() {
+		return
+	}
+	if !value.Type().AssignableTo(valueField.Type()) {
+		panic(fmt.Errorf("provider(%s) returns %v, which is not assignable to %v", liveID, value.Type(), valueField.Type()))
+	}
+	valueField.Set(value)
+}
+
+// `brick:"typeID,configs"`
+func injectConfigsValue(valueField reflect.Value, typeID string) {
+	if valueField.Kind() != reflect.Slice {
+		panic(fmt.Errorf("configs of brick(%s) can only be injected into a slice, got %v", typeID, valueField.Type()))
+	}
+	configs := brickManager.getEnabledBrickConfigsByTypeID(typeID)
+	elemType := valueField.Type().Elem()
+	slice := reflect.MakeSlice(valueField.Type(), 0, len(configs))
+	for _, config := range configs {
+		configBytes, err := marshalBrickConfig(config.Config)
+		if err != nil {
+			panic(fmt.Errorf("failed to marshal config of live(%s): %w", config.LiveID, err))
+		}
+		elem := reflect.New(elemType)
+		if configBytes != nil {
+			if err := json.Unmarshal(configBytes, elem.Interface()); err != nil {
+				panic(fmt.Errorf("failed to parse config of live(%s) into %v: %w", config.LiveID, elemType, err))
+			}
+		}
+		slice = reflect.Append(slice, elem.Elem())
+	}
+	valueField.Set(slice)
+}
+
+// injectLiveIDsValue injects the sorted liveIDs of the enabled configured lives of the type into a string slice,
+// without building them, e.g. for a brick building each tenant on demand. noDefault excludes the default live.
+func injectLiveIDsValue(valueField reflect.Value, typeID string, noDefault bool) {
+	typ := valueField.Type()
+	if typ.Kind() != reflect.Slice || typ.Elem().Kind() != reflect.String {
+		panic(fmt.Errorf("liveIDs of brick(%s) can only be injected into a string slice, got %v", typeID, typ))
+	}
+	if _, ok := brickManager.getBrickType(typeID); !ok {
+		panic(fmt.Errorf("%w: the brick(%s) of the liveIDs", ErrTypeNotRegistered, typeID))
+	}
+	configs := brickManager.getEnabledBrickConfigsByTypeID(typeID)
+	slice := reflect.MakeSlice(typ, 0, len(configs))
+	for _, config := range configs {
+		if noDefault && config.LiveID == typeID {
+			continue
+		}
+		slice = reflect.Append(slice, reflect.ValueOf(config.LiveID).Convert(typ.Elem()))
+	}
+	valueField.Set(slice)
+}
+
+// hasTagOption reports whether an option follows the typeID component of the tag, e.g. `brick:"typeID,liveids,nodefault"`.
+func hasTagOption(tag string, option string) bool {
+	tag, _ = splitTagModifiers(tag)
+	components := strings.Split(tag, ",")
+	if len(components) < 3 {
+		return false
+	}
+	return slices.Contains(components[2:], option)
+}
+
+// pickWeightedLiveID picks one configured live of the brick type at random, weighted by the weight of each live.
+// A live without weight has the weight 1, a weight of 0 is rejected when the config is checked.
+func (b *BrickManager) pickWeightedLiveID(typeID string) string {
+	configs := b.getEnabledBrickConfigsByTypeID(typeID)
+	if len(configs) == 0 {
+		panic(fmt.Errorf("brick(%s) has no configured lives to pick a weighted live from", typeID))
+	}
+	total := 0
+	for _, config := range configs {
+		total += liveWeight(config)
+	}
+	n := rand.IntN(total)
+	for _, config := range configs {
+		n -= liveWeight(config)
+		if n < 0 {
+			return config.LiveID
+		}
+	}
+	return configs[len(configs)-1].LiveID
+}
+
+func liveWeight(config BrickConfig) int {
+	if config.Weight == 0 {
+		return 1
+	}
+	return config.Weight
+}
+
+// `brick:"liveID1;liveID2;liveID3"`
+//
+// The slice is filled with the bricks of the given liveIDs in the order they are listed.
+// If optional is true, the missing bricks are skipped.
+func injectSliceBrick(valueField reflect.Value, tag string, ctx getBrickInstanceCtx, optional bool) {
+	liveIDs, typeID, isClone, isRandomLiveID := brickManager.parseTag(tag)
+	if isRandomLiveID {
+		panic(fmt.Errorf("slice type brick(%s) cannot use random liveID", valueField.Type()))
+	}
+	if liveIDs == groupTag {
+		injectGroupBrick(valueField, typeID == nonemptyTag, ctx)
+		return
+	}
+	if liveIDs == allTag {
+		injectMemberBricks(valueField, brickManager.getAllMembers(valueField.Type().Elem()), ctx)
+		return
+	}
+	ids := splitLiveIDList(liveIDs)
+	if len(ids) == 0 {
+		panic(fmt.Errorf("slice type brick(%s) must give a liveID list on tag", valueField.Type()))
+	}
+	elemType := valueField.Type().Elem()
+	slice := reflect.MakeSlice(valueField.Type(), 0, len(ids))
+	for _, liveID := range ids {
+		elem := reflect.New(elemType).Elem()
+		resolve := func() {
+			switch {
+			case elemType.Kind() == reflect.Interface:
+				elemTag := liveID + "," + typeID
+				if isClone {
+					elemTag = "clone:" + elemTag
+				}
+				injectInterfaceBrick(elem, elemTag, ctx)
+			case isClone || ctx.deepClone:
+				elem.Set(cloneDependency(elemType, liveID, ctx))
+			default:
+				elem.Set(getBrickInstance(elemType, ctx, liveID))
+			}
+		}
+		if !optional {
+			resolve()
+		} else if !resolveOptional(resolve) {
+			continue
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	valueField.Set(slice)
+}
+
+// `brick:"group"` or `brick:"group,nonempty"`
+//
+// The slice is filled with every live of the element type sorted by order then liveID,
+// or every live of each registered type implementing the element interface.
+func injectGroupBrick(valueField reflect.Value, nonempty bool, ctx getBrickInstanceCtx) {
+	elemType := valueField.Type().Elem()
+	members := brickManager.getGroupMembers(elemType)
+	if nonempty && len(members) == 0 {
+		panic(fmt.Errorf("group brick(%s) requires at least one member, but none was resolved", elemType))
+	}
+	injectMemberBricks(valueField, members, ctx)
+}
+
+// `brick:"all"` on a map[string]T field
+//
+// The map is filled with every configured live of the element type, keyed by liveID.
+// Like any map, its iteration order is undefined.
+func injectMapBrick(valueField reflect.Value, tag string, ctx getBrickInstanceCtx) {
+	typ := valueField.Type()
+	if liveID, _, _, _ := brickManager.parseTag(tag); liveID != allTag || typ.Key().Kind() != reflect.String {
+		panic(fmt.Errorf("map type brick(%s) must be a map keyed by string with the tag `brick:\"all\"`", typ))
+	}
+	elemType := typ.Elem()
+	members := brickManager.getAllMembers(elemType)
+	m := reflect.MakeMapWithSize(typ, len(members))
+	for _, member := range members {
+		elem := convertInstance(getBrickInstance(member.typ, ctx, member.liveID), elemType, member.liveID)
+		m.SetMapIndex(reflect.ValueOf(member.liveID).Convert(typ.Key()), elem)
+	}
+	valueField.Set(m)
+}
+
+// injectMemberBricks fills the slice with the instances of the members.
+func injectMemberBricks(valueField reflect.Value, members []groupMember, ctx getBrickInstanceCtx) {
+	elemType := valueField.Type().Elem()
+	slice := reflect.MakeSlice(valueField.Type(), 0, len(members))
+	for _, member := range members {
+		elem := reflect.New(elemType).Elem()
+		elem.Set(convertInstance(getBrickInstance(member.typ, ctx, member.liveID), elemType, member.liveID))
+		slice = reflect.Append(slice, elem)
+	}
+	valueField.Set(slice)
+}
+
+type groupMember struct {
+	typ    reflect.Type
+	liveID string
+	order  int
+}
+
+// getGroupMembers returns the lives of the element type, sorted by their configured order then liveID.
+// The lives of a type are its configured lives, or its default live if it has no configured live.
+// Disabled types are skipped.
+func (b *BrickManager) getGroupMembers(elemType reflect.Type) []groupMember {
+	members := b.typeMembers(elemType, true)
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].order != members[j].order {
+			return members[i].order < members[j].order
+		}
+		return members[i].liveID < members[j].liveID
+	})
+	return members
+}
+
+// getAllMembers returns the configured lives of the element type, sorted by liveID.
+// Disabled types are skipped.
+func (b *BrickManager) getAllMembers(elemType reflect.Type) []groupMember {
+	members := b.typeMembers(elemType, false)
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].liveID < members[j].liveID
+	})
+	return members
+}
+
+// typeMembers returns the configured lives of the element type, or of each registered type implementing the element interface.
+// If withDefault is true, the default live of a type without configured live is returned instead.
+func (b *BrickManager) typeMembers(elemType reflect.Type, withDefault bool) []groupMember {
+	var members []groupMember
+	addLives := func(typ reflect.Type, typeID string) {
+		if b.IsDisabled(typeID) {
+			return
+		}
+		configs := b.getBrickConfigsByTypeID(typeID)
+		if len(configs) == 0 && withDefault {
+			members = append(members, groupMember{typ: typ, liveID: typeID})
+			return
+		}
+		for _, config := range configs {
+			if config.Disabled {
+				continue
+			}
+			members = append(members, groupMember{typ: typ, liveID: config.LiveID, order: config.Order})
+		}
+	}
+	if elemType.Kind() == reflect.Interface {
+		for typeID, typ := range b.implementors(elemType) {
+			addLives(typ, typeID)
+		}
+	} else {
+		addLives(elemType, b.getTypeIDByReflectType(elemType))
+	}
+	return members
 }
 
 func CloneConfig[T Brick](liveID ...string) (newLiveID string) {
@@ -333,22 +1297,56 @@ func CloneConfig[T Brick](liveID ...string) (newLiveID string) {
 	if !ok {
 		panic(fmt.Errorf("liveID(%s) does not have a configuration", cloneId))
 	}
+	brickConfig.filePath = ""
+	brickConfig.clone = true
 	brickManager.setBrickConfig(newLiveID, brickConfig)
 	brickManager.setDeclaredLiveID(newLiveID)
 	return newLiveID
 }
 
 func cloneBrick(brickType reflect.Type, liveID string) (newBrick reflect.Value, newLiveID string) {
-	newLiveID = RandomLiveID()
-	brickConfig, ok := brickManager.getBrickConfig(liveID)
-	if ok {
-		brickManager.setBrickConfig(newLiveID, brickConfig)
+	var key dedupKey
+	if brickManager.configDedup.Load() {
+		config, _ := brickManager.getBrickConfig(brickManager.resolveLiveID(liveID))
+		key = dedupKeyOf(brickManager.getTypeIDByReflectType(brickType), config.Config)
+		if shared, ok := brickManager.getDedupInstance(key); ok {
+			return convertInstance(shared.instance, brickType, shared.liveID), shared.liveID
+		}
 	}
+	newLiveID = cloneBrickConfig(liveID)
 	ctx := getBrickInstanceCtx{
 		buildingBrick: make(map[reflect.Type]bool),
 		createUnknown: true,
 	}
-	return getBrickInstance(brickType, ctx, newLiveID), newLiveID
+	newBrick = getBrickInstance(brickType, ctx, newLiveID)
+	if brickManager.configDedup.Load() {
+		shared := brickManager.storeDedupInstance(key, dedupInstance{liveID: newLiveID, instance: newBrick})
+		return convertInstance(shared.instance, brickType, shared.liveID), shared.liveID
+	}
+	return newBrick, newLiveID
+}
+
+// cloneBrickConfig copies the config of the liveID to a new random liveID, and returns the new liveID.
+func cloneBrickConfig(liveID string) string {
+	liveID = brickManager.resolveLiveID(liveID)
+	newLiveID := RandomLiveID()
+	brickConfig, ok := brickManager.getBrickConfig(liveID)
+	if ok {
+		brickConfig.filePath = ""
+		brickConfig.clone = true
+		brickManager.setBrickConfig(newLiveID, brickConfig)
+	}
+	return newLiveID
+}
+
+// cloneDependency clones the brick of the liveID injected into a field. If ctx.deepClone is set,
+// the dependencies of the clone are cloned too, recursively, so the clone shares no brick with the original.
+func cloneDependency(brickType reflect.Type, liveID string, ctx getBrickInstanceCtx) reflect.Value {
+	if !ctx.deepClone {
+		return cloneBrick2(brickType, liveID)
+	}
+	ctx.createUnknown = true
+	return getBrickInstance(brickType, ctx, cloneBrickConfig(liveID))
 }
 
 func cloneBrick2(brickType reflect.Type, liveID string) reflect.Value {
@@ -0,0 +1,52 @@
+package brick
+
+import (
+	"crypto/sha256"
+	"reflect"
+)
+
+// SetConfigDedup sets whether the clones (`clone:` tags) and the prototypes (see RegisterPrototype)
+// share the instances built with the same effective config, instead of building a new one each time.
+// An instance is shared by the builds of the same typeID whose config, with its placeholders replaced,
+// marshals to the same json. This trades isolation for performance: the shared instances are mutated by every
+// holder, only enable it for bricks that are not mutated after their construction. It is disabled by default,
+// the deep clones (`deepclone:` tags) and the builds of GetWith are never shared.
+func SetConfigDedup(enabled bool) {
+	brickManager.configDedup.Store(enabled)
+}
+
+// dedupKey identifies the instances sharing the same effective config.
+type dedupKey struct {
+	typeID     string
+	configHash [sha256.Size]byte
+}
+
+// dedupInstance is an instance shared by the builds with the same dedupKey, liveID is the liveID it was built with.
+type dedupInstance struct {
+	liveID   string
+	instance reflect.Value
+}
+
+// dedupKeyOf returns the key of the instances of typeID built with config.
+func dedupKeyOf(typeID string, config any) dedupKey {
+	configBytes, _ := marshalBrickConfig(config)
+	return dedupKey{typeID: typeID, configHash: sha256.Sum256(configBytes)}
+}
+
+func (b *BrickManager) getDedupInstance(key dedupKey) (dedupInstance, bool) {
+	b.dedupInstancesLock.RLock()
+	defer b.dedupInstancesLock.RUnlock()
+	instance, ok := b.dedupInstances[key]
+	return instance, ok
+}
+
+// storeDedupInstance saves the instance of the key, and returns the instance already saved by a concurrent build if any.
+func (b *BrickManager) storeDedupInstance(key dedupKey, instance dedupInstance) dedupInstance {
+	b.dedupInstancesLock.Lock()
+	defer b.dedupInstancesLock.Unlock()
+	if old, ok := b.dedupInstances[key]; ok {
+		return old
+	}
+	b.dedupInstances[key] = instance
+	return instance
+}
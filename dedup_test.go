@@ -0,0 +1,115 @@
+package brick
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+type TestBrick100 struct {
+	Name string `json:"name"`
+}
+
+func (t *TestBrick100) BrickTypeID() string {
+	return "TestBrick100"
+}
+
+func (t *TestBrick100) NewBrick(jsonConfig []byte) Brick {
+	newBrick := &TestBrick100{}
+	if len(jsonConfig) > 0 {
+		if err := json.Unmarshal(jsonConfig, newBrick); err != nil {
+			panic(err)
+		}
+	}
+	return newBrick
+}
+
+type TestBrick1001 struct {
+	A *TestBrick100 `brick:"clone:TestBrick100 a"`
+	B *TestBrick100 `brick:"clone:TestBrick100 b"`
+	// C has another config.
+	C *TestBrick100 `brick:"clone:TestBrick100 c"`
+}
+
+func (t *TestBrick1001) BrickTypeID() string {
+	return "TestBrick1001"
+}
+
+// TestBrick1002 is a prototype.
+type TestBrick1002 struct {
+	Name string `json:"name"`
+}
+
+func (t *TestBrick1002) BrickTypeID() string {
+	return "TestBrick1002"
+}
+
+func (t *TestBrick1002) NewBrick(jsonConfig []byte) Brick {
+	newBrick := &TestBrick1002{}
+	if len(jsonConfig) > 0 {
+		if err := json.Unmarshal(jsonConfig, newBrick); err != nil {
+			panic(err)
+		}
+	}
+	return newBrick
+}
+
+var setupTestBrick100 = sync.OnceFunc(func() {
+	RegisterNewer[*TestBrick100]()
+	Register[*TestBrick1001]()
+	RegisterPrototype[*TestBrick1002]()
+	// The default live is added first.
+	for _, live := range [][2]string{{"TestBrick100", "default"}, {"TestBrick100 a", "same"}, {"TestBrick100 b", "same"}, {"TestBrick100 c", "other"}} {
+		if err := AddLive("TestBrick100", live[0], map[string]any{"name": live[1]}); err != nil {
+			panic(err)
+		}
+	}
+	if err := AddLive("TestBrick1002", "TestBrick1002", map[string]any{"name": "proto"}); err != nil {
+		panic(err)
+	}
+})
+
+func TestConfigDedup(t *testing.T) {
+	setupTestBrick100()
+
+	b := GetOrCreate[*TestBrick1001](RandomLiveID())
+	if b.A == b.B {
+		t.Error("the clones with the same config share an instance without SetConfigDedup")
+	}
+	if Get[*TestBrick1002]() == Get[*TestBrick1002]() {
+		t.Error("the prototypes share an instance without SetConfigDedup")
+	}
+
+	SetConfigDedup(true)
+	defer SetConfigDedup(false)
+	b = GetOrCreate[*TestBrick1001](RandomLiveID())
+	if b.A != b.B || b.A.Name != "same" {
+		t.Errorf("A = %p, B = %p, want the clones with the same config to share an instance", b.A, b.B)
+	}
+	if b.A == b.C || b.C.Name != "other" {
+		t.Error("the clones with different configs share an instance")
+	}
+	if b2 := GetOrCreate[*TestBrick1001](RandomLiveID()); b2.A != b.A {
+		t.Error("the clones of another build don't share the instance")
+	}
+	if Get[*TestBrick1002]() != Get[*TestBrick1002]() {
+		t.Error("the prototypes with the same config don't share an instance")
+	}
+}
+
+func BenchmarkConfigDedup(b *testing.B) {
+	setupTestBrick100()
+	for _, dedup := range []bool{false, true} {
+		name := "off"
+		if dedup {
+			name = "on"
+		}
+		b.Run(name, func(b *testing.B) {
+			SetConfigDedup(dedup)
+			defer SetConfigDedup(false)
+			for i := 0; i < b.N; i++ {
+				GetOrCreate[*TestBrick1001](RandomLiveID())
+			}
+		})
+	}
+}
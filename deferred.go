@@ -0,0 +1,131 @@
+package brick
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// A dependency tagged with `brick:"liveID,deferred"` is not constructed when its dependent is built.
+// Instead, the field is set immediately to a placeholder pointer, which is also saved as the instance of the liveID,
+// so every holder shares the same pointer.
+//
+// The placeholder stays zero until the first use of the deferred brick, which is either
+// the first Get/GetOrCreate/injection of its liveID, or an explicit call to EnsureBuilt.
+// Only then NewBrick runs and the dependencies of the deferred brick are injected. The built instance replaces
+// the placeholder as the instance of the liveID, and every field holding the placeholder is set to it.
+// Since Go can't intercept a pointer dereference, a dependent must call EnsureBuilt before it uses the field,
+// and must not keep a copy of the placeholder.
+//
+// Only pointer fields can be deferred.
+type deferredBuild struct {
+	once        sync.Once
+	placeholder reflect.Value
+	// fields are the fields holding the placeholder, guarded by deferredBuildsLock.
+	fields []reflect.Value
+	build  func() reflect.Value
+	// built is the instance built by once.
+	built reflect.Value
+}
+
+// EnsureBuilt constructs a deferred brick if it has not been constructed yet.
+// It does nothing for bricks that are not deferred or already constructed.
+func EnsureBuilt(brick Brick) {
+	brickManager.ensureBuilt(brick)
+}
+
+func (b *BrickManager) ensureBuilt(brick Brick) {
+	value := reflect.ValueOf(brick)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return
+	}
+	b.deferredBuildsLock.RLock()
+	var liveID string
+	for id, d := range b.deferredBuilds {
+		if d.placeholder.Pointer() == value.Pointer() {
+			liveID = id
+			break
+		}
+	}
+	b.deferredBuildsLock.RUnlock()
+	if liveID != "" {
+		b.runDeferredBuild(liveID)
+	}
+}
+
+// runDeferredBuild constructs the deferred brick of the liveID if there is one pending, and returns the built instance.
+// It returns false if the liveID has no pending deferred build.
+func (b *BrickManager) runDeferredBuild(liveID string) (reflect.Value, bool) {
+	b.deferredBuildsLock.RLock()
+	d, ok := b.deferredBuilds[liveID]
+	b.deferredBuildsLock.RUnlock()
+	if !ok {
+		return reflect.Value{}, false
+	}
+	d.once.Do(func() {
+		built := d.build()
+		b.deferredBuildsLock.Lock()
+		defer b.deferredBuildsLock.Unlock()
+		b.saveBrickInstance(liveID, built)
+		for _, field := range d.fields {
+			field.Set(convertInstance(built, field.Type(), liveID))
+		}
+		d.built = built
+		delete(b.deferredBuilds, liveID)
+	})
+	return d.built, d.built.IsValid()
+}
+
+// `brick:"liveID,deferred"`
+func injectDeferredBrick(valueField reflect.Value, liveID string) {
+	typ := valueField.Type()
+	if typ.Kind() != reflect.Ptr {
+		panic(fmt.Errorf("deferred brick(%s) must be a pointer", typ))
+	}
+	typeID, ok := brickManager.getBrickTypeID(typ)
+	if !ok {
+		typeID = brickManager.getTypeIDByReflectType(typ)
+	}
+	if brickManager.IsDisabled(typeID) {
+		valueField.Set(brickManager.getDisabledFallback(typeID, typ))
+		return
+	}
+	if liveID == "" {
+		liveID = typeID
+	}
+	liveID = brickManager.resolveLiveID(liveID)
+	// The lock is held until the placeholder is saved, so that the holders of the liveID share one placeholder.
+	brickManager.deferredBuildsLock.Lock()
+	defer brickManager.deferredBuildsLock.Unlock()
+	if d, ok := brickManager.deferredBuilds[liveID]; ok {
+		d.fields = append(d.fields, valueField)
+		valueField.Set(convertInstance(d.placeholder, typ, liveID))
+		return
+	}
+	if brick, ok := brickManager.getBrickFromExist(liveID); ok {
+		valueField.Set(convertInstance(brick, typ, liveID))
+		return
+	}
+
+	// The placeholder is the pointer layer holding the struct, like the instances of the other bricks.
+	structType := typ
+	for structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	placeholder := reflect.New(structType)
+	d := &deferredBuild{
+		placeholder: placeholder,
+		fields:      []reflect.Value{valueField},
+		build: func() reflect.Value {
+			ctx := getBrickInstanceCtx{
+				buildingBrick: make(map[reflect.Type]bool),
+				createUnknown: true,
+				noCache:       true,
+			}
+			return convertInstance(getBrickInstance(placeholder.Type(), ctx, liveID), placeholder.Type(), liveID)
+		},
+	}
+	brickManager.deferredBuilds[liveID] = d
+	brickManager.saveBrickInstance(liveID, placeholder)
+	valueField.Set(convertInstance(placeholder, typ, liveID))
+}
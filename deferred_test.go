@@ -0,0 +1,162 @@
+package brick
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+var testBrick20NewBrickCalls int
+
+type TestBrick20 struct {
+	BrickBase[*TestBrick20]
+	Name string      `json:"name"`
+	T1   *TestBrick1 `brick:""`
+}
+
+func (t *TestBrick20) BrickTypeID() string {
+	return "TestBrick20"
+}
+
+func (t *TestBrick20) NewBrick(config []byte) Brick {
+	testBrick20NewBrickCalls++
+	var newBrick = &TestBrick20{}
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, newBrick); err != nil {
+			panic(err)
+		}
+	}
+	return newBrick
+}
+
+type TestBrick201 struct {
+	T20 *TestBrick20 `brick:"TestBrick20 deferred,deferred"`
+}
+
+func (t *TestBrick201) BrickTypeID() string {
+	return "TestBrick201"
+}
+
+type TestBrick202 struct {
+	T20 *TestBrick20 `brick:"TestBrick20 deferred"`
+}
+
+func (t *TestBrick202) BrickTypeID() string {
+	return "TestBrick202"
+}
+
+func TestDeferredBrick(t *testing.T) {
+	RegisterNewer[*TestBrick20]()
+	Register[*TestBrick201]()
+	Register[*TestBrick202]()
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick20"},
+		"lives": [{"liveID": "TestBrick20", "config": {"name": "default"}}, {"liveID": "TestBrick20 deferred", "config": {"name": "deferred"}}]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := Get[*TestBrick201]()
+	if testBrick20NewBrickCalls != 0 {
+		t.Fatalf("NewBrick called %d times before first use", testBrick20NewBrickCalls)
+	}
+	if b.T20 == nil || b.T20.Name != "" {
+		t.Fatalf("T20 = %v, want an empty placeholder", b.T20)
+	}
+	placeholder := b.T20
+
+	EnsureBuilt(b.T20)
+	if testBrick20NewBrickCalls != 1 {
+		t.Errorf("NewBrick called %d times after EnsureBuilt, want 1", testBrick20NewBrickCalls)
+	}
+	if b.T20 == placeholder || b.T20.Name != "deferred" || b.T20.T1 == nil || b.T20.BrickLiveID() != "TestBrick20 deferred" {
+		t.Errorf("T20 = %+v, want the field forwarded to the built brick", b.T20)
+	}
+	EnsureBuilt(b.T20)
+	if got := Get[*TestBrick202]().T20; got != b.T20 || testBrick20NewBrickCalls != 1 {
+		t.Errorf("deferred brick was built again")
+	}
+}
+
+type TestBrick203 struct {
+	T20 *TestBrick20 `brick:"TestBrick20 deferred2,deferred"`
+}
+
+func (t *TestBrick203) BrickTypeID() string {
+	return "TestBrick203"
+}
+
+func TestDeferredBrickOnGet(t *testing.T) {
+	Register[*TestBrick203]()
+	calls := testBrick20NewBrickCalls
+	b := Get[*TestBrick203]()
+	if testBrick20NewBrickCalls != calls {
+		t.Fatalf("NewBrick called before first use")
+	}
+	if got := Get[*TestBrick20]("TestBrick20 deferred2"); got != b.T20 {
+		t.Errorf("Get() = %p, want the instance %p the field is forwarded to", got, b.T20)
+	}
+	if testBrick20NewBrickCalls != calls+1 || b.T20.T1 == nil {
+		t.Errorf("deferred brick was not built on Get")
+	}
+}
+
+type TestBrick106 struct {
+	self *TestBrick106
+}
+
+func (t *TestBrick106) BrickTypeID() string {
+	return "TestBrick106"
+}
+
+func (t *TestBrick106) BrickInit() error {
+	t.self = t
+	return nil
+}
+
+type TestBrick1061 struct {
+	Dep *TestBrick106 `brick:"TestBrick106 deferred,deferred"`
+}
+
+func (t *TestBrick1061) BrickTypeID() string {
+	return "TestBrick1061"
+}
+
+type TestBrick1062 struct {
+	Dep *TestBrick106 `brick:"TestBrick106 deferred,deferred"`
+}
+
+func (t *TestBrick1062) BrickTypeID() string {
+	return "TestBrick1062"
+}
+
+func TestDeferredBrickForwarding(t *testing.T) {
+	Register[*TestBrick1061]()
+	Register[*TestBrick1062]()
+
+	var b1 *TestBrick1061
+	var b2 *TestBrick1062
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		b1 = Get[*TestBrick1061]()
+	}()
+	go func() {
+		defer wg.Done()
+		b2 = Get[*TestBrick1062]()
+	}()
+	wg.Wait()
+	if b1.Dep != b2.Dep {
+		t.Fatalf("the dependents hold the placeholders %p and %p, want a shared one", b1.Dep, b2.Dep)
+	}
+
+	EnsureBuilt(b1.Dep)
+	if b1.Dep != b2.Dep || b1.Dep != Get[*TestBrick106]("TestBrick106 deferred") {
+		t.Errorf("the fields are not forwarded to the built instance")
+	}
+	if b1.Dep.self != b1.Dep {
+		t.Errorf("self = %p, want BrickInit to run on the instance %p the fields hold", b1.Dep.self, b1.Dep)
+	}
+}
@@ -0,0 +1,134 @@
+package brick
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ConfigDiffKind is the kind of a configuration difference.
+type ConfigDiffKind string
+
+const (
+	ConfigAdded   ConfigDiffKind = "added"
+	ConfigRemoved ConfigDiffKind = "removed"
+	ConfigChanged ConfigDiffKind = "changed"
+)
+
+// ConfigDiff is a difference of one liveID's configuration between two managers.
+type ConfigDiff struct {
+	LiveID string
+	Kind   ConfigDiffKind
+	// Path is the key path of the difference, e.g. `db.hosts[1]`.
+	// It is empty if the whole live was added or removed, and `typeID` if the type of the live changed.
+	Path string
+	Old  any
+	New  any
+}
+
+// DiffConfigs returns the differences of the stored configurations from a to b,
+// ordered by liveID then key path. Env placeholders are compared as is.
+func DiffConfigs(a, b *BrickManager) []ConfigDiff {
+	return diffBrickConfigs(a.snapshotBrickConfigs(), b.snapshotBrickConfigs())
+}
+
+func (b *BrickManager) snapshotBrickConfigs() map[string]BrickConfig {
+	b.brickConfigLock.RLock()
+	defer b.brickConfigLock.RUnlock()
+	configs := make(map[string]BrickConfig, len(b.brickConfigs))
+	for liveID, config := range b.brickConfigs {
+		configs[liveID] = config
+	}
+	return configs
+}
+
+func diffBrickConfigs(a, b map[string]BrickConfig) []ConfigDiff {
+	var diffs []ConfigDiff
+	for liveID, oldConfig := range a {
+		newConfig, ok := b[liveID]
+		if !ok {
+			diffs = append(diffs, ConfigDiff{LiveID: liveID, Kind: ConfigRemoved, Old: normalizeConfig(oldConfig.Config)})
+			continue
+		}
+		if oldConfig.TypeID != newConfig.TypeID {
+			diffs = append(diffs, ConfigDiff{LiveID: liveID, Kind: ConfigChanged, Path: "typeID", Old: oldConfig.TypeID, New: newConfig.TypeID})
+		}
+		diffs = diffConfigValues(diffs, liveID, "", normalizeConfig(oldConfig.Config), normalizeConfig(newConfig.Config))
+	}
+	for liveID, newConfig := range b {
+		if _, ok := a[liveID]; !ok {
+			diffs = append(diffs, ConfigDiff{LiveID: liveID, Kind: ConfigAdded, New: normalizeConfig(newConfig.Config)})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].LiveID != diffs[j].LiveID {
+			return diffs[i].LiveID < diffs[j].LiveID
+		}
+		return diffs[i].Path < diffs[j].Path
+	})
+	return diffs
+}
+
+// diffConfigValues appends the differences between two normalized config values at the key path.
+func diffConfigValues(diffs []ConfigDiff, liveID string, path string, a, b any) []ConfigDiff {
+	mapA, okA := a.(map[string]any)
+	mapB, okB := b.(map[string]any)
+	if okA && okB {
+		for k, va := range mapA {
+			vb, ok := mapB[k]
+			if !ok {
+				diffs = append(diffs, ConfigDiff{LiveID: liveID, Kind: ConfigRemoved, Path: joinConfigPath(path, k), Old: va})
+				continue
+			}
+			diffs = diffConfigValues(diffs, liveID, joinConfigPath(path, k), va, vb)
+		}
+		for k, vb := range mapB {
+			if _, ok := mapA[k]; !ok {
+				diffs = append(diffs, ConfigDiff{LiveID: liveID, Kind: ConfigAdded, Path: joinConfigPath(path, k), New: vb})
+			}
+		}
+		return diffs
+	}
+	sliceA, okA := a.([]any)
+	sliceB, okB := b.([]any)
+	if okA && okB {
+		for i := 0; i < len(sliceA) || i < len(sliceB); i++ {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(sliceB):
+				diffs = append(diffs, ConfigDiff{LiveID: liveID, Kind: ConfigRemoved, Path: itemPath, Old: sliceA[i]})
+			case i >= len(sliceA):
+				diffs = append(diffs, ConfigDiff{LiveID: liveID, Kind: ConfigAdded, Path: itemPath, New: sliceB[i]})
+			default:
+				diffs = diffConfigValues(diffs, liveID, itemPath, sliceA[i], sliceB[i])
+			}
+		}
+		return diffs
+	}
+	if !reflect.DeepEqual(a, b) {
+		diffs = append(diffs, ConfigDiff{LiveID: liveID, Kind: ConfigChanged, Path: path, Old: a, New: b})
+	}
+	return diffs
+}
+
+func joinConfigPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// normalizeConfig round-trips a configuration through json, so that configurations loaded from
+// different formats have the same representation.
+func normalizeConfig(config any) any {
+	content, err := json.Marshal(config)
+	if err != nil {
+		return config
+	}
+	var normalized any
+	if err := json.Unmarshal(content, &normalized); err != nil {
+		return config
+	}
+	return normalized
+}
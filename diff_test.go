@@ -0,0 +1,54 @@
+package brick
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	prod := NewBrickManager()
+	err := prod.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "DB"},
+		"lives": [
+			{"liveID": "DB", "config": {"host": "${DB_HOST}", "port": 3306, "hosts": ["a", "b"], "pool": {"size": 10}}},
+			{"liveID": "DB replica", "config": {"host": "replica"}}
+		]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	staging := NewBrickManager()
+	err = staging.addConfigFileYaml([]byte(`
+- metaData:
+    typeID: DB
+  lives:
+    - liveID: DB
+      config:
+        host: ${DB_HOST}
+        port: 3307
+        hosts: [a]
+        pool:
+          size: 10
+          idle: 2
+    - liveID: DB cache
+      config:
+        host: cache
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ConfigDiff{
+		{LiveID: "DB", Kind: ConfigRemoved, Path: "hosts[1]", Old: "b"},
+		{LiveID: "DB", Kind: ConfigAdded, Path: "pool.idle", New: float64(2)},
+		{LiveID: "DB", Kind: ConfigChanged, Path: "port", Old: float64(3306), New: float64(3307)},
+		{LiveID: "DB cache", Kind: ConfigAdded, New: map[string]any{"host": "cache"}},
+		{LiveID: "DB replica", Kind: ConfigRemoved, Old: map[string]any{"host": "replica"}},
+	}
+	if got := DiffConfigs(prod, staging); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffConfigs() = %+v, want %+v", got, want)
+	}
+	if got := DiffConfigs(prod, prod); len(got) != 0 {
+		t.Errorf("DiffConfigs() = %+v, want no difference", got)
+	}
+}
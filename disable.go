@@ -0,0 +1,71 @@
+package brick
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Disable disables the brick type at runtime, e.g. for feature flags.
+// Get and GetOrCreate of a disabled brick (including injection into other bricks) return
+// the fallback registered by RegisterDisabledFallback, or panic with ErrBrickDisabled if there is none,
+// in which case an `optional` field depending on it is left nil.
+//
+// Existing instances are not destroyed, they are returned again after Enable.
+// Meanwhile they are skipped by GetAll and StartAll, Shutdown still closes them.
+func Disable(typeID string) {
+	brickManager.Disable(typeID)
+}
+
+// Enable enables the brick type disabled by Disable.
+func Enable(typeID string) {
+	brickManager.Enable(typeID)
+}
+
+// IsDisabled reports whether the brick type is disabled.
+func IsDisabled(typeID string) bool {
+	return brickManager.IsDisabled(typeID)
+}
+
+// RegisterDisabledFallback registers the null object returned in place of T while T is disabled.
+func RegisterDisabledFallback[T Brick](fallback T) {
+	brickManager.disabledTypesLock.Lock()
+	defer brickManager.disabledTypesLock.Unlock()
+	brickManager.disabledFallback[GetBrickTypeID[T]()] = reflect.ValueOf(fallback)
+}
+
+// Disable disables the brick type at runtime.
+func (b *BrickManager) Disable(typeID string) {
+	b.disabledTypesLock.Lock()
+	defer b.disabledTypesLock.Unlock()
+	b.disabledTypes[typeID] = true
+}
+
+// Enable enables the brick type disabled by Disable.
+func (b *BrickManager) Enable(typeID string) {
+	b.disabledTypesLock.Lock()
+	defer b.disabledTypesLock.Unlock()
+	delete(b.disabledTypes, typeID)
+}
+
+// IsDisabled reports whether the brick type is disabled.
+func (b *BrickManager) IsDisabled(typeID string) bool {
+	b.disabledTypesLock.RLock()
+	defer b.disabledTypesLock.RUnlock()
+	return b.disabledTypes[typeID]
+}
+
+// getDisabledFallback returns the fallback of a disabled brick type, it panics if there is none.
+func (b *BrickManager) getDisabledFallback(typeID string, brickType reflect.Type) reflect.Value {
+	b.disabledTypesLock.RLock()
+	fallback, ok := b.disabledFallback[typeID]
+	b.disabledTypesLock.RUnlock()
+	if !ok {
+		panic(fmt.Errorf("%w: brick(%s) has no fallback", ErrBrickDisabled, typeID))
+	}
+	return convertInstance(fallback, brickType, typeID)
+}
+
+// isDisabledInstance reports whether the instance is of a disabled brick type.
+func (b *BrickManager) isDisabledInstance(instance reflect.Value) bool {
+	return b.IsDisabled(b.getTypeIDByReflectType(instance.Type()))
+}
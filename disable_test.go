@@ -0,0 +1,184 @@
+package brick
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type TestBrick11 struct {
+	Name string
+}
+
+func (t *TestBrick11) BrickTypeID() string {
+	return "TestBrick11"
+}
+
+type TestBrick111 struct {
+	T11 *TestBrick11 `brick:""`
+}
+
+func (t *TestBrick111) BrickTypeID() string {
+	return "TestBrick111"
+}
+
+func TestDisable(t *testing.T) {
+	Register[*TestBrick111]()
+	instance := Get[*TestBrick11]()
+
+	Disable("TestBrick11")
+	if !IsDisabled("TestBrick11") {
+		t.Errorf("IsDisabled() = false, want true")
+	}
+	t.Run("no fallback", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("expected panic, but no panic")
+			}
+		}()
+		Get[*TestBrick11]()
+	})
+
+	fallback := &TestBrick11{Name: "null object"}
+	RegisterDisabledFallback(fallback)
+	if got := Get[*TestBrick11](); got != fallback {
+		t.Errorf("Get() = %v, want fallback", got)
+	}
+	if got := Get[*TestBrick111](); got.T11 != fallback {
+		t.Errorf("injected %v, want fallback", got.T11)
+	}
+
+	Enable("TestBrick11")
+	if IsDisabled("TestBrick11") {
+		t.Errorf("IsDisabled() = true, want false")
+	}
+	if got := Get[*TestBrick11](); got != instance {
+		t.Errorf("Get() after Enable = %v, want the existing instance", got)
+	}
+}
+
+type TestBrick80 struct {
+	Name string `json:"name"`
+}
+
+func (t *TestBrick80) BrickTypeID() string {
+	return "TestBrick80"
+}
+
+func (t *TestBrick80) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick80{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+type TestBrick801 struct {
+	Group []*TestBrick80 `brick:"group"`
+	Off   *TestBrick80   `brick:"TestBrick80 off,optional"`
+}
+
+func (t *TestBrick801) BrickTypeID() string {
+	return "TestBrick801"
+}
+
+func TestDisabledLive(t *testing.T) {
+	RegisterNewer[*TestBrick80]()
+	Register[*TestBrick801]()
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick80"},
+		"lives": [
+			{"liveID": "TestBrick80", "config": {"name": "default"}},
+			{"liveID": "TestBrick80 off", "config": {"name": "off"}, "disabled": true}
+		]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := Get[*TestBrick801]()
+	if len(b.Group) != 1 || b.Group[0].Name != "default" {
+		t.Errorf("Group = %v, want only the enabled live", b.Group)
+	}
+	if b.Off != nil {
+		t.Errorf("the optional disabled live = %v, want nil", b.Off)
+	}
+	if _, err := TryGet[*TestBrick80]("TestBrick80 off"); !errors.Is(err, ErrLiveDisabled) {
+		t.Errorf("TryGet() of a disabled live error = %v, want ErrLiveDisabled", err)
+	}
+
+	// A disabled live does not satisfy the liveID constraint.
+	err = NewBrickManager().addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick80"},
+		"lives": [
+			{"liveID": "TestBrick80", "disabled": true},
+			{"liveID": "TestBrick80 other"}
+		]
+	}]`))
+	if err == nil {
+		t.Error("the liveID constraint accepts a disabled default live")
+	}
+}
+
+type TestNamer105 interface {
+	Name105() string
+}
+
+type TestBrick105 struct {
+	closed bool
+}
+
+func (t *TestBrick105) BrickTypeID() string {
+	return "TestBrick105"
+}
+
+func (t *TestBrick105) Name105() string {
+	return "TestBrick105"
+}
+
+func (t *TestBrick105) Close() error {
+	t.closed = true
+	return nil
+}
+
+type TestBrick1051 struct {
+	Namer    TestNamer105  `brick:"TestBrick105,TestBrick105,optional"`
+	Optional *TestBrick105 `brick:",optional"`
+}
+
+func (t *TestBrick1051) BrickTypeID() string {
+	return "TestBrick1051"
+}
+
+func TestDisabledResolutionPaths(t *testing.T) {
+	Register[*TestBrick1051]()
+	instance := Get[*TestBrick105]()
+
+	Disable("TestBrick105")
+	defer Enable("TestBrick105")
+	b := Get[*TestBrick1051]()
+	if b.Namer != nil {
+		t.Errorf("Namer = %v, want nil for the existing instance of a disabled type", b.Namer)
+	}
+	if b.Optional != nil {
+		t.Errorf("Optional = %v, want nil for a disabled type without fallback", b.Optional)
+	}
+	if got := GetAll[*TestBrick105](); len(got) != 0 {
+		t.Errorf("GetAll() = %v, want no instance of a disabled type", got)
+	}
+	if _, err := TryGet[*TestBrick105](); !errors.Is(err, ErrBrickDisabled) {
+		t.Errorf("TryGet() error = %v, want ErrBrickDisabled", err)
+	}
+
+	m := NewBrickManager()
+	m.saveBrickInstance("TestBrick105", reflect.ValueOf(instance))
+	m.Disable("TestBrick105")
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !instance.closed {
+		t.Error("Shutdown() does not close the instance of a disabled type")
+	}
+}
@@ -0,0 +1,134 @@
+package brick
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maskedValue replaces the secret values in a state dump.
+const maskedValue = "******"
+
+// secretKeys are the key substrings marking a config value as a secret in a state dump.
+var secretKeys = []string{"password", "passwd", "secret", "token"}
+
+type stateDump struct {
+	TypeIDs         []string            `json:"typeIDs"`
+	DeclaredLiveIDs []string            `json:"declaredLiveIDs"`
+	Configs         []configDump        `json:"configs"`
+	Instances       []instanceDump      `json:"instances"`
+	Dependencies    map[string][]string `json:"dependencies"`
+}
+
+type configDump struct {
+	LiveID   string `json:"liveID"`
+	TypeID   string `json:"typeID"`
+	FilePath string `json:"filePath,omitempty"`
+	Config   any    `json:"config,omitempty"`
+}
+
+type instanceDump struct {
+	LiveID string `json:"liveID"`
+	TypeID string `json:"typeID"`
+}
+
+// DumpState returns an indented JSON snapshot of the manager for bug reports: the registered typeIDs,
+// the declared liveIDs, the configs, the built instances and the dependency edges.
+// Config values of keys looking like secrets (password, secret, token) are masked,
+// env placeholders are kept as written and their values are never included.
+func DumpState() string {
+	return brickManager.DumpState()
+}
+
+// DumpState returns an indented JSON snapshot of the manager for bug reports.
+func (b *BrickManager) DumpState() string {
+	data, err := json.MarshalIndent(b.stateDump(), "", "  ")
+	if err != nil {
+		return fmt.Sprintf("brick: failed to dump state: %v", err)
+	}
+	return string(data)
+}
+
+func (b *BrickManager) stateDump() stateDump {
+	var state stateDump
+	b.brickTypeIDMapLock.RLock()
+	for typeID := range b.brickTypeIDMap2 {
+		state.TypeIDs = append(state.TypeIDs, typeID)
+	}
+	b.brickTypeIDMapLock.RUnlock()
+	sort.Strings(state.TypeIDs)
+
+	b.declaredLiveIDsLock.RLock()
+	for liveID := range b.declaredLiveIDs {
+		state.DeclaredLiveIDs = append(state.DeclaredLiveIDs, liveID)
+	}
+	b.declaredLiveIDsLock.RUnlock()
+	sort.Strings(state.DeclaredLiveIDs)
+
+	b.brickConfigLock.RLock()
+	for _, config := range b.brickConfigs {
+		state.Configs = append(state.Configs, configDump{
+			LiveID:   config.LiveID,
+			TypeID:   config.TypeID,
+			FilePath: config.filePath,
+			Config:   maskSecrets(config.Config),
+		})
+	}
+	b.brickConfigLock.RUnlock()
+	sort.Slice(state.Configs, func(i, j int) bool { return state.Configs[i].LiveID < state.Configs[j].LiveID })
+
+	for _, liveID := range b.BuiltLiveIDs() {
+		typeID := ""
+		if typ, ok := b.liveIDType(liveID); ok {
+			typeID = b.getTypeIDByReflectType(typ)
+		}
+		state.Instances = append(state.Instances, instanceDump{LiveID: liveID, TypeID: typeID})
+	}
+
+	state.Dependencies = b.dependencyGraph()
+	return state
+}
+
+// maskSecrets returns a copy of the config whose values of secret keys are masked.
+func maskSecrets(config any) any {
+	switch val := config.(type) {
+	case map[string]any:
+		ret := make(map[string]any, len(val))
+		for k, v := range val {
+			if isSecretKey(k) {
+				ret[k] = maskedValue
+			} else {
+				ret[k] = maskSecrets(v)
+			}
+		}
+		return ret
+	case map[string]string:
+		ret := make(map[string]any, len(val))
+		for k, v := range val {
+			if isSecretKey(k) {
+				ret[k] = maskedValue
+			} else {
+				ret[k] = v
+			}
+		}
+		return ret
+	case []any:
+		ret := make([]any, len(val))
+		for i, v := range val {
+			ret[i] = maskSecrets(v)
+		}
+		return ret
+	}
+	return config
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package brick
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type TestBrick46 struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+func (t *TestBrick46) BrickTypeID() string {
+	return "TestBrick46"
+}
+
+func (t *TestBrick46) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick46{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+type TestBrick461 struct {
+	DB *TestBrick46 `brick:""`
+}
+
+func (t *TestBrick461) BrickTypeID() string {
+	return "TestBrick461"
+}
+
+func TestDumpState(t *testing.T) {
+	RegisterNewer[*TestBrick46]()
+	Register[*TestBrick461]()
+	path := filepath.Join(t.TempDir(), "dump.json")
+	content := `[{"metaData": {"typeID": "TestBrick46"}, "lives": [
+		{"liveID": "TestBrick46", "config": {"user": "admin", "password": "hunter2"}}
+	]}]`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	Get[*TestBrick461]()
+
+	dump := DumpState()
+	if strings.Contains(dump, "hunter2") {
+		t.Errorf("DumpState() contains the password:\n%s", dump)
+	}
+	var state stateDump
+	if err := json.Unmarshal([]byte(dump), &state); err != nil {
+		t.Fatalf("DumpState() is not valid JSON: %v", err)
+	}
+	var config map[string]any
+	for _, c := range state.Configs {
+		if c.LiveID == "TestBrick46" {
+			config, _ = c.Config.(map[string]any)
+		}
+	}
+	if config["user"] != "admin" || config["password"] != maskedValue {
+		t.Errorf("config = %v, want user kept and password masked", config)
+	}
+	var found bool
+	for _, instance := range state.Instances {
+		if instance.LiveID == "TestBrick461" && instance.TypeID == "TestBrick461" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("instances = %v, missing TestBrick461", state.Instances)
+	}
+	if deps := state.Dependencies["TestBrick461"]; len(deps) != 1 || deps[0] != "TestBrick46" {
+		t.Errorf("dependencies of TestBrick461 = %v, want [TestBrick46]", deps)
+	}
+}
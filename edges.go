@@ -0,0 +1,201 @@
+package brick
+
+import (
+	"reflect"
+	"sort"
+)
+
+// brickField is a `brick` tagged field of a struct type with its parsed tag.
+type brickField struct {
+	field reflect.StructField
+	tag   BrickTag
+}
+
+// brickFields returns the `brick` tagged fields of the struct type, or of the struct type a pointer type points to.
+// The tags of a type never change, they are parsed once and cached.
+func (b *BrickManager) brickFields(typ reflect.Type) []brickField {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+	b.brickFieldsLock.RLock()
+	fields, ok := b.brickFieldCache[typ]
+	b.brickFieldsLock.RUnlock()
+	if ok {
+		return fields
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if tag, ok := field.Tag.Lookup(brickTag); ok {
+			fields = append(fields, brickField{field: field, tag: ParseBrickTag(tag)})
+		}
+	}
+	b.brickFieldsLock.Lock()
+	b.brickFieldCache[typ] = fields
+	b.brickFieldsLock.Unlock()
+	return fields
+}
+
+// dependencyEdge is a dependency of a `brick` tagged field.
+type dependencyEdge struct {
+	// liveID is the live depended on after resolving the aliases, empty for a random live.
+	liveID string
+	// typ is the brick type of the dependency, nil if it can't be determined statically.
+	typ reflect.Type
+	// clone and random mark a new instance of the type rather than a shared live.
+	clone, random bool
+}
+
+// fieldDeps is the static injection decision of a `brick` tagged field.
+type fieldDeps struct {
+	lazy  bool
+	iface bool
+	// source is how the lives are decided, see fieldPlan.Source.
+	source string
+	// typeID is the typeID of the injected lives, empty if it is unknown.
+	typeID string
+	// edges are the bricks injected into the field, none if they are not known statically.
+	edges []dependencyEdge
+}
+
+// resolveField classifies a `brick` tagged field by its tag and resolves its dependencies statically
+// from the registered types, the configs and the bindings. It is the single reading of the tags
+// shared by Dependencies, DependencyGraph, InjectionPlanJSON and the registration checks.
+// Secrets, from, method, provider, selected, configs and liveids fields have no edge.
+func (b *BrickManager) resolveField(fieldType reflect.Type, tag BrickTag) fieldDeps {
+	var deps fieldDeps
+	if elemType, ok := lazyElemType(fieldType); ok {
+		deps.lazy = true
+		fieldType = elemType
+	}
+	if fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Map {
+		fieldType = fieldType.Elem()
+	}
+	baseType := fieldType
+	for baseType.Kind() == reflect.Ptr {
+		baseType = baseType.Elem()
+	}
+	deps.iface = baseType.Kind() == reflect.Interface
+
+	switch {
+	case tag.Secret != "":
+		deps.source = "secret"
+	case tag.From != "":
+		deps.source = "from"
+	case tag.Method != "":
+		deps.source = "method"
+	case tag.Provider:
+		deps.source = "provider"
+	case tag.Selected:
+		deps.source = "selected"
+	case tag.Configs:
+		deps.source, deps.typeID = "configs", tag.TypeID
+	case tag.TypeLiveIDs:
+		deps.source, deps.typeID = "liveids", tag.TypeID
+	case tag.Random:
+		deps.source = "random"
+		if deps.iface {
+			deps.typeID, _ = b.getImplBinding(baseType)
+		} else {
+			deps.typeID, _ = b.typeIDOf(fieldType)
+		}
+		if typ, ok := b.getBrickType(deps.typeID); ok {
+			deps.edges = append(deps.edges, dependencyEdge{typ: typ, random: true})
+		}
+	case tag.Weighted:
+		deps.source, deps.typeID = "weighted", tag.TypeID
+		if deps.typeID == "" {
+			deps.typeID, _ = b.typeIDOf(fieldType)
+		}
+		typ, _ := b.getBrickType(deps.typeID)
+		for _, config := range b.getEnabledBrickConfigsByTypeID(deps.typeID) {
+			deps.edges = append(deps.edges, dependencyEdge{liveID: b.resolveLiveID(config.LiveID), typ: typ})
+		}
+	case tag.Group || tag.All:
+		deps.source = "all"
+		if tag.Group {
+			deps.source = "group"
+		}
+		members := b.typeMembers(fieldType, tag.Group)
+		sort.Slice(members, func(i, j int) bool { return members[i].liveID < members[j].liveID })
+		for _, member := range members {
+			deps.edges = append(deps.edges, dependencyEdge{liveID: b.resolveLiveID(member.liveID), typ: member.typ})
+		}
+		if !deps.iface {
+			deps.typeID, _ = b.typeIDOf(fieldType)
+		}
+	default:
+		b.resolveTaggedLives(&deps, fieldType, baseType, tag)
+	}
+	return deps
+}
+
+// resolveTaggedLives resolves the lives written in the tag, or the default live of the field type or of its binding.
+func (b *BrickManager) resolveTaggedLives(deps *fieldDeps, fieldType, baseType reflect.Type, tag BrickTag) {
+	liveIDs := tag.LiveIDs
+	if tag.LiveID != "" {
+		liveIDs = []string{tag.LiveID}
+	}
+	deps.source = planSourceTag
+	if !deps.iface {
+		// A wrapper type of a brick has the typeID of the tag.
+		deps.typeID = tag.TypeID
+		if typeID, ok := b.typeIDOf(fieldType); ok {
+			deps.typeID = typeID
+		}
+		if len(liveIDs) == 0 {
+			deps.source = planSourceDefault
+			if deps.typeID == "" {
+				return
+			}
+			liveIDs = []string{deps.typeID}
+		}
+		typ, _ := b.getBrickType(deps.typeID)
+		for _, liveID := range liveIDs {
+			deps.edges = append(deps.edges, dependencyEdge{liveID: b.resolveLiveID(liveID), typ: typ, clone: tag.Clone})
+		}
+		return
+	}
+
+	switch {
+	case len(liveIDs) != 0:
+	case tag.TypeID != "":
+		liveIDs = []string{tag.TypeID}
+	default:
+		typeID, ok := b.getImplBinding(baseType)
+		if !ok {
+			deps.source = planSourceUnresolved
+			return
+		}
+		liveIDs = []string{typeID}
+		deps.source = planSourceBinding
+	}
+	deps.typeID = tag.TypeID
+	tagType, _ := b.getBrickType(tag.TypeID)
+	for _, liveID := range liveIDs {
+		edge := dependencyEdge{liveID: b.resolveLiveID(liveID), typ: tagType, clone: tag.Clone}
+		if edge.typ == nil {
+			// The type of a liveID is resolved from its config, its instance or RegisterLiveIDType.
+			edge.typ, _ = b.liveIDType(edge.liveID)
+		}
+		deps.edges = append(deps.edges, edge)
+	}
+	if deps.typeID != "" {
+		return
+	}
+	for _, edge := range deps.edges {
+		if edge.typ == nil {
+			deps.typeID, deps.source = "", planSourceUnresolved
+			return
+		}
+		typeID, _ := b.typeIDOf(edge.typ)
+		if deps.typeID != "" && deps.typeID != typeID {
+			// The lives of a list have different types.
+			deps.typeID = ""
+			return
+		}
+		deps.typeID = typeID
+	}
+}
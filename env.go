@@ -0,0 +1,79 @@
+package brick
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// EnvVarRef is an environment variable referenced by a config placeholder, e.g. `${DB_HOST}` or `${DB_HOST:localhost}`.
+type EnvVarRef struct {
+	Name string
+	// HasDefault is true if every placeholder of the variable gives a default value.
+	HasDefault bool
+}
+
+// RequiredEnvVars returns the environment variables referenced by the placeholders of every stored config, sorted by name.
+// Deployment tooling can check that the variables without default are set before startup.
+func RequiredEnvVars() []EnvVarRef {
+	return brickManager.RequiredEnvVars()
+}
+
+// RequiredEnvVars returns the environment variables referenced by the placeholders of every stored config, sorted by name.
+func (b *BrickManager) RequiredEnvVars() []EnvVarRef {
+	refs := make(map[string]bool)
+	b.brickConfigLock.RLock()
+	for _, config := range b.brickConfigs {
+		collectEnvVars(config.Config, refs)
+	}
+	b.brickConfigLock.RUnlock()
+	ret := make([]EnvVarRef, 0, len(refs))
+	for name, hasDefault := range refs {
+		ret = append(ret, EnvVarRef{Name: name, HasDefault: hasDefault})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
+
+// collectEnvVars collects the variables of the env config items in the config,
+// refs records whether every placeholder of a variable gives a default value.
+func collectEnvVars(config any, refs map[string]bool) {
+	switch val := config.(type) {
+	case string:
+		if !isEnvConfigItem(val) {
+			return
+		}
+		os.Expand(val, func(placeholder string) string {
+			if strings.HasPrefix(placeholder, flagPlaceholderPrefix) {
+				return ""
+			}
+			name, _, hasDefault := parseEnvPlaceholder(placeholder)
+			if old, ok := refs[name]; ok {
+				hasDefault = hasDefault && old
+			}
+			refs[name] = hasDefault
+			return ""
+		})
+	case map[string]any:
+		for _, v := range val {
+			collectEnvVars(v, refs)
+		}
+	case map[string]string:
+		for _, v := range val {
+			collectEnvVars(v, refs)
+		}
+	case []any:
+		for _, v := range val {
+			collectEnvVars(v, refs)
+		}
+	case []string:
+		for _, v := range val {
+			collectEnvVars(v, refs)
+		}
+	}
+}
+
+// parseEnvPlaceholder parses the content of a placeholder `NAME` or `NAME:default`, the default may contain colons.
+func parseEnvPlaceholder(placeholder string) (name string, defaultValue string, hasDefault bool) {
+	return strings.Cut(placeholder, ":")
+}
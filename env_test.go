@@ -0,0 +1,63 @@
+package brick
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRequiredEnvVars(t *testing.T) {
+	m := NewBrickManager()
+	err := m.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick42"},
+		"lives": [
+			{"liveID": "TestBrick42", "config": {
+				"host": "${TEST_BRICK42_HOST:localhost}",
+				"user": "${TEST_BRICK42_USER}",
+				"dsn": "${TEST_BRICK42_SCHEME:mysql}://${TEST_BRICK42_ADDR}",
+				"literal": "$TEST_BRICK42_LITERAL",
+				"nested": {"password": ["${TEST_BRICK42_PASSWORD:a:b}"]}
+			}},
+			{"liveID": "TestBrick42 b", "config": {"host": "${TEST_BRICK42_HOST}"}}
+		]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []EnvVarRef{
+		{Name: "TEST_BRICK42_ADDR"},
+		{Name: "TEST_BRICK42_HOST"},
+		{Name: "TEST_BRICK42_PASSWORD", HasDefault: true},
+		{Name: "TEST_BRICK42_SCHEME", HasDefault: true},
+		{Name: "TEST_BRICK42_USER"},
+	}
+	if got := m.RequiredEnvVars(); !reflect.DeepEqual(got, want) {
+		t.Errorf("RequiredEnvVars() = %v, want %v", got, want)
+	}
+}
+
+func TestEnvConfigItemDefault(t *testing.T) {
+	t.Setenv("TEST_ENV_DEFAULT_SET", "db.internal")
+	t.Setenv("TEST_ENV_DEFAULT_EMPTY", "")
+	tests := []struct {
+		name string
+		item string
+		want string
+	}{
+		{"unset uses default", "${TEST_ENV_DEFAULT_UNSET:localhost}", "localhost"},
+		{"empty uses default", "${TEST_ENV_DEFAULT_EMPTY:localhost}", "localhost"},
+		{"set overrides default", "${TEST_ENV_DEFAULT_SET:localhost}", "db.internal"},
+		{"plain form", "${TEST_ENV_DEFAULT_SET}", "db.internal"},
+		{"plain form unset", "${TEST_ENV_DEFAULT_UNSET}", ""},
+		{"default with colons", "${TEST_ENV_DEFAULT_UNSET:http://localhost:8080}", "http://localhost:8080"},
+		{"empty default", "${TEST_ENV_DEFAULT_UNSET:}", ""},
+		{"not an env item", "price: $5 ${TEST_ENV_DEFAULT_SET:x}", "price: $5 ${TEST_ENV_DEFAULT_SET:x}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := handleConfig(map[string]any{"item": tt.item}).(map[string]any)["item"]
+			if got != tt.want {
+				t.Errorf("handleConfig(%q) = %q, want %q", tt.item, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package brick
+
+import (
+	"fmt"
+	"log"
+)
+
+// RegisterFallbackFactory registers the factory used in place of NewBrick of T when NewBrick panics,
+// e.g. an in-memory cache when Redis is down. The failure is logged, the fallback instance is cached
+// as the instance of the liveID, and the live is marked degraded.
+//
+// Dependents can't distinguish a degraded instance from a healthy one, unless they check IsDegraded.
+// Only the construction of the brick itself falls back, failures while injecting its dependencies still panic.
+func RegisterFallbackFactory[T BrickNewer](fallback func() T) {
+	typeID := GetBrickTypeID[T]()
+	brickManager.fallbackFactoriesLock.Lock()
+	defer brickManager.fallbackFactoriesLock.Unlock()
+	if _, ok := brickManager.fallbackFactories[typeID]; ok {
+		panic(fmt.Errorf("fallback factory of brick(%s) already registered", typeID))
+	}
+	brickManager.fallbackFactories[typeID] = func() Brick {
+		return fallback()
+	}
+}
+
+// IsDegraded reports whether the instance of the liveID was built by a fallback factory.
+func IsDegraded(liveID string) bool {
+	return brickManager.IsDegraded(liveID)
+}
+
+// IsDegraded reports whether the instance of the liveID was built by a fallback factory.
+func (b *BrickManager) IsDegraded(liveID string) bool {
+	liveID = b.resolveLiveID(liveID)
+	b.degradedLock.RLock()
+	defer b.degradedLock.RUnlock()
+	return b.degraded[liveID]
+}
+
+func (b *BrickManager) setDegraded(liveID string, degraded bool) {
+	b.degradedLock.Lock()
+	defer b.degradedLock.Unlock()
+	if degraded {
+		b.degraded[liveID] = true
+	} else {
+		delete(b.degraded, liveID)
+	}
+}
+
+// newBrick calls the factory of the brick type, and the fallback factory if the factory panics.
+func (b *BrickManager) newBrick(typeID string, liveID string, factory func(config any) Brick, config any) (brick Brick, degraded bool) {
+	b.fallbackFactoriesLock.RLock()
+	fallback, ok := b.fallbackFactories[typeID]
+	b.fallbackFactoriesLock.RUnlock()
+	if !ok {
+		return factory(config), false
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("brick: failed to build brick(%s) of liveID(%s), using the fallback: %v", typeID, liveID, r)
+			brick, degraded = fallback(), true
+		}
+	}()
+	return factory(config), false
+}
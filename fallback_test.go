@@ -0,0 +1,66 @@
+package brick
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type TestBrick35 struct {
+	Addr string `json:"addr"`
+}
+
+func (t *TestBrick35) BrickTypeID() string {
+	return "TestBrick35"
+}
+
+func (t *TestBrick35) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick35{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	if newBrick.Addr == "down" {
+		panic(errors.New("connection refused"))
+	}
+	return newBrick
+}
+
+type TestBrick351 struct {
+	Cache *TestBrick35 `brick:""`
+}
+
+func (t *TestBrick351) BrickTypeID() string {
+	return "TestBrick351"
+}
+
+func TestRegisterFallbackFactory(t *testing.T) {
+	RegisterNewer[*TestBrick35]()
+	Register[*TestBrick351]()
+	RegisterFallbackFactory(func() *TestBrick35 {
+		return &TestBrick35{Addr: "memory"}
+	})
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick35"},
+		"lives": [
+			{"liveID": "TestBrick35", "config": {"addr": "down"}},
+			{"liveID": "TestBrick35 healthy", "config": {"addr": "up"}}
+		]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := Get[*TestBrick351]()
+	if b.Cache.Addr != "memory" {
+		t.Errorf("Cache.Addr = %s, want the fallback", b.Cache.Addr)
+	}
+	if b.Cache != Get[*TestBrick35]() {
+		t.Errorf("the fallback instance is not cached")
+	}
+	if !IsDegraded("TestBrick35") {
+		t.Errorf("IsDegraded(TestBrick35) = false, want true")
+	}
+	if got := Get[*TestBrick35]("TestBrick35 healthy"); got.Addr != "up" || IsDegraded("TestBrick35 healthy") {
+		t.Errorf("healthy live = %+v, degraded %v", got, IsDegraded("TestBrick35 healthy"))
+	}
+}
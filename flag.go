@@ -0,0 +1,37 @@
+package brick
+
+import "flag"
+
+// flagPlaceholderPrefix is the prefix of the config placeholders resolved from the command-line flags,
+// e.g. `${flag:port}` or `${flag:port:8080}` with a default value.
+const flagPlaceholderPrefix = "flag:"
+
+// SetFlagResolver sets the resolver of the `${flag:name}` placeholders of the configs, like the env placeholders
+// `${NAME}`, e.g. FlagSetResolver(flag.CommandLine) after flag.Parse. The placeholder is replaced with the value
+// of the flag, or with its default `${flag:name:default}` if the resolver doesn't know the flag.
+// When a config is saved, the placeholders are retained, and the flags are not changed.
+func SetFlagResolver(resolver func(name string) (string, bool)) {
+	brickManager.flagResolver = resolver
+}
+
+// FlagSetResolver returns a resolver of SetFlagResolver looking up the flags defined in fs.
+func FlagSetResolver(fs *flag.FlagSet) func(name string) (string, bool) {
+	return func(name string) (string, bool) {
+		f := fs.Lookup(name)
+		if f == nil {
+			return "", false
+		}
+		return f.Value.String(), true
+	}
+}
+
+// expandFlagPlaceholder returns the value of the placeholder `name` or `name:default` of a flag.
+func expandFlagPlaceholder(placeholder string) string {
+	name, defaultValue, _ := parseEnvPlaceholder(placeholder)
+	if resolver := brickManager.flagResolver; resolver != nil {
+		if value, ok := resolver(name); ok {
+			return value
+		}
+	}
+	return defaultValue
+}
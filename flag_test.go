@@ -0,0 +1,78 @@
+package brick
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type TestBrick99 struct {
+	BrickBase[*TestBrick99]
+	Host string `json:"host"`
+	Mode string `json:"mode"`
+}
+
+func (t *TestBrick99) BrickTypeID() string {
+	return "TestBrick99"
+}
+
+func (t *TestBrick99) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick99{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func TestFlagPlaceholder(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("host", "", "")
+	if err := fs.Parse([]string{"-host", "db.internal"}); err != nil {
+		t.Fatal(err)
+	}
+	SetFlagResolver(FlagSetResolver(fs))
+	defer SetFlagResolver(nil)
+
+	RegisterNewer[*TestBrick99]()
+	path := filepath.Join(t.TempDir(), "db.json")
+	content := `[{
+		"metaData": {"typeID": "TestBrick99"},
+		"lives": [{"liveID": "TestBrick99", "config": {"host": "${flag:host}", "mode": "${flag:mode:debug}"}}]
+	}]`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	b := Get[*TestBrick99]()
+	if b.Host != "db.internal" || b.Mode != "debug" {
+		t.Errorf("config = %+v, want the flag value and the default of the unknown flag", b)
+	}
+	for _, ref := range RequiredEnvVars() {
+		if ref.Name == "flag" {
+			t.Errorf("RequiredEnvVars() = %v, want no flag placeholder", RequiredEnvVars())
+		}
+	}
+
+	if err := b.SaveBrickConfig(map[string]any{"host": "db2.internal", "mode": "release"}); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs, err := parseConfigJson(saved)
+	if err != nil {
+		t.Fatalf("parse the saved file: %v\n%s", err, saved)
+	}
+	config := configs[0].Lives[0].Config.(map[string]any)
+	if config["host"] != "${flag:host}" || config["mode"] != "${flag:mode:debug}" {
+		t.Errorf("saved config = %v, want the placeholders kept", config)
+	}
+	if got := fs.Lookup("host").Value.String(); got != "db.internal" {
+		t.Errorf("flag host = %s, want it unchanged", got)
+	}
+}
@@ -0,0 +1,86 @@
+package brick
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type TestBrick43 struct {
+	DSN string `json:"dsn"`
+}
+
+func (t *TestBrick43) BrickTypeID() string {
+	return "TestBrick43"
+}
+
+func (t *TestBrick43) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick43{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+type TestBrick431 struct {
+	DriverName string       `json:"driverName"`
+	DB         *TestBrick43 `brick:"from:DriverName"`
+}
+
+func (t *TestBrick431) BrickTypeID() string {
+	return "TestBrick431"
+}
+
+func (t *TestBrick431) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick431{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func TestFromTag(t *testing.T) {
+	RegisterNewer[*TestBrick43]()
+	RegisterNewer[*TestBrick431]()
+	path := filepath.Join(t.TempDir(), "from.json")
+	content := `[
+	{"metaData": {"typeID": "TestBrick43"}, "lives": [
+		{"liveID": "TestBrick43", "config": {"dsn": "default"}},
+		{"liveID": "TestBrick43 postgres", "config": {"dsn": "postgres://"}}
+	]},
+	{"metaData": {"typeID": "TestBrick431"}, "lives": [
+		{"liveID": "TestBrick431", "config": {"driverName": "TestBrick43 postgres"}},
+		{"liveID": "TestBrick431 empty", "config": {}}
+	]}
+]`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	b := Get[*TestBrick431]()
+	if b.DB == nil || b.DB.DSN != "postgres://" {
+		t.Errorf("DB = %v, want the live named by DriverName", b.DB)
+	}
+	if b.DB != Get[*TestBrick43]("TestBrick43 postgres") {
+		t.Errorf("DB is not the shared instance of the live")
+	}
+	if tag := ParseBrickTag("from:DriverName"); tag.From != "DriverName" || tag.LiveID != "" {
+		t.Errorf("ParseBrickTag() = %+v, want From DriverName", tag)
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("empty source field did not panic")
+		}
+		if msg := r.(error).Error(); !strings.Contains(msg, "is empty") {
+			t.Errorf("panic = %q, want an empty source field error", msg)
+		}
+	}()
+	Get[*TestBrick431]("TestBrick431 empty")
+}
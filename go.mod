@@ -7,3 +7,5 @@ require github.com/doraemonkeys/doraemon v0.6.1
 require gopkg.in/yaml.v3 v3.0.1
 
 require golang.org/x/sync v0.11.0
+
+require github.com/BurntSushi/toml v1.4.0
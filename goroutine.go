@@ -0,0 +1,107 @@
+package brick
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+)
+
+// A goroutine scope is a Scope bound to the calling goroutine between BeginGoroutineScope and EndGoroutineScope,
+// e.g. for the per-request bricks of a handler that can't thread the Scope or a context.
+//
+// Go reuses neither goroutine IDs nor goroutines by itself, but worker pools do: a scope that is not ended
+// leaks its bricks into the next task run by the same goroutine. Always defer EndGoroutineScope right after
+// BeginGoroutineScope, and never leave a scope open across tasks. Prefer a Scope when it can be threaded.
+
+// BeginGoroutineScope opens a scope for the calling goroutine, it panics if the goroutine is already in a scope.
+func BeginGoroutineScope() {
+	brickManager.BeginGoroutineScope()
+}
+
+// EndGoroutineScope closes the scope of the calling goroutine like Scope.Close. It panics if the goroutine is not in a scope.
+func EndGoroutineScope() error {
+	return brickManager.EndGoroutineScope()
+}
+
+// GetGoroutineScoped like Get, but the brick is a singleton of the scope of the calling goroutine:
+// it is built at its first get in the scope, and dropped by EndGoroutineScope.
+// Its dependencies are the global singletons, or the instances of the scope for the request-scoped bricks,
+// as with GetInScope. It panics if the goroutine is not in a scope.
+//
+// Every call finds the scope by the ID of the calling goroutine, which is parsed from the header of runtime.Stack,
+// that costs about a microsecond: keep the brick in a variable rather than getting it in a hot loop.
+func GetGoroutineScoped[T Brick](liveID ...string) T {
+	return brickManager.getGoroutineScoped(reflect.TypeOf((*(new(T)))), liveID...).Interface().(T)
+}
+
+// BeginGoroutineScope opens a scope for the calling goroutine.
+func (b *BrickManager) BeginGoroutineScope() {
+	id := goroutineID()
+	b.goroutineScopesLock.Lock()
+	defer b.goroutineScopesLock.Unlock()
+	if _, ok := b.goroutineScopes[id]; ok {
+		panic(fmt.Errorf("goroutine %d is already in a scope, EndGoroutineScope must be called first", id))
+	}
+	b.goroutineScopes[id] = NewScope()
+}
+
+// EndGoroutineScope closes the scope of the calling goroutine.
+func (b *BrickManager) EndGoroutineScope() error {
+	id := goroutineID()
+	b.goroutineScopesLock.Lock()
+	scope, ok := b.goroutineScopes[id]
+	delete(b.goroutineScopes, id)
+	b.goroutineScopesLock.Unlock()
+	if !ok {
+		panic(fmt.Errorf("goroutine %d is not in a scope, BeginGoroutineScope must be called first", id))
+	}
+	return scope.Close()
+}
+
+func (b *BrickManager) getGoroutineScoped(brickType reflect.Type, liveID ...string) reflect.Value {
+	id := goroutineID()
+	b.goroutineScopesLock.RLock()
+	scope, ok := b.goroutineScopes[id]
+	b.goroutineScopesLock.RUnlock()
+	if !ok {
+		panic(fmt.Errorf("goroutine %d is not in a scope, BeginGoroutineScope must be called first", id))
+	}
+	typeID := b.getTypeIDByReflectType(brickType)
+	// The default live is got with or without its liveID.
+	targetLiveID := typeID
+	if len(liveID) > 0 && liveID[0] != "" {
+		targetLiveID = b.resolveLiveID(liveID[0])
+	}
+	if instance, ok := scope.instance(targetLiveID); ok {
+		return convertInstance(instance, brickType, targetLiveID)
+	}
+	b.brickConfigCheckOnce.Do(b.checkConfig)
+	ctx := getBrickInstanceCtx{
+		buildingBrick: make(map[reflect.Type]bool),
+		scope:         scope,
+	}
+	if b.isScoped(typeID) {
+		// A request-scoped brick is saved in the scope by its build.
+		return getBrickInstance(brickType, ctx, liveID...)
+	}
+	ctx.noCache = true
+	instance := scope.build(targetLiveID, func() (any, error) {
+		return getBrickInstance(brickType, ctx, liveID...), nil
+	})
+	return convertInstance(instance, brickType, targetLiveID)
+}
+
+// goroutineID returns the ID of the calling goroutine, read from the header of its stack trace, e.g. `goroutine 18 [running]:`.
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	buf = buf[:bytes.IndexByte(buf, ' ')]
+	id, err := strconv.ParseUint(string(buf), 10, 64)
+	if err != nil {
+		panic(fmt.Errorf("failed to parse the goroutine ID: %w", err))
+	}
+	return id
+}
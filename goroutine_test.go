@@ -0,0 +1,98 @@
+package brick
+
+import (
+	"sync"
+	"testing"
+)
+
+type TestBrick77 struct {
+	T771   *TestBrick771 `brick:""`
+	closed bool
+}
+
+func (t *TestBrick77) BrickTypeID() string {
+	return "TestBrick77"
+}
+
+func (t *TestBrick77) Close() error {
+	t.closed = true
+	return nil
+}
+
+type TestBrick771 struct{}
+
+func (t *TestBrick771) BrickTypeID() string {
+	return "TestBrick771"
+}
+
+func TestGoroutineScope(t *testing.T) {
+	Register[*TestBrick77]()
+
+	var got [2]*TestBrick77
+	var wg sync.WaitGroup
+	for i := range got {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			BeginGoroutineScope()
+			defer EndGoroutineScope()
+			got[i] = GetGoroutineScoped[*TestBrick77]()
+			if again := GetGoroutineScoped[*TestBrick77](); again != got[i] {
+				t.Errorf("GetGoroutineScoped() in one scope = %p, %p, want the same instance", got[i], again)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got[0] == got[1] {
+		t.Error("two scopes got the same instance")
+	}
+	if got[0].T771 != got[1].T771 || got[0].T771 != Get[*TestBrick771]() {
+		t.Error("the dependencies of the scoped bricks are not the global singletons")
+	}
+	if got[0] == Get[*TestBrick77]() {
+		t.Error("the scoped brick is the global singleton")
+	}
+	if !got[0].closed || !got[1].closed {
+		t.Error("EndGoroutineScope() did not close the scoped bricks")
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("GetGoroutineScoped() out of a scope did not panic")
+		}
+	}()
+	GetGoroutineScoped[*TestBrick77]()
+}
+
+type TestBrick772 struct{}
+
+func (t *TestBrick772) BrickTypeID() string {
+	return "TestBrick772"
+}
+
+type TestBrick773 struct {
+	Request *TestBrick772 `brick:""`
+}
+
+func (t *TestBrick773) BrickTypeID() string {
+	return "TestBrick773"
+}
+
+func TestGoroutineScopeRequestScoped(t *testing.T) {
+	RegisterScoped[*TestBrick772]()
+	Register[*TestBrick773]()
+	BeginGoroutineScope()
+	defer EndGoroutineScope()
+
+	request := GetGoroutineScoped[*TestBrick772]()
+	if again := GetGoroutineScoped[*TestBrick772]("TestBrick772"); again != request {
+		t.Error("GetGoroutineScoped() of the default live with and without its liveID got two instances")
+	}
+	if got := GetGoroutineScoped[*TestBrick773]().Request; got != request {
+		t.Error("the request-scoped dependency is not the instance of the goroutine scope")
+	}
+	if GetGoroutineScoped[*TestBrick773]() != GetGoroutineScoped[*TestBrick773]("TestBrick773") {
+		t.Error("GetGoroutineScoped() of the default live with and without its liveID got two instances")
+	}
+}
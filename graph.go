@@ -0,0 +1,174 @@
+package brick
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Dependencies returns the liveIDs that the brick of liveID transitively depends on, sorted by liveID.
+// The dependencies are resolved from the `brick` tags of the registered types and the configs,
+// the bricks do not need to be built.
+func Dependencies(liveID string) []string {
+	return brickManager.Dependencies(liveID)
+}
+
+// Dependents returns the liveIDs that transitively depend on the brick of liveID, sorted by liveID.
+func Dependents(liveID string) []string {
+	return brickManager.Dependents(liveID)
+}
+
+// Dependencies returns the liveIDs that the brick of liveID transitively depends on, sorted by liveID.
+func (b *BrickManager) Dependencies(liveID string) []string {
+	return reachable(b.dependencyGraph(), b.resolveLiveID(liveID))
+}
+
+// Dependents returns the liveIDs that transitively depend on the brick of liveID, sorted by liveID.
+func (b *BrickManager) Dependents(liveID string) []string {
+	graph := b.dependencyGraph()
+	reversed := make(map[string][]string, len(graph))
+	for dependent, deps := range graph {
+		for _, dep := range deps {
+			reversed[dep] = append(reversed[dep], dependent)
+		}
+	}
+	return reachable(reversed, b.resolveLiveID(liveID))
+}
+
+// reachable returns the nodes reachable from start, excluding start, sorted.
+func reachable(graph map[string][]string, start string) []string {
+	visited := map[string]bool{start: true}
+	queue := []string{start}
+	var ret []string
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, next := range graph[node] {
+			if !visited[next] {
+				visited[next] = true
+				ret = append(ret, next)
+				queue = append(queue, next)
+			}
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// dependencyGraph returns the direct dependencies of every known live, indexed by LiveID.
+// The known lives are the configured lives, the built instances, the lives registered by RegisterLiveIDType,
+// the default lives of the registered types, and every live they depend on.
+func (b *BrickManager) dependencyGraph() map[string][]string {
+	graph, _ := b.typedDependencyGraph()
+	return graph
+}
+
+// typedDependencyGraph like dependencyGraph, but it also returns the brick type of every live in the graph, indexed by LiveID.
+func (b *BrickManager) typedDependencyGraph() (map[string][]string, map[string]reflect.Type) {
+	graph := make(map[string][]string)
+	types := make(map[string]reflect.Type)
+	var queue []string
+	visit := func(liveID string, typ reflect.Type) {
+		if _, ok := types[liveID]; ok {
+			return
+		}
+		if typ == nil || typ.Kind() == reflect.Interface {
+			var ok bool
+			if typ, ok = b.liveIDType(liveID); !ok {
+				return
+			}
+		}
+		types[liveID] = typ
+		queue = append(queue, liveID)
+	}
+	for _, liveID := range b.knownLiveIDs() {
+		visit(liveID, nil)
+	}
+	for len(queue) > 0 {
+		liveID := queue[0]
+		queue = queue[1:]
+		seen := make(map[string]bool)
+		for _, dep := range b.fieldDependencies(liveID, types[liveID]) {
+			if !seen[dep.liveID] {
+				seen[dep.liveID] = true
+				graph[liveID] = append(graph[liveID], dep.liveID)
+			}
+			visit(dep.liveID, dep.typ)
+		}
+		sort.Strings(graph[liveID])
+	}
+	return graph, types
+}
+
+// knownLiveIDs returns the liveIDs known to the manager without resolving any dependency.
+// The configs copied for clones are not lives of their own, they are skipped.
+func (b *BrickManager) knownLiveIDs() []string {
+	var liveIDs []string
+	b.brickConfigLock.RLock()
+	for liveID, config := range b.brickConfigs {
+		if !config.clone {
+			liveIDs = append(liveIDs, liveID)
+		}
+	}
+	b.brickConfigLock.RUnlock()
+	b.instancesLock.RLock()
+	for liveID := range b.instances {
+		liveIDs = append(liveIDs, liveID)
+	}
+	b.instancesLock.RUnlock()
+	b.liveIDTypeMapLock.RLock()
+	for liveID := range b.liveIDTypeMap {
+		liveIDs = append(liveIDs, liveID)
+	}
+	b.liveIDTypeMapLock.RUnlock()
+	b.brickTypeIDMapLock.RLock()
+	for typeID := range b.brickTypeIDMap2 {
+		liveIDs = append(liveIDs, typeID)
+	}
+	b.brickTypeIDMapLock.RUnlock()
+	return liveIDs
+}
+
+// liveIDType returns the brick type of the liveID, from its instance, its config,
+// the type registered by RegisterLiveIDType, or the type of which it is the default live.
+func (b *BrickManager) liveIDType(liveID string) (reflect.Type, bool) {
+	if brick, ok := b.getBrickFromExist(liveID); ok {
+		return brick.Type(), true
+	}
+	if config, ok := b.getBrickConfig(liveID); ok {
+		return b.getBrickType(config.TypeID)
+	}
+	b.liveIDTypeMapLock.RLock()
+	typ, ok := b.liveIDTypeMap[liveID]
+	b.liveIDTypeMapLock.RUnlock()
+	if ok {
+		return typ, true
+	}
+	return b.getBrickType(liveID)
+}
+
+// fieldDependencies returns the lives that the `brick` tagged fields of the live depend on, see resolveField.
+// Random lives are not known statically and are skipped.
+func (b *BrickManager) fieldDependencies(liveID string, typ reflect.Type) []dependencyEdge {
+	var relyLives map[string]string
+	if lives, ok := b.getBrickLives(typ); ok {
+		for _, live := range lives {
+			if live.LiveID == liveID {
+				relyLives = live.RelyLives
+				break
+			}
+		}
+	}
+	var deps []dependencyEdge
+	for _, field := range b.brickFields(typ) {
+		tag := field.tag
+		if tag2, ok := relyLives[field.field.Name]; ok {
+			tag = ParseBrickTag(tag2)
+		}
+		for _, edge := range b.resolveField(field.field.Type, tag).edges {
+			if !edge.random {
+				deps = append(deps, edge)
+			}
+		}
+	}
+	return deps
+}
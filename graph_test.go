@@ -0,0 +1,158 @@
+package brick
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type TestBrick25DB struct{}
+
+func (t *TestBrick25DB) BrickTypeID() string {
+	return "TestBrick25DB"
+}
+
+type TestBrick25Cache struct {
+	DB *TestBrick25DB `brick:""`
+}
+
+func (t *TestBrick25Cache) BrickTypeID() string {
+	return "TestBrick25Cache"
+}
+
+type TestBrick25Service struct {
+	DB    *TestBrick25DB `brick:""`
+	Cache any            `brick:"TestBrick25 cache"`
+}
+
+func (t *TestBrick25Service) BrickTypeID() string {
+	return "TestBrick25Service"
+}
+
+type TestBrick25App struct {
+	Service *TestBrick25Service `brick:""`
+}
+
+func (t *TestBrick25App) BrickTypeID() string {
+	return "TestBrick25App"
+}
+
+type TestBrick25Worker struct {
+	DB *TestBrick25DB `brick:""`
+}
+
+func (t *TestBrick25Worker) BrickTypeID() string {
+	return "TestBrick25Worker"
+}
+
+func TestDependencies(t *testing.T) {
+	Register[*TestBrick25App]()
+	Register[*TestBrick25Worker]()
+	Register[*TestBrick25Cache]()
+	RegisterLiveIDType[*TestBrick25Cache]("TestBrick25 cache")
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"Dependencies(app)", Dependencies("TestBrick25App"), []string{"TestBrick25 cache", "TestBrick25DB", "TestBrick25Service"}},
+		{"Dependencies(service)", Dependencies("TestBrick25Service"), []string{"TestBrick25 cache", "TestBrick25DB"}},
+		{"Dependencies(db)", Dependencies("TestBrick25DB"), nil},
+		{"Dependents(db)", Dependents("TestBrick25DB"), []string{"TestBrick25 cache", "TestBrick25App", "TestBrick25Cache", "TestBrick25Service", "TestBrick25Worker"}},
+		{"Dependents(cache)", Dependents("TestBrick25 cache"), []string{"TestBrick25App", "TestBrick25Service"}},
+		{"Dependents(app)", Dependents("TestBrick25App"), nil},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+type TestBrick108 struct {
+	Name string         `json:"name"`
+	Dep  *TestBrick1081 `brick:""`
+}
+
+func (t *TestBrick108) BrickTypeID() string {
+	return "TestBrick108"
+}
+
+func (t *TestBrick108) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick108{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+type TestBrick1081 struct{}
+
+func (t *TestBrick1081) BrickTypeID() string {
+	return "TestBrick1081"
+}
+
+func TestDependentsSkipClones(t *testing.T) {
+	RegisterNewer[*TestBrick108]()
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick108"},
+		"lives": [{"liveID": "TestBrick108", "config": {"name": "only"}}]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	CloneConfig[*TestBrick108]()
+	if got, want := Dependents("TestBrick1081"), []string{"TestBrick108"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependents() = %v after a clone, want %v", got, want)
+	}
+}
+
+type TestBrick75 struct{}
+
+func (t *TestBrick75) BrickTypeID() string {
+	return "TestBrick75"
+}
+
+type TestBrick751 struct {
+	Shared *TestBrick75 `brick:""`
+	Clone  *TestBrick75 `brick:"clone"`
+	Random *TestBrick75 `brick:"random"`
+	Any    any          `brick:"TestBrick75 live"`
+}
+
+func (t *TestBrick751) BrickTypeID() string {
+	return "TestBrick751"
+}
+
+func TestDependencyGraph(t *testing.T) {
+	m := NewBrickManager()
+	m.register2("TestBrick75", reflect.TypeOf(&TestBrick75{}))
+	m.register2("TestBrick751", reflect.TypeOf(&TestBrick751{}))
+	m.RegisterLiveIDType("TestBrick75 live", reflect.TypeOf(&TestBrick75{}))
+
+	want := map[string][]string{
+		"TestBrick75":  {},
+		"TestBrick751": {"TestBrick75", "TestBrick75 (clone)", "TestBrick75 (random)"},
+	}
+	if got := m.DependencyGraph(); !reflect.DeepEqual(got, want) {
+		t.Errorf("DependencyGraph() = %v, want %v", got, want)
+	}
+
+	var buf bytes.Buffer
+	if err := m.ExportDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	wantDOT := `digraph brick {
+	"TestBrick75";
+	"TestBrick751";
+	"TestBrick751" -> "TestBrick75";
+	"TestBrick751" -> "TestBrick75" [style=dashed, label="clone"];
+	"TestBrick751" -> "TestBrick75" [style=dashed, label="random"];
+}
+`
+	if buf.String() != wantDOT {
+		t.Errorf("ExportDOT() =\n%s\nwant\n%s", buf.String(), wantDOT)
+	}
+}
@@ -0,0 +1,78 @@
+package brick
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RegisterImpl registers the brick Impl like Register, or like RegisterNewer if it implements BrickNewer,
+// and binds it to the interface I. It panics if Impl does not implement I, which catches receiver mistakes
+// (e.g. registering `Impl` whose methods have pointer receivers) at registration instead of injection.
+//
+// The binding resolves GetImpl[I], the interface fields of type I tagged without liveID, e.g. `brick:""`,
+// and the type of the liveIDs of the interface fields that is not given otherwise.
+func RegisterImpl[Impl Brick, I any]() {
+	iface := reflect.TypeOf((*I)(nil)).Elem()
+	if iface.Kind() != reflect.Interface {
+		panic(fmt.Errorf("type %v is not an interface", iface))
+	}
+	implType := reflect.TypeOf((*Impl)(nil)).Elem()
+	if !implType.Implements(iface) {
+		hint := ""
+		if implType.Kind() != reflect.Ptr && reflect.PointerTo(implType).Implements(iface) {
+			hint = fmt.Sprintf(", but *%v does, register the pointer type instead", implType)
+		}
+		panic(fmt.Errorf("brick %v does not implement %v%s", implType, iface, hint))
+	}
+	var instance Impl
+	if _, ok := any(instance).(BrickNewer); ok {
+		registerNewerType(implType)
+	} else {
+		Register[Impl]()
+	}
+	typeID := GetBrickTypeID[Impl]()
+	brickManager.implBindingsLock.Lock()
+	defer brickManager.implBindingsLock.Unlock()
+	if bound, ok := brickManager.implBindings[iface]; ok && bound != typeID {
+		panic(fmt.Errorf("interface %v is already bound to brick(%s)", iface, bound))
+	}
+	brickManager.implBindings[iface] = typeID
+}
+
+// RegisterInterfaceImpl like RegisterImpl, with the interface first, e.g. `RegisterInterfaceImpl[IDatabase, *MySQL]()`.
+// Every interface field of type I resolves its type by the binding, unless the tag, the config
+// or RegisterLiveIDType gives the type of its liveID, e.g. `brick:"mysql"` builds the live mysql of Impl.
+func RegisterInterfaceImpl[I any, Impl Brick]() {
+	RegisterImpl[Impl, I]()
+}
+
+// GetImpl retrieves the brick bound to the interface I by RegisterImpl.
+// If liveID is not provided, it will use the typeID of the bound brick as the LiveID.
+func GetImpl[I any](liveID ...string) I {
+	iface := reflect.TypeOf((*I)(nil)).Elem()
+	typeID, ok := brickManager.getImplBinding(iface)
+	if !ok {
+		panic(fmt.Errorf("no brick is bound to interface %v, please use `brick.RegisterImpl` to bind one", iface))
+	}
+	typ, ok := brickManager.getBrickType(typeID)
+	if !ok {
+		panic(fmt.Errorf("unexpect error, brick type(%s) not found", typeID))
+	}
+	if typ.Kind() != reflect.Ptr && !typ.Implements(iface) {
+		// The methods of I have pointer receivers, get the shared instance instead of a copy.
+		typ = reflect.PointerTo(typ)
+	}
+	brickManager.brickConfigCheckOnce.Do(brickManager.checkConfig)
+	ctx := getBrickInstanceCtx{
+		buildingBrick: make(map[reflect.Type]bool),
+		createUnknown: false,
+	}
+	return convertInstance(getBrickInstance(typ, ctx, liveID...), iface, typeID).Interface().(I)
+}
+
+func (b *BrickManager) getImplBinding(iface reflect.Type) (string, bool) {
+	b.implBindingsLock.RLock()
+	defer b.implBindingsLock.RUnlock()
+	typeID, ok := b.implBindings[iface]
+	return typeID, ok
+}
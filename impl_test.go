@@ -0,0 +1,152 @@
+package brick
+
+import (
+	"strings"
+	"testing"
+)
+
+type TestGreeter42 interface {
+	Greet() string
+}
+
+type TestBrick42 struct{}
+
+func (t *TestBrick42) BrickTypeID() string {
+	return "TestBrick42"
+}
+
+func (t *TestBrick42) Greet() string {
+	return "hello"
+}
+
+type TestBrick421 struct {
+	Greeter TestGreeter42 `brick:""`
+}
+
+func (t TestBrick421) BrickTypeID() string {
+	return "TestBrick421"
+}
+
+func TestRegisterImpl(t *testing.T) {
+	RegisterImpl[*TestBrick42, TestGreeter42]()
+	Register[*TestBrick421]()
+
+	if got := GetImpl[TestGreeter42]().Greet(); got != "hello" {
+		t.Errorf("GetImpl().Greet() = %q, want %q", got, "hello")
+	}
+	b := Get[*TestBrick421]()
+	if b.Greeter == nil || b.Greeter != GetImpl[TestGreeter42]() {
+		t.Errorf("Greeter = %v, want the bound brick", b.Greeter)
+	}
+}
+
+type TestGreeter422 interface {
+	Greet422() string
+}
+
+// TestBrick422 has a value receiver BrickTypeID and a pointer receiver interface method.
+type TestBrick422 struct{}
+
+func (t TestBrick422) BrickTypeID() string {
+	return "TestBrick422"
+}
+
+func (t *TestBrick422) Greet422() string {
+	return "hello"
+}
+
+type TestBrick4221 struct {
+	Greeter TestGreeter422 `brick:""`
+}
+
+func (t *TestBrick4221) BrickTypeID() string {
+	return "TestBrick4221"
+}
+
+func TestRegisterImplMixedReceivers(t *testing.T) {
+	RegisterImpl[*TestBrick422, TestGreeter422]()
+	Register[*TestBrick4221]()
+
+	greeter := GetImpl[TestGreeter422]()
+	if got := greeter.Greet422(); got != "hello" {
+		t.Errorf("GetImpl().Greet422() = %q, want %q", got, "hello")
+	}
+	if greeter != GetImpl[TestGreeter422]() {
+		t.Errorf("GetImpl() returns a copy, want the shared brick")
+	}
+	if b := Get[*TestBrick4221](); b.Greeter != greeter {
+		t.Errorf("Greeter = %v, want the bound brick %v", b.Greeter, greeter)
+	}
+}
+
+func TestRegisterImplPanic(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("RegisterImpl did not panic")
+		}
+		if msg := r.(error).Error(); !strings.Contains(msg, "does not implement") {
+			t.Errorf("panic = %q, want a not implement error", msg)
+		}
+	}()
+	// TestBrick421 has no Greet method.
+	RegisterImpl[TestBrick421, TestGreeter42]()
+}
+
+type TestStore96 interface {
+	Store() string
+}
+
+// TestBrick96 is not zero-sized, so that its instances have distinct addresses.
+type TestBrick96 struct {
+	Name string
+}
+
+func (t *TestBrick96) BrickTypeID() string {
+	return "TestBrick96"
+}
+
+func (t *TestBrick96) Store() string {
+	return "default"
+}
+
+type TestBrick962 struct{}
+
+func (t *TestBrick962) BrickTypeID() string {
+	return "TestBrick962"
+}
+
+func (t *TestBrick962) Store() string {
+	return "override"
+}
+
+type TestBrick961 struct {
+	Default TestStore96 `brick:""`
+	// The liveID has no config and no type registered by RegisterLiveIDType.
+	Named    TestStore96 `brick:"TestBrick96 named"`
+	Override TestStore96 `brick:",TestBrick962"`
+}
+
+func (t *TestBrick961) BrickTypeID() string {
+	return "TestBrick961"
+}
+
+func TestRegisterInterfaceImpl(t *testing.T) {
+	RegisterInterfaceImpl[TestStore96, *TestBrick96]()
+	Register[*TestBrick962]()
+	Register[*TestBrick961]()
+	if err := CheckInterfaceBindings(); err != nil && strings.Contains(err.Error(), "TestBrick961") {
+		t.Errorf("CheckInterfaceBindings() = %v, want the fields bound by RegisterInterfaceImpl", err)
+	}
+
+	b := Get[*TestBrick961]()
+	if b.Default != Get[*TestBrick96]() {
+		t.Errorf("Default = %v, want the default live of the bound brick", b.Default)
+	}
+	if named, ok := b.Named.(*TestBrick96); !ok || named == Get[*TestBrick96]() || named != Get[*TestBrick96]("TestBrick96 named") {
+		t.Errorf("Named = %v, want the live TestBrick96 named of the bound brick", b.Named)
+	}
+	if got := b.Override.Store(); got != "override" {
+		t.Errorf("Override.Store() = %s, want the typeID of the tag to take precedence", got)
+	}
+}
@@ -0,0 +1,69 @@
+package brick
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var testBrick56Inits int
+
+type TestBrick56 struct {
+	Dep   *TestBrick561 `brick:""`
+	Ready bool
+}
+
+func (t *TestBrick56) BrickTypeID() string {
+	return "TestBrick56"
+}
+
+func (t *TestBrick56) BrickInit() error {
+	testBrick56Inits++
+	if t.Dep == nil {
+		return errors.New("dependency not injected")
+	}
+	t.Ready = true
+	return nil
+}
+
+type TestBrick561 struct{}
+
+func (t *TestBrick561) BrickTypeID() string {
+	return "TestBrick561"
+}
+
+type TestBrick562 struct{}
+
+func (t *TestBrick562) BrickTypeID() string {
+	return "TestBrick562"
+}
+
+func (t *TestBrick562) BrickInit() error {
+	return errors.New("connection refused")
+}
+
+func TestBrickIniter(t *testing.T) {
+	Register[*TestBrick56]()
+	Register[*TestBrick562]()
+
+	b := Get[*TestBrick56]()
+	Get[*TestBrick56]()
+	if !b.Ready || testBrick56Inits != 1 {
+		t.Errorf("Ready = %v, inits = %d, want BrickInit called once with the dependencies injected", b.Ready, testBrick56Inits)
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("Get did not panic when BrickInit failed")
+		}
+		if msg := r.(error).Error(); !strings.Contains(msg, "liveID(TestBrick562)") || !strings.Contains(msg, "connection refused") {
+			t.Errorf("panic = %q, want the liveID and the init error", msg)
+		}
+		if slices.Contains(BuiltLiveIDs(), "TestBrick562") {
+			t.Error("the brick failing to init is saved")
+		}
+	}()
+	Get[*TestBrick562]()
+}
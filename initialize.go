@@ -0,0 +1,187 @@
+package brick
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DeclareInitDependency declares that the lives of the brick type beforeTypeID are initialized
+// before the lives of afterTypeID by InitializeAll, e.g. when the former registers a global driver the latter uses.
+// The edge is added to the ones of the `brick` tagged fields, which don't need to be declared.
+func DeclareInitDependency(beforeTypeID, afterTypeID string) {
+	brickManager.DeclareInitDependency(beforeTypeID, afterTypeID)
+}
+
+// DeclareInitDependency declares that the lives of beforeTypeID are initialized before the lives of afterTypeID.
+func (b *BrickManager) DeclareInitDependency(beforeTypeID, afterTypeID string) {
+	if beforeTypeID == afterTypeID {
+		panic(fmt.Errorf("brick(%s) can't be initialized before itself", beforeTypeID))
+	}
+	b.initDependenciesLock.Lock()
+	defer b.initDependenciesLock.Unlock()
+	if !slices.Contains(b.initDependencies[afterTypeID], beforeTypeID) {
+		b.initDependencies[afterTypeID] = append(b.initDependencies[afterTypeID], beforeTypeID)
+	}
+}
+
+// InitializeAll builds every live known to the manager (see Dependencies), dependencies first,
+// so that the failures of the wiring surface at startup instead of at the first Get.
+// The lives of the types declared by DeclareInitDependency are built before the lives depending on them.
+// The request-scoped bricks (see RegisterScoped), the disabled types (see Disable) and the disabled lives are skipped.
+// It panics if the dependencies form a cycle, or a brick fails to build.
+func InitializeAll() {
+	initializeAll(nil)
+}
+
+// InitializeAllCtx like InitializeAll, but it returns the error instead of panicking,
+// and gives up when ctx is done, e.g. to bound the startup by a deadline.
+// ctx is passed to NewBrickCtx of the bricks (see BrickNewerCtx). When ctx is done first,
+// the returned error wraps the error of ctx and names the bricks still building (see InFlightBuilds);
+// the builds in progress finish in the background and are cached as usual, the remaining lives are not built.
+func InitializeAllCtx(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- runRecovered(func() { initializeAll(ctx) })
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		building := brickManager.InFlightBuilds()
+		if len(building) == 0 {
+			return ctx.Err()
+		}
+		return fmt.Errorf("initialize bricks: %w, still building: %s", ctx.Err(), strings.Join(building, ", "))
+	}
+}
+
+// initializeAll builds every live in the initialization order, it stops when buildCtx is done.
+func initializeAll(buildCtx context.Context) {
+	brickManager.brickConfigCheckOnce.Do(brickManager.checkConfig)
+	order, types, err := brickManager.initOrder()
+	if err != nil {
+		panic(err)
+	}
+	for _, liveID := range order {
+		if buildCtx != nil && buildCtx.Err() != nil {
+			return
+		}
+		typeID := brickManager.getTypeIDByReflectType(types[liveID])
+		if brickManager.isScoped(typeID) {
+			// A request-scoped brick is built in a Scope.
+			continue
+		}
+		if brickManager.IsDisabled(typeID) {
+			continue
+		}
+		if config, ok := brickManager.getBrickConfig(liveID); ok && config.Disabled {
+			continue
+		}
+		ctx := getBrickInstanceCtx{
+			buildingBrick: make(map[reflect.Type]bool),
+			createUnknown: false,
+			buildCtx:      buildCtx,
+		}
+		getBrickInstance(types[liveID], ctx, liveID)
+	}
+}
+
+// EagerInit like InitializeAll, but it returns the error instead of panicking,
+// so that a server fails fast at startup instead of at the first Get.
+// It must be called after every AddConfigFile and Register call.
+func EagerInit() (err error) {
+	defer recoverError(&err)
+	InitializeAll()
+	return nil
+}
+
+// initOrder returns the liveIDs of the dependency graph sorted topologically, dependencies first,
+// lives without ordering constraint between them are sorted by liveID.
+func (b *BrickManager) initOrder() ([]string, map[string]reflect.Type, error) {
+	graph, types := b.typedDependencyGraph()
+	byTypeID := make(map[string][]string)
+	for liveID, typ := range types {
+		typeID := b.getTypeIDByReflectType(typ)
+		byTypeID[typeID] = append(byTypeID[typeID], liveID)
+	}
+	deps := make(map[string][]string, len(types))
+	for liveID := range types {
+		deps[liveID] = nil
+		for _, dep := range graph[liveID] {
+			// A dependency whose type can't be determined is not built by InitializeAll.
+			if _, ok := types[dep]; ok {
+				deps[liveID] = append(deps[liveID], dep)
+			}
+		}
+	}
+	b.initDependenciesLock.RLock()
+	for afterTypeID, beforeTypeIDs := range b.initDependencies {
+		for _, after := range byTypeID[afterTypeID] {
+			for _, beforeTypeID := range beforeTypeIDs {
+				deps[after] = append(deps[after], byTypeID[beforeTypeID]...)
+			}
+		}
+	}
+	b.initDependenciesLock.RUnlock()
+
+	// Kahn's algorithm, the ready lives are picked in liveID order to keep the result stable.
+	pending := make(map[string]int, len(deps))
+	dependents := make(map[string][]string, len(deps))
+	for liveID, liveDeps := range deps {
+		liveDeps = uniqueStrings(liveDeps)
+		pending[liveID] = len(liveDeps)
+		for _, dep := range liveDeps {
+			dependents[dep] = append(dependents[dep], liveID)
+		}
+	}
+	var ready []string
+	for liveID, n := range pending {
+		if n == 0 {
+			ready = append(ready, liveID)
+		}
+	}
+	order := make([]string, 0, len(pending))
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		liveID := ready[0]
+		ready = ready[1:]
+		order = append(order, liveID)
+		for _, dependent := range dependents[liveID] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+	if len(order) < len(pending) {
+		var cycle []string
+		for liveID, n := range pending {
+			if n > 0 {
+				cycle = append(cycle, liveID)
+			}
+		}
+		sort.Strings(cycle)
+		return nil, nil, fmt.Errorf("the initialization order of the bricks has a cycle among: %s", strings.Join(cycle, ", "))
+	}
+	return order, types, nil
+}
+
+// uniqueStrings returns the distinct strings of s in their first order.
+func uniqueStrings(s []string) []string {
+	seen := make(map[string]bool, len(s))
+	ret := s[:0:0]
+	for _, v := range s {
+		if !seen[v] {
+			seen[v] = true
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,115 @@
+package brick
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type TestBrick47 struct {
+	Dep *TestBrick471 `brick:""`
+}
+
+func (t *TestBrick47) BrickTypeID() string {
+	return "TestBrick47"
+}
+
+type TestBrick471 struct{}
+
+func (t *TestBrick471) BrickTypeID() string {
+	return "TestBrick471"
+}
+
+// TestBrick472 has no field link to the others.
+type TestBrick472 struct{}
+
+func (t *TestBrick472) BrickTypeID() string {
+	return "TestBrick472"
+}
+
+func TestDeclareInitDependency(t *testing.T) {
+	m := NewBrickManager()
+	m.register2("TestBrick47", reflect.TypeOf(&TestBrick47{}))
+	m.register2("TestBrick471", reflect.TypeOf(&TestBrick471{}))
+	m.register2("TestBrick472", reflect.TypeOf(&TestBrick472{}))
+
+	order, _, err := m.initOrder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"TestBrick471", "TestBrick47", "TestBrick472"}; !slices.Equal(order, want) {
+		t.Fatalf("initOrder() = %v, want %v", order, want)
+	}
+
+	m.DeclareInitDependency("TestBrick472", "TestBrick471")
+	order, _, err = m.initOrder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"TestBrick472", "TestBrick471", "TestBrick47"}; !slices.Equal(order, want) {
+		t.Errorf("initOrder() = %v, want %v", order, want)
+	}
+
+	// The declared edges and the field edge form a cycle.
+	m.DeclareInitDependency("TestBrick47", "TestBrick472")
+	if _, _, err = m.initOrder(); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("initOrder() error = %v, want a cycle error", err)
+	}
+}
+
+// testBrick95Release unblocks the construction of TestBrick95.
+var testBrick95Release = make(chan struct{})
+
+type TestBrick95 struct{}
+
+func (t *TestBrick95) BrickTypeID() string {
+	return "TestBrick95"
+}
+
+func (t *TestBrick95) NewBrickCtx(ctx context.Context, jsonConfig []byte) (Brick, error) {
+	// A slow external dependency ignoring ctx.
+	<-testBrick95Release
+	return &TestBrick95{}, nil
+}
+
+type TestBrick951 struct {
+	Slow *TestBrick95 `brick:""`
+}
+
+func (t *TestBrick951) BrickTypeID() string {
+	return "TestBrick951"
+}
+
+func TestInitializeAllCtx(t *testing.T) {
+	// InitializeAll builds every live of the manager, the bricks of the other tests are dropped.
+	Reset()
+	defer Reset()
+	Register[*TestBrick95]()
+	Register[*TestBrick951]()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := InitializeAllCtx(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "still building: TestBrick95") {
+		t.Fatalf("InitializeAllCtx() error = %v, want the deadline naming TestBrick95", err)
+	}
+	if got := InFlightBuilds(); !slices.Equal(got, []string{"TestBrick95"}) {
+		t.Errorf("InFlightBuilds() = %v, want [TestBrick95]", got)
+	}
+
+	// The build in flight finishes in the background, and is shared with the next Get.
+	close(testBrick95Release)
+	if Get[*TestBrick951]().Slow == nil {
+		t.Error("TestBrick951.Slow is not injected")
+	}
+	if got := InFlightBuilds(); len(got) != 0 {
+		t.Errorf("InFlightBuilds() = %v after the builds finished", got)
+	}
+	if err := InitializeAllCtx(context.Background()); err != nil {
+		t.Errorf("InitializeAllCtx() error = %v", err)
+	}
+}
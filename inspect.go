@@ -0,0 +1,185 @@
+package brick
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+)
+
+// BuildOrder returns the liveIDs in the order they were first constructed during the process lifetime.
+// Dependencies are constructed before their dependents.
+func BuildOrder() []string {
+	return brickManager.BuildOrder()
+}
+
+// BuildOrder returns the liveIDs in the order they were first constructed.
+func (b *BrickManager) BuildOrder() []string {
+	b.instancesLock.RLock()
+	defer b.instancesLock.RUnlock()
+	order := make([]string, len(b.buildOrder))
+	copy(order, b.buildOrder)
+	return order
+}
+
+// InstanceGeneration returns the generation of the instance of the liveID, which is 1 once it is built,
+// and increases every time the instance is replaced, by Replace or by a rebuild after ApplyReload.
+// Tools can compare it with the generation captured by a dependent to detect stale references.
+// It returns 0 if the liveID has never been built.
+func InstanceGeneration(liveID string) uint64 {
+	return brickManager.InstanceGeneration(liveID)
+}
+
+// InstanceGeneration returns the generation of the instance of the liveID.
+func (b *BrickManager) InstanceGeneration(liveID string) uint64 {
+	liveID = b.resolveLiveID(liveID)
+	b.instancesLock.RLock()
+	defer b.instancesLock.RUnlock()
+	return b.generations[liveID]
+}
+
+// BuiltLiveIDs returns the sorted liveIDs of the bricks that have been constructed.
+// Deferred bricks whose construction is still pending are not included.
+func BuiltLiveIDs() []string {
+	return brickManager.BuiltLiveIDs()
+}
+
+// BuiltLiveIDs returns the sorted liveIDs of the bricks that have been constructed.
+func (b *BrickManager) BuiltLiveIDs() []string {
+	b.deferredBuildsLock.RLock()
+	pending := make(map[string]bool, len(b.deferredBuilds))
+	for liveID := range b.deferredBuilds {
+		pending[liveID] = true
+	}
+	b.deferredBuildsLock.RUnlock()
+	b.instancesLock.RLock()
+	liveIDs := make([]string, 0, len(b.instances))
+	for liveID := range b.instances {
+		if !pending[liveID] {
+			liveIDs = append(liveIDs, liveID)
+		}
+	}
+	b.instancesLock.RUnlock()
+	sort.Strings(liveIDs)
+	return liveIDs
+}
+
+// builtInstancesOf returns the constructed instances of the typeID, indexed by liveID.
+func (b *BrickManager) builtInstancesOf(typeID string) map[string]reflect.Value {
+	pending := make(map[string]bool)
+	b.deferredBuildsLock.RLock()
+	for liveID := range b.deferredBuilds {
+		pending[liveID] = true
+	}
+	b.deferredBuildsLock.RUnlock()
+	ret := make(map[string]reflect.Value)
+	b.instancesLock.RLock()
+	defer b.instancesLock.RUnlock()
+	for liveID, instance := range b.instances {
+		if !pending[liveID] && b.getTypeIDByReflectType(instance.Type()) == typeID {
+			ret[liveID] = instance
+		}
+	}
+	return ret
+}
+
+// ImplementorsOf returns the sorted typeIDs of the registered bricks implementing the interface I.
+func ImplementorsOf[I any]() []string {
+	iface := reflect.TypeOf((*I)(nil)).Elem()
+	if iface.Kind() != reflect.Interface {
+		panic(fmt.Errorf("type %v is not an interface", iface))
+	}
+	implementors := brickManager.implementors(iface)
+	typeIDs := make([]string, 0, len(implementors))
+	for typeID := range implementors {
+		typeIDs = append(typeIDs, typeID)
+	}
+	sort.Strings(typeIDs)
+	return typeIDs
+}
+
+// implementors returns the registered brick types implementing the interface, indexed by TypeID.
+// A value type implements the interface if its pointer type does, since bricks are stored as pointers.
+func (b *BrickManager) implementors(iface reflect.Type) map[string]reflect.Type {
+	b.brickTypeIDMapLock.RLock()
+	defer b.brickTypeIDMapLock.RUnlock()
+	ret := make(map[string]reflect.Type)
+	for typeID, typ := range b.brickTypeIDMap2 {
+		if typ.Implements(iface) || (typ.Kind() != reflect.Ptr && reflect.PointerTo(typ).Implements(iface)) {
+			ret[typeID] = typ
+		}
+	}
+	return ret
+}
+
+// FieldTagInfo describes a `brick` tagged field of a brick type.
+type FieldTagInfo struct {
+	// Name is the name of the field.
+	Name string
+	// Type is the Go type of the field.
+	Type reflect.Type
+	// Tag is the parsed `brick` tag of the field.
+	Tag BrickTag
+	// IsInterface, IsSlice and IsMap report the kind of the field.
+	IsInterface bool
+	IsSlice     bool
+	IsMap       bool
+}
+
+// FieldTags returns the `brick` tagged fields of T, in the order they are declared.
+func FieldTags[T Brick]() []FieldTagInfo {
+	typ := reflect.TypeOf((*(new(T))))
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+	var infos []FieldTagInfo
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag, ok := field.Tag.Lookup(brickTag)
+		if !ok {
+			continue
+		}
+		infos = append(infos, FieldTagInfo{
+			Name:        field.Name,
+			Type:        field.Type,
+			Tag:         ParseBrickTag(tag),
+			IsInterface: field.Type.Kind() == reflect.Interface,
+			IsSlice:     field.Type.Kind() == reflect.Slice,
+			IsMap:       field.Type.Kind() == reflect.Map,
+		})
+	}
+	return infos
+}
+
+// ConfigsForType returns the configs of every live of the brick type grouped back into the file structure,
+// with the lives sorted by liveID and the env placeholders kept as written, e.g. for admin tooling editing configs.
+// The Lives are empty if the type has no config.
+func ConfigsForType(typeID string) BrickFileConfig {
+	return brickManager.ConfigsForType(typeID)
+}
+
+// ConfigsForType returns the configs of every live of the brick type grouped back into the file structure.
+func (b *BrickManager) ConfigsForType(typeID string) BrickFileConfig {
+	var fileConfig BrickFileConfig
+	fileConfig.MetaData.TypeID = typeID
+	configs := b.getBrickConfigsByTypeID(typeID)
+	// The element type of Lives is anonymous, so the lives are allocated by growing the slice.
+	fileConfig.Lives = slices.Grow(fileConfig.Lives, len(configs))[:len(configs)]
+	for i, config := range configs {
+		live := &fileConfig.Lives[i]
+		live.LiveID = config.LiveID
+		live.Config = copyConfig(config.Config)
+		if config.Weight != 0 {
+			live.Weight = &config.Weight
+		}
+		live.Order = config.Order
+		live.Disabled = config.Disabled
+		if config.noCheck {
+			fileConfig.MetaData.NoCheck = true
+		}
+	}
+	return fileConfig
+}
@@ -0,0 +1,212 @@
+package brick
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+type TestBrick13 struct {
+	T131 *TestBrick131 `brick:""`
+	T132 *TestBrick132 `brick:""`
+}
+
+func (t *TestBrick13) BrickTypeID() string {
+	return "TestBrick13"
+}
+
+type TestBrick131 struct {
+	T132 *TestBrick132 `brick:""`
+}
+
+func (t *TestBrick131) BrickTypeID() string {
+	return "TestBrick131"
+}
+
+type TestBrick132 struct{}
+
+func (t *TestBrick132) BrickTypeID() string {
+	return "TestBrick132"
+}
+
+func TestBuildOrder(t *testing.T) {
+	Register[*TestBrick13]()
+	Get[*TestBrick13]()
+	Get[*TestBrick13]()
+
+	order := BuildOrder()
+	i13 := slices.Index(order, "TestBrick13")
+	i131 := slices.Index(order, "TestBrick131")
+	i132 := slices.Index(order, "TestBrick132")
+	if i13 == -1 || i131 == -1 || i132 == -1 {
+		t.Fatalf("BuildOrder() = %v, missing bricks", order)
+	}
+	if !(i132 < i131 && i131 < i13) {
+		t.Errorf("BuildOrder() = %v, dependencies must come before dependents", order)
+	}
+	if slices.Index(order[i13+1:], "TestBrick13") != -1 {
+		t.Errorf("BuildOrder() = %v, liveID recorded twice", order)
+	}
+}
+
+type TestBrick18 struct {
+	Name   string
+	Clone  *TestBrick132   `brick:"clone:TestBrick18 clone"`
+	Random *TestBrick132   `brick:"random"`
+	Mover  TestMover       `brick:"TestBrick18 mover,TestBrick10"`
+	List   []*TestBrick132 `brick:"a;b"`
+}
+
+func (t *TestBrick18) BrickTypeID() string {
+	return "TestBrick18"
+}
+
+func TestFieldTags(t *testing.T) {
+	want := []FieldTagInfo{
+		{Name: "Clone", Type: reflect.TypeOf(&TestBrick132{}), Tag: BrickTag{LiveID: "TestBrick18 clone", Clone: true}},
+		{Name: "Random", Type: reflect.TypeOf(&TestBrick132{}), Tag: BrickTag{Random: true}},
+		{Name: "Mover", Type: reflect.TypeOf((*TestMover)(nil)).Elem(), Tag: BrickTag{LiveID: "TestBrick18 mover", TypeID: "TestBrick10"}, IsInterface: true},
+		{Name: "List", Type: reflect.TypeOf([]*TestBrick132{}), Tag: BrickTag{LiveIDs: []string{"a", "b"}}, IsSlice: true},
+	}
+	if got := FieldTags[*TestBrick18](); !reflect.DeepEqual(got, want) {
+		t.Errorf("FieldTags() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseBrickTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want BrickTag
+	}{
+		{tag: "", want: BrickTag{}},
+		{tag: "live,type", want: BrickTag{LiveID: "live", TypeID: "type"}},
+		{tag: "clone:", want: BrickTag{Clone: true}},
+		{tag: "group,nonempty", want: BrickTag{Group: true, NonEmpty: true}},
+		{tag: "type,weighted", want: BrickTag{TypeID: "type", Weighted: true}},
+		{tag: "clock,provider", want: BrickTag{LiveID: "clock", Provider: true}},
+		{tag: "cache,optional", want: BrickTag{LiveID: "cache", Optional: true}},
+		{tag: "cache,type,optional", want: BrickTag{LiveID: "cache", TypeID: "type", Optional: true}},
+		{tag: "type,liveids,nodefault", want: BrickTag{TypeID: "type", TypeLiveIDs: true, NoDefault: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := ParseBrickTag(tt.tag); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseBrickTag() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+type TestBrick29Lazy struct{}
+
+func (t *TestBrick29Lazy) BrickTypeID() string {
+	return "TestBrick29Lazy"
+}
+
+type TestBrick29 struct {
+	Lazy *TestBrick29Lazy `brick:",deferred"`
+}
+
+func (t *TestBrick29) BrickTypeID() string {
+	return "TestBrick29"
+}
+
+func TestBuiltLiveIDs(t *testing.T) {
+	Register[*TestBrick29]()
+	Register[*TestBrick29Lazy]()
+	b := Get[*TestBrick29]()
+	built := BuiltLiveIDs()
+	if !slices.Contains(built, "TestBrick29") {
+		t.Errorf("BuiltLiveIDs() = %v, want TestBrick29 built", built)
+	}
+	if slices.Contains(built, "TestBrick29Lazy") {
+		t.Errorf("BuiltLiveIDs() = %v, want the deferred TestBrick29Lazy not built", built)
+	}
+	if !slices.IsSorted(built) {
+		t.Errorf("BuiltLiveIDs() = %v, want sorted", built)
+	}
+	EnsureBuilt(b.Lazy)
+	if built := BuiltLiveIDs(); !slices.Contains(built, "TestBrick29Lazy") {
+		t.Errorf("BuiltLiveIDs() = %v, want TestBrick29Lazy built after EnsureBuilt", built)
+	}
+}
+
+type TestMover39 interface {
+	Move() string
+}
+
+type TestBrick39Car struct{}
+
+func (t *TestBrick39Car) BrickTypeID() string {
+	return "TestBrick39Car"
+}
+
+func (t *TestBrick39Car) Move() string {
+	return "drive"
+}
+
+type TestBrick39Boat struct{}
+
+func (t TestBrick39Boat) BrickTypeID() string {
+	return "TestBrick39Boat"
+}
+
+func (t TestBrick39Boat) Move() string {
+	return "sail"
+}
+
+type TestBrick39House struct{}
+
+func (t *TestBrick39House) BrickTypeID() string {
+	return "TestBrick39House"
+}
+
+func TestImplementorsOf(t *testing.T) {
+	Register[*TestBrick39Car]()
+	Register[TestBrick39Boat]()
+	Register[*TestBrick39House]()
+	want := []string{"TestBrick39Boat", "TestBrick39Car"}
+	if got := ImplementorsOf[TestMover39](); !reflect.DeepEqual(got, want) {
+		t.Errorf("ImplementorsOf() = %v, want %v", got, want)
+	}
+}
+
+func TestConfigsForType(t *testing.T) {
+	m := NewBrickManager()
+	err := m.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick55"},
+		"lives": [
+			{"liveID": "TestBrick55 b", "config": {"host": "${TEST_BRICK55_HOST:b}"}, "weight": 2},
+			{"liveID": "TestBrick55", "config": {"host": "a", "ports": [1, 2]}, "order": 1}
+		]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileConfig := m.ConfigsForType("TestBrick55")
+	if fileConfig.MetaData.TypeID != "TestBrick55" || len(fileConfig.Lives) != 2 {
+		t.Fatalf("ConfigsForType() = %+v, want the 2 lives of TestBrick55", fileConfig)
+	}
+	if fileConfig.Lives[0].LiveID != "TestBrick55" || fileConfig.Lives[1].LiveID != "TestBrick55 b" {
+		t.Errorf("lives = %+v, want sorted by liveID", fileConfig.Lives)
+	}
+	data, err := json.Marshal([]BrickFileConfig{fileConfig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2 := NewBrickManager()
+	if err := m2.addConfigFileJson(data); err != nil {
+		t.Fatalf("add the reconstructed config: %v", err)
+	}
+	if got := m2.ConfigsForType("TestBrick55"); !reflect.DeepEqual(got, fileConfig) {
+		t.Errorf("round trip = %+v, want %+v", got, fileConfig)
+	}
+	if got, _ := m2.getBrickConfig("TestBrick55 b"); got.Weight != 2 || got.Config.(map[string]any)["host"] != "${TEST_BRICK55_HOST:b}" {
+		t.Errorf("config = %+v, want the weight and the placeholder kept", got)
+	}
+	if got := m.ConfigsForType("TestBrick55 none"); len(got.Lives) != 0 {
+		t.Errorf("ConfigsForType() of a type without config = %+v, want no lives", got)
+	}
+}
@@ -0,0 +1,66 @@
+package brick
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Lazy is a brick resolved on the first call of Get instead of when its owner is built,
+// e.g. a field `DB Lazy[*DBConnection]` tagged with `brick:"db"`, the tag is the same as the one of a field of type T.
+// It breaks dependency cycles and defers expensive builds.
+type Lazy[T Brick] struct {
+	get func() T
+}
+
+// Get resolves the brick on the first call and returns the same brick afterwards,
+// it returns the zero value if the field is not injected.
+func (l Lazy[T]) Get() T {
+	if l.get == nil {
+		var zero T
+		return zero
+	}
+	return l.get()
+}
+
+func (l Lazy[T]) lazyElemType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (l *Lazy[T]) setLazy(resolve func() reflect.Value) {
+	get := sync.OnceValue(resolve)
+	l.get = func() T {
+		return get().Interface().(T)
+	}
+}
+
+// lazyBrick is implemented by the instantiations of Lazy.
+type lazyBrick interface {
+	lazyElemType() reflect.Type
+	setLazy(resolve func() reflect.Value)
+}
+
+var lazyBrickInterfaceType = reflect.TypeOf((*lazyBrick)(nil)).Elem()
+
+// lazyElemType returns T if typ is a Lazy[T].
+func lazyElemType(typ reflect.Type) (reflect.Type, bool) {
+	if typ.Kind() != reflect.Struct || !reflect.PointerTo(typ).Implements(lazyBrickInterfaceType) {
+		return nil, false
+	}
+	return reflect.Zero(typ).Interface().(interface{ lazyElemType() reflect.Type }).lazyElemType(), true
+}
+
+// injectLazyBrick sets the Lazy field with a resolver that injects its brick like a field of type T on the first Get,
+// with the options of the build of its owner, so an unknown liveID fails like it does for an eager field.
+func injectLazyBrick(rfValue reflect.Value, typeField reflect.StructField, valueField reflect.Value, tag string, ownerCtx getBrickInstanceCtx) {
+	lazy := valueField.Addr().Interface().(lazyBrick)
+	elemType := lazy.lazyElemType()
+	lazy.setLazy(func() reflect.Value {
+		ctx := getBrickInstanceCtx{
+			buildingBrick: make(map[reflect.Type]bool),
+			createUnknown: ownerCtx.createUnknown,
+		}
+		value := reflect.New(elemType).Elem()
+		injectField(rfValue, typeField, value, tag, nil, ctx)
+		return value
+	})
+}
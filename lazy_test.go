@@ -0,0 +1,73 @@
+package brick
+
+import (
+	"errors"
+	"testing"
+)
+
+type TestBrick62 struct {
+	Dep  Lazy[*TestBrick621] `brick:""`
+	Self Lazy[*TestBrick62]  `brick:""`
+}
+
+func (t *TestBrick62) BrickTypeID() string {
+	return "TestBrick62"
+}
+
+var testBrick621Builds int
+
+type TestBrick621 struct{}
+
+func (t *TestBrick621) BrickTypeID() string {
+	return "TestBrick621"
+}
+
+func (t *TestBrick621) NewBrick(config []byte) Brick {
+	testBrick621Builds++
+	return &TestBrick621{}
+}
+
+func TestLazy(t *testing.T) {
+	Register[*TestBrick62]()
+	b := Get[*TestBrick62]()
+	if testBrick621Builds != 0 {
+		t.Fatalf("the lazy dependency is built %d times before Get, want 0", testBrick621Builds)
+	}
+	dep := b.Dep.Get()
+	if dep == nil || testBrick621Builds != 1 {
+		t.Fatalf("Get() = %v after %d builds, want the dependency built once", dep, testBrick621Builds)
+	}
+	if b.Dep.Get() != dep || testBrick621Builds != 1 {
+		t.Errorf("the second Get() is not cached, %d builds", testBrick621Builds)
+	}
+	if dep != Get[*TestBrick621]() {
+		t.Error("the lazy dependency is not the shared instance")
+	}
+	if b.Self.Get() != b {
+		t.Error("a lazy dependency on itself is not the brick itself")
+	}
+	if (Lazy[*TestBrick621]{}).Get() != nil {
+		t.Error("Get() of a zero Lazy is not nil")
+	}
+}
+
+type TestBrick622 struct {
+	DepName string
+	Dep     Lazy[*TestBrick621] `brick:"from:DepName"`
+}
+
+func (t *TestBrick622) BrickTypeID() string {
+	return "TestBrick622"
+}
+
+func TestLazyUnknownLiveID(t *testing.T) {
+	Register[*TestBrick622]()
+	b := Get[*TestBrick622]()
+	b.DepName = "TestBrick621 typo"
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrUnknownLiveID) {
+			t.Errorf("Get() of a lazy dependency with an unknown liveID panic = %v, want ErrUnknownLiveID", err)
+		}
+	}()
+	b.Dep.Get()
+}
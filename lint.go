@@ -0,0 +1,64 @@
+package brick
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Lint is a static self-check of the registrations, it returns a warning for:
+//   - a type registered under a typeID that differs from what BrickTypeID of a fresh instance returns.
+//   - an interface field whose liveID can't be bound to a type, see CheckInterfaceBindings.
+//   - a liveID registered by RegisterLiveIDType that no registered brick depends on.
+//
+// Nothing is created, Lint can be called in a test to catch wiring mistakes early.
+func Lint() []string {
+	return brickManager.Lint()
+}
+
+// Lint returns the warnings of the static self-check of the registrations, sorted.
+func (b *BrickManager) Lint() []string {
+	var warnings []string
+	b.brickTypeIDMapLock.RLock()
+	for typ, typeID := range b.brickTypeIDMap1 {
+		if brick, ok := newLintBrick(typ); ok && brick.BrickTypeID() != typeID {
+			warnings = append(warnings, fmt.Sprintf("the type %s is registered under typeID(%s), but its BrickTypeID returns %s",
+				typ, typeID, brick.BrickTypeID()))
+		}
+	}
+	b.brickTypeIDMapLock.RUnlock()
+
+	for _, err := range b.interfaceBindingErrors() {
+		warnings = append(warnings, err.Error())
+	}
+
+	graph := b.dependencyGraph()
+	used := make(map[string]bool)
+	for _, deps := range graph {
+		for _, dep := range deps {
+			used[dep] = true
+		}
+	}
+	b.liveIDTypeMapLock.RLock()
+	for liveID, typ := range b.liveIDTypeMap {
+		if !used[liveID] {
+			warnings = append(warnings, fmt.Sprintf("the type %s of liveID(%s) is registered, but no registered brick depends on it", typ, liveID))
+		}
+	}
+	b.liveIDTypeMapLock.RUnlock()
+
+	sort.Strings(warnings)
+	return warnings
+}
+
+// newLintBrick returns a fresh instance of the registered type.
+func newLintBrick(typ reflect.Type) (Brick, bool) {
+	var instance reflect.Value
+	if typ.Kind() == reflect.Ptr {
+		instance = reflect.New(typ.Elem())
+	} else {
+		instance = reflect.New(typ).Elem()
+	}
+	brick, ok := instance.Interface().(Brick)
+	return brick, ok
+}
@@ -0,0 +1,63 @@
+package brick
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type TestBrick61 struct{}
+
+func (t *TestBrick61) BrickTypeID() string {
+	return "TestBrick61"
+}
+
+type TestBrick611 struct{}
+
+func (t *TestBrick611) BrickTypeID() string {
+	return "TestBrick611"
+}
+
+type testLintDep interface {
+	BrickTypeID() string
+}
+
+type TestBrick612 struct {
+	Used    testLintDep `brick:"used61"`
+	Unbound testLintDep `brick:"unbound61"`
+}
+
+func (t *TestBrick612) BrickTypeID() string {
+	return "TestBrick612"
+}
+
+func TestLint(t *testing.T) {
+	m := NewBrickManager()
+	if warnings := m.Lint(); len(warnings) != 0 {
+		t.Fatalf("Lint() of an empty manager = %q, want no warning", warnings)
+	}
+
+	m.register2("TestBrick61", reflect.TypeOf(&TestBrick61{}))
+	m.register2("TestBrick61x", reflect.TypeOf(&TestBrick611{}))
+	m.register2("TestBrick612", reflect.TypeOf(&TestBrick612{}))
+	m.RegisterLiveIDType("used61", reflect.TypeOf(&TestBrick61{}))
+	m.RegisterLiveIDType("unused61", reflect.TypeOf(&TestBrick61{}))
+
+	warnings := m.Lint()
+	for _, want := range []string{
+		"*brick.TestBrick611 is registered under typeID(TestBrick61x), but its BrickTypeID returns TestBrick611",
+		"field Unbound of brick(TestBrick612) depends on liveID(unbound61)",
+		"liveID(unused61) is registered, but no registered brick depends on it",
+	} {
+		found := false
+		for _, warning := range warnings {
+			found = found || strings.Contains(warning, want)
+		}
+		if !found {
+			t.Errorf("Lint() = %q, want a warning containing %q", warnings, want)
+		}
+	}
+	if len(warnings) != 3 {
+		t.Errorf("Lint() returned %d warnings, want 3: %q", len(warnings), warnings)
+	}
+}
@@ -0,0 +1,99 @@
+package brick
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+type TestBrick103 struct{}
+
+func (t *TestBrick103) BrickTypeID() string {
+	return "TestBrick103"
+}
+
+type TestBrick1031 struct {
+	TenantIDs []string `brick:"TestBrick103,liveids"`
+	// Tenants excludes the default live.
+	Tenants []string `brick:"TestBrick103,liveids,nodefault"`
+}
+
+func (t *TestBrick1031) BrickTypeID() string {
+	return "TestBrick1031"
+}
+
+func TestLiveIDsTag(t *testing.T) {
+	Register[*TestBrick103]()
+	Register[*TestBrick1031]()
+	for _, liveID := range []string{"TestBrick103", "TestBrick103 c", "TestBrick103 a", "TestBrick103 b"} {
+		if err := AddLive("TestBrick103", liveID, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b := Get[*TestBrick1031]()
+	if want := []string{"TestBrick103", "TestBrick103 a", "TestBrick103 b", "TestBrick103 c"}; !slices.Equal(b.TenantIDs, want) {
+		t.Errorf("TenantIDs = %q, want %q", b.TenantIDs, want)
+	}
+	if want := []string{"TestBrick103 a", "TestBrick103 b", "TestBrick103 c"}; !slices.Equal(b.Tenants, want) {
+		t.Errorf("Tenants = %q, want %q", b.Tenants, want)
+	}
+	if got := BuiltLiveIDs(); slices.Contains(got, "TestBrick103 a") {
+		t.Error("the lives of the liveIDs are built")
+	}
+	if tag := ParseBrickTag("TestBrick103,liveids"); !tag.TypeLiveIDs || tag.TypeID != "TestBrick103" || tag.LiveID != "" {
+		t.Errorf("ParseBrickTag() = %+v", tag)
+	}
+}
+
+type TestBrick1032 struct {
+	Name string `json:"name"`
+}
+
+func (t *TestBrick1032) BrickTypeID() string {
+	return "TestBrick1032"
+}
+
+func (t *TestBrick1032) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick1032{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+type TestBrick1033 struct {
+	Clone *TestBrick1032 `brick:"clone:TestBrick1032 a"`
+}
+
+func (t *TestBrick1033) BrickTypeID() string {
+	return "TestBrick1033"
+}
+
+type TestBrick1034 struct {
+	Tenants []string `brick:"TestBrick1032,liveids"`
+}
+
+func (t *TestBrick1034) BrickTypeID() string {
+	return "TestBrick1034"
+}
+
+func TestLiveIDsTagExcludesClones(t *testing.T) {
+	Register[*TestBrick1033]()
+	Register[*TestBrick1034]()
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick1032"},
+		"lives": [{"liveID": "TestBrick1032", "config": {}}, {"liveID": "TestBrick1032 a", "config": {"name": "a"}}]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Get[*TestBrick1033]().Clone.Name != "a" {
+		t.Fatal("the clone does not have the config of the cloned live")
+	}
+	CloneConfig[*TestBrick1032]("TestBrick1032 a")
+
+	if got, want := Get[*TestBrick1034]().Tenants, []string{"TestBrick1032", "TestBrick1032 a"}; !slices.Equal(got, want) {
+		t.Errorf("Tenants = %q, want %q without the clones", got, want)
+	}
+}
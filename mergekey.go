@@ -0,0 +1,68 @@
+package brick
+
+import (
+	"maps"
+	"reflect"
+)
+
+// SetConfigMergeKey sets the identity key of the objects of an array in the config of a brick type.
+// When configs are merged (the shared config under a live, the layers of AddConfigDir),
+// the array at the path is merged element by element instead of being replaced:
+// an object of the override is merged into the object of the base with the same key, or appended if there is none.
+//
+// The path is dot-separated from the root of the live config, e.g. `SetConfigMergeKey("Router", "server.routes", "name")`,
+// the objects of the array are at the same path, e.g. `server.routes.handlers` is the field handlers of each route.
+func SetConfigMergeKey(typeID string, path string, key string) {
+	brickManager.SetConfigMergeKey(typeID, path, key)
+}
+
+// SetConfigMergeKey sets the identity key of the objects of an array in the config of a brick type.
+func (b *BrickManager) SetConfigMergeKey(typeID string, path string, key string) {
+	b.configMergeKeysLock.Lock()
+	defer b.configMergeKeysLock.Unlock()
+	if b.configMergeKeys[typeID] == nil {
+		b.configMergeKeys[typeID] = make(map[string]string)
+	}
+	b.configMergeKeys[typeID][path] = key
+}
+
+// getConfigMergeKeys returns a copy of the merge keys of the brick type, indexed by path.
+func (b *BrickManager) getConfigMergeKeys(typeID string) map[string]string {
+	b.configMergeKeysLock.RLock()
+	defer b.configMergeKeysLock.RUnlock()
+	return maps.Clone(b.configMergeKeys[typeID])
+}
+
+// mergeKeyedList merges the objects of override into the objects of base with the same key, and appends the others.
+// The elements without the key are appended.
+func mergeKeyedList(base []any, override []any, key string, path string, mergeKeys map[string]string) []any {
+	merged := make([]any, len(base), len(base)+len(override))
+	copy(merged, base)
+	for _, elem := range override {
+		i := -1
+		if id, ok := keyOf(elem, key); ok {
+			for j, baseElem := range merged {
+				if baseID, ok := keyOf(baseElem, key); ok && reflect.DeepEqual(id, baseID) {
+					i = j
+					break
+				}
+			}
+		}
+		if i < 0 {
+			merged = append(merged, elem)
+			continue
+		}
+		merged[i] = mergeConfigAt(merged[i], elem, path, mergeKeys)
+	}
+	return merged
+}
+
+// keyOf returns the value of the key of an object.
+func keyOf(elem any, key string) (any, bool) {
+	m, ok := elem.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	id, ok := m[key]
+	return id, ok && id != nil
+}
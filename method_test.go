@@ -0,0 +1,66 @@
+package brick
+
+import (
+	"strings"
+	"testing"
+)
+
+type TestBrick89Host struct{}
+
+func (t *TestBrick89Host) BrickTypeID() string {
+	return "TestBrick89Host"
+}
+
+type TestBrick89Port struct{}
+
+func (t *TestBrick89Port) BrickTypeID() string {
+	return "TestBrick89Port"
+}
+
+type TestBrick89 struct {
+	// The method field is declared before its dependencies, it is still computed after them.
+	Addr string           `brick:"method:BuildAddr"`
+	Host *TestBrick89Host `brick:""`
+	Port *TestBrick89Port `brick:""`
+}
+
+func (t *TestBrick89) BrickTypeID() string {
+	return "TestBrick89"
+}
+
+func (t *TestBrick89) BuildAddr() string {
+	if t.Host == nil || t.Port == nil {
+		return "not injected"
+	}
+	return "localhost:8080"
+}
+
+type TestBrick891 struct {
+	Addr string `brick:"method:BuildAddr"`
+}
+
+func (t *TestBrick891) BrickTypeID() string {
+	return "TestBrick891"
+}
+
+func (t *TestBrick891) BuildAddr() int {
+	return 8080
+}
+
+func TestMethodTag(t *testing.T) {
+	Register[*TestBrick89]()
+	if got := Get[*TestBrick89]().Addr; got != "localhost:8080" {
+		t.Errorf("Addr = %s, want the result of BuildAddr on the injected brick", got)
+	}
+	if got := ParseBrickTag("method:BuildAddr").Method; got != "BuildAddr" {
+		t.Errorf("ParseBrickTag().Method = %s, want BuildAddr", got)
+	}
+
+	defer func() {
+		err, _ := recover().(error)
+		if err == nil || !strings.Contains(err.Error(), "the method BuildAddr of field Addr in brick.TestBrick891 must return string") {
+			t.Errorf("Register() panic = %v, want the method type error", err)
+		}
+	}()
+	Register[*TestBrick891]()
+}
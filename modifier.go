@@ -0,0 +1,87 @@
+package brick
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// InjectContext describes the field processed by a tag modifier.
+type InjectContext struct {
+	// LiveID is the liveID of the brick owning the field.
+	LiveID string
+	// Brick is the struct value of the brick owning the field.
+	Brick reflect.Value
+	// Field is the `brick` tagged field.
+	Field reflect.StructField
+	// Tag is the value of the `brick` tag of the field, including the modifiers.
+	Tag string
+}
+
+// tagModifier is a `name:arg` component of a `brick` tag, e.g. `brick:"liveID,metric:requests"`.
+type tagModifier struct {
+	name string
+	arg  string
+}
+
+// RegisterTagModifier registers a handler of the tag modifier name, so users can add their own tag behaviors,
+// e.g. a `metric:` modifier wrapping the injected value: `brick:"liveID,metric:requests"`.
+//
+// A modifier is a `name:arg` component of the tag after the first one. The built-in grammar takes precedence:
+// the modifiers are removed before the tag is parsed, and their handlers run after the built-in injection of the field,
+// in the order of the tag, so field holds the injected value. A handler error, or a modifier without handler, panics.
+func RegisterTagModifier(name string, handler func(ctx InjectContext, field reflect.Value, arg string) error) {
+	brickManager.RegisterTagModifier(name, handler)
+}
+
+// RegisterTagModifier registers a handler of the tag modifier name.
+func (b *BrickManager) RegisterTagModifier(name string, handler func(ctx InjectContext, field reflect.Value, arg string) error) {
+	if name == "" || strings.ContainsAny(name, ",:;") {
+		panic(fmt.Errorf("invalid tag modifier name(%s)", name))
+	}
+	if name == "clone" || name == "deepclone" || name == strings.TrimSuffix(fromTagPrefix, ":") || name == strings.TrimSuffix(methodTagPrefix, ":") {
+		panic(fmt.Errorf("tag modifier name(%s) is reserved", name))
+	}
+	b.tagModifiersLock.Lock()
+	defer b.tagModifiersLock.Unlock()
+	if _, ok := b.tagModifiers[name]; ok {
+		panic(fmt.Errorf("tag modifier(%s) already registered", name))
+	}
+	b.tagModifiers[name] = handler
+}
+
+// splitTagModifiers removes the modifiers from the tag, and returns them in the order of the tag.
+func splitTagModifiers(tag string) (string, []tagModifier) {
+	if !strings.Contains(tag, ":") {
+		return tag, nil
+	}
+	components := strings.Split(tag, ",")
+	base := components[:1]
+	var modifiers []tagModifier
+	for _, component := range components[1:] {
+		name, arg, ok := strings.Cut(component, ":")
+		if !ok {
+			base = append(base, component)
+			continue
+		}
+		modifiers = append(modifiers, tagModifier{name: strings.TrimSpace(name), arg: arg})
+	}
+	return strings.Join(base, ","), modifiers
+}
+
+// runTagModifiers runs the handlers of the modifiers of the tag on the injected field.
+func runTagModifiers(ctx InjectContext, field reflect.Value) {
+	_, modifiers := splitTagModifiers(ctx.Tag)
+	for _, modifier := range modifiers {
+		brickManager.tagModifiersLock.RLock()
+		handler, ok := brickManager.tagModifiers[modifier.name]
+		brickManager.tagModifiersLock.RUnlock()
+		if !ok {
+			panic(fmt.Errorf("tag modifier(%s) of field %s in %s is not registered, please use `brick.RegisterTagModifier` to register it",
+				modifier.name, ctx.Field.Name, ctx.Brick.Type()))
+		}
+		if err := handler(ctx, field, modifier.arg); err != nil {
+			panic(fmt.Errorf("tag modifier(%s) of field %s in %s: %w", modifier.name, ctx.Field.Name, ctx.Brick.Type(), err))
+		}
+	}
+}
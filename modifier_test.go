@@ -0,0 +1,45 @@
+package brick
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+type TestBrick53 struct{}
+
+func (t *TestBrick53) BrickTypeID() string {
+	return "TestBrick53"
+}
+
+type TestBrick531 struct {
+	Plain    *TestBrick53 `brick:""`
+	Recorded *TestBrick53 `brick:"TestBrick53 recorded,record:a,record:b"`
+}
+
+func (t *TestBrick531) BrickTypeID() string {
+	return "TestBrick531"
+}
+
+func TestRegisterTagModifier(t *testing.T) {
+	var processed []string
+	RegisterTagModifier("record", func(ctx InjectContext, field reflect.Value, arg string) error {
+		if field.IsNil() {
+			t.Errorf("field %s is not injected before the modifier", ctx.Field.Name)
+		}
+		processed = append(processed, ctx.LiveID+"."+ctx.Field.Name+":"+arg)
+		return nil
+	})
+	Register[*TestBrick531]()
+
+	b := Get[*TestBrick531]()
+	if want := []string{"TestBrick531.Recorded:a", "TestBrick531.Recorded:b"}; !slices.Equal(processed, want) {
+		t.Errorf("processed = %v, want %v", processed, want)
+	}
+	if b.Recorded != Get[*TestBrick53]("TestBrick53 recorded") {
+		t.Errorf("Recorded is not the live of the tag without modifiers")
+	}
+	if tag := ParseBrickTag("TestBrick53 recorded,record:a"); tag.LiveID != "TestBrick53 recorded" || tag.TypeID != "" {
+		t.Errorf("ParseBrickTag() = %+v, want the modifiers removed", tag)
+	}
+}
@@ -0,0 +1,85 @@
+package brick
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"reflect"
+)
+
+// SetMutationDetection sets whether the shared instances are checked for mutations, as a debugging aid.
+// When it is on, the exported fields of an instance are hashed when the instance is built, and every Get
+// returning the shared instance compares them with the hash, logging a warning if they changed, e.g. when
+// a holder modifies a singleton instead of a clone. The `brick` tagged fields are not hashed, since Replace
+// may re-wire them. It is a heuristic: the values behind pointers, maps and slices held by the fields are
+// compared by address, and hashing every Get is costly, so keep it off in production.
+//
+// The fields are read without the lock of the instance, so a holder writing them while another goroutine
+// calls Get is a data race reported by the race detector. Turn it on only where the instances are not written concurrently.
+func SetMutationDetection(detect bool) {
+	brickManager.mutationDetection.Store(detect)
+}
+
+// recordInstanceHash saves the hash of the exported fields of the instance of the liveID.
+func (b *BrickManager) recordInstanceHash(liveID string, instance reflect.Value) {
+	if !b.mutationDetection.Load() {
+		return
+	}
+	hash, ok := instanceHash(instance)
+	if !ok {
+		return
+	}
+	b.instanceHashesLock.Lock()
+	defer b.instanceHashesLock.Unlock()
+	b.instanceHashes[liveID] = hash
+}
+
+// checkMutation logs a warning if the exported fields of the instance of the liveID changed since their hash was saved.
+// The new hash is saved, so a mutation is reported once.
+func (b *BrickManager) checkMutation(liveID string, instance reflect.Value) {
+	if !b.mutationDetection.Load() {
+		return
+	}
+	hash, ok := instanceHash(instance)
+	if !ok {
+		return
+	}
+	b.instanceHashesLock.Lock()
+	old, recorded := b.instanceHashes[liveID]
+	b.instanceHashes[liveID] = hash
+	b.instanceHashesLock.Unlock()
+	if recorded && old != hash {
+		log.Printf("brick: the shared brick(%s) of liveID(%s) was mutated after its construction, every holder sees the change",
+			instance.Type(), liveID)
+	}
+}
+
+// instanceHash returns the hash of the exported fields of a struct instance, without the `brick` tagged fields.
+func instanceHash(instance reflect.Value) (uint64, bool) {
+	for instance.Kind() == reflect.Ptr || instance.Kind() == reflect.Interface {
+		if instance.IsNil() {
+			return 0, false
+		}
+		instance = instance.Elem()
+	}
+	if instance.Kind() != reflect.Struct {
+		return 0, false
+	}
+	h := fnv.New64a()
+	typ := instance.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if _, ok := field.Tag.Lookup(brickTag); ok || !field.IsExported() {
+			continue
+		}
+		value := instance.Field(i)
+		switch value.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			// Only the address is hashed, reading the referenced values would race with their writers.
+			fmt.Fprintf(h, "%s=%x;", field.Name, value.Pointer())
+		default:
+			fmt.Fprintf(h, "%s=%#v;", field.Name, value.Interface())
+		}
+	}
+	return h.Sum64(), true
+}
@@ -0,0 +1,43 @@
+package brick
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+type TestBrick91 struct {
+	Limit int
+	Tags  map[string]string
+}
+
+func (t *TestBrick91) BrickTypeID() string {
+	return "TestBrick91"
+}
+
+func TestMutationDetection(t *testing.T) {
+	Register[*TestBrick91]()
+	SetMutationDetection(true)
+	t.Cleanup(func() { SetMutationDetection(false) })
+	var buf bytes.Buffer
+	output := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(output) })
+
+	Get[*TestBrick91]()
+	Get[*TestBrick91]()
+	if buf.Len() != 0 {
+		t.Errorf("unexpected warning without a mutation: %s", buf.String())
+	}
+	Get[*TestBrick91]().Limit = 10
+	Get[*TestBrick91]()
+	if !strings.Contains(buf.String(), "the shared brick(*brick.TestBrick91) of liveID(TestBrick91) was mutated") {
+		t.Errorf("warning = %q, want the mutation of TestBrick91 reported", buf.String())
+	}
+	buf.Reset()
+	Get[*TestBrick91]()
+	if buf.Len() != 0 {
+		t.Errorf("the mutation is reported twice: %s", buf.String())
+	}
+}
@@ -0,0 +1,126 @@
+package brick
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Lives can be namespaced by a prefix of their liveID, e.g. `tenantA/db` and `tenantB/db`,
+// so that the lives of a namespace are managed as a group by the prefix operations below.
+// The prefix is matched as is, include the separator to avoid matching `tenantAB/db` with `tenantA`.
+
+// LiveIDsWithPrefix returns the sorted liveIDs having the prefix, among the configured and the built lives.
+func LiveIDsWithPrefix(prefix string) []string {
+	return brickManager.LiveIDsWithPrefix(prefix)
+}
+
+// ShutdownPrefix like Shutdown, but it only closes the built bricks whose liveID has the prefix,
+// in reverse build order, and it doesn't run the cleanups of AddCleanup.
+// The instances of the prefix are dropped once closed, so the next Get of their liveIDs builds new ones.
+func ShutdownPrefix(ctx context.Context, prefix string) error {
+	return brickManager.ShutdownPrefix(ctx, prefix)
+}
+
+// ReloadPrefix reloads the lives whose liveID has the prefix from the config files backing them (see ReloadConfigFile),
+// the other lives of the files keep their configuration. Every file is staged before anything is applied,
+// so an invalid file rejects the whole reload. The built instances of the prefix are then closed and dropped
+// like ShutdownPrefix, so they are rebuilt with the new configuration at the next Get.
+// An instance whose Close is not done when ctx is done is kept with its old configuration.
+func ReloadPrefix(ctx context.Context, prefix string) error {
+	return brickManager.ReloadPrefix(ctx, prefix)
+}
+
+// LiveIDsWithPrefix returns the sorted liveIDs having the prefix, among the configured and the built lives.
+func (b *BrickManager) LiveIDsWithPrefix(prefix string) []string {
+	liveIDs := make(map[string]bool)
+	b.brickConfigLock.RLock()
+	for liveID := range b.brickConfigs {
+		if strings.HasPrefix(liveID, prefix) {
+			liveIDs[liveID] = true
+		}
+	}
+	b.brickConfigLock.RUnlock()
+	for _, liveID := range b.BuiltLiveIDs() {
+		if strings.HasPrefix(liveID, prefix) {
+			liveIDs[liveID] = true
+		}
+	}
+	ret := make([]string, 0, len(liveIDs))
+	for liveID := range liveIDs {
+		ret = append(ret, liveID)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// ShutdownPrefix closes the built bricks whose liveID has the prefix, in reverse build order.
+func (b *BrickManager) ShutdownPrefix(ctx context.Context, prefix string) error {
+	b.shutdownLock.Lock()
+	defer b.shutdownLock.Unlock()
+	var order []string
+	for _, liveID := range b.BuildOrder() {
+		if strings.HasPrefix(liveID, prefix) {
+			order = append(order, liveID)
+		}
+	}
+	errs := b.closeBricks(ctx, order, 0)
+	for _, liveID := range order {
+		instance, ok := b.getBrickFromExist(liveID)
+		if !ok {
+			continue
+		}
+		if _, _, isCloser := b.getBrickCloser(liveID); isCloser {
+			if _, closed := b.closedBricks[closedBrickKey{ptr: instance.Pointer(), typ: instance.Type()}]; !closed {
+				// ctx is done before the brick is closed.
+				continue
+			}
+		}
+		b.deleteBrickInstance(liveID, instance)
+	}
+	return errors.Join(errs...)
+}
+
+// ReloadPrefix reloads the lives of the prefix from their config files, and closes and drops the built instances of the prefix.
+func (b *BrickManager) ReloadPrefix(ctx context.Context, prefix string) error {
+	paths := make(map[string]bool)
+	b.brickConfigLock.RLock()
+	for liveID, config := range b.brickConfigs {
+		if strings.HasPrefix(liveID, prefix) && config.filePath != "" {
+			paths[config.filePath] = true
+		}
+	}
+	b.brickConfigLock.RUnlock()
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	var stagedFiles []stagedReload
+	for _, path := range sorted {
+		staged, err := b.stageReload(path)
+		if err != nil {
+			return fmt.Errorf("reload config file(%s): %w", path, err)
+		}
+		stagedFiles = append(stagedFiles, newStagedReload(path, withPrefix(staged.oldConfigs, prefix), withPrefix(staged.configs, prefix)))
+	}
+	for _, staged := range stagedFiles {
+		b.applyReload(staged)
+		b.emitReloadEvent(ReloadEvent{FilePath: staged.path, ChangedLiveIDs: staged.changed})
+	}
+	return b.ShutdownPrefix(ctx, prefix)
+}
+
+// withPrefix returns the configurations whose liveID has the prefix.
+func withPrefix(configs map[string]BrickConfig, prefix string) map[string]BrickConfig {
+	ret := make(map[string]BrickConfig)
+	for liveID, config := range configs {
+		if strings.HasPrefix(liveID, prefix) {
+			ret[liveID] = config
+		}
+	}
+	return ret
+}
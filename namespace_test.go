@@ -0,0 +1,87 @@
+package brick
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+var testBrick87Closed []string
+
+type TestBrick87 struct {
+	BrickBase[*TestBrick87]
+	Name string `json:"name"`
+}
+
+func (t *TestBrick87) BrickTypeID() string {
+	return "TestBrick87"
+}
+
+func (t *TestBrick87) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick87{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func (t *TestBrick87) Close() error {
+	testBrick87Closed = append(testBrick87Closed, t.BrickLiveID())
+	return nil
+}
+
+func TestPrefixOperations(t *testing.T) {
+	RegisterNewer[*TestBrick87]()
+	path := filepath.Join(t.TempDir(), "tenants.json")
+	writeConfig := func(name string) {
+		content := `[{"metaData": {"typeID": "TestBrick87"}, "lives": [
+			{"liveID": "TestBrick87", "config": {"name": "default"}},
+			{"liveID": "tenantA/db", "config": {"name": "` + name + `"}},
+			{"liveID": "tenantA/cache", "config": {"name": "a cache"}},
+			{"liveID": "tenantB/db", "config": {"name": "` + name + `"}}
+		]}]`
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig("db")
+	if err := AddConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := LiveIDsWithPrefix("tenantA/"), []string{"tenantA/cache", "tenantA/db"}; !slices.Equal(got, want) {
+		t.Errorf("LiveIDsWithPrefix(tenantA/) = %v, want %v", got, want)
+	}
+	for _, liveID := range []string{"tenantA/db", "tenantA/cache", "tenantB/db"} {
+		Get[*TestBrick87](liveID)
+	}
+	tenantB := Get[*TestBrick87]("tenantB/db")
+
+	if err := ShutdownPrefix(context.Background(), "tenantA/"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"tenantA/cache", "tenantA/db"}; !slices.Equal(testBrick87Closed, want) {
+		t.Errorf("closed = %v, want %v", testBrick87Closed, want)
+	}
+	built := BuiltLiveIDs()
+	if slices.Contains(built, "tenantA/db") || !slices.Contains(built, "tenantB/db") {
+		t.Errorf("built liveIDs = %v, want only the tenantA instances dropped", built)
+	}
+
+	writeConfig("db v2")
+	testBrick87Closed = nil
+	if err := ReloadPrefix(context.Background(), "tenantB/"); err != nil {
+		t.Fatal(err)
+	}
+	if got := Get[*TestBrick87]("tenantB/db"); got == tenantB || got.Name != "db v2" {
+		t.Errorf("Get(tenantB/db).Name = %s, want a new instance with the reloaded config", got.Name)
+	}
+	if want := []string{"tenantB/db"}; !slices.Equal(testBrick87Closed, want) {
+		t.Errorf("closed = %v, want ReloadPrefix to close the instances of the prefix", testBrick87Closed)
+	}
+	if got := Get[*TestBrick87]("tenantA/db"); got.Name != "db" {
+		t.Errorf("Get(tenantA/db).Name = %s, want the lives of another prefix not reloaded", got.Name)
+	}
+}
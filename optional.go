@@ -0,0 +1,64 @@
+package brick
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// splitOptionalTag removes the `optional` option from the tag, e.g. `brick:"cache,optional"` or `brick:"cache,RedisCache,optional"`,
+// and reports whether it was present.
+func splitOptionalTag(tag string) (string, bool) {
+	components := strings.Split(tag, ",")
+	for i := 1; i < len(components); i++ {
+		if components[i] == optionalTag {
+			return strings.Join(append(components[:i:i], components[i+1:]...), ","), true
+		}
+	}
+	return tag, false
+}
+
+// checkOptionalTag returns an error if the tag, without its `optional` option, has an option contradicting it.
+func checkOptionalTag(tag string) error {
+	if _, typeID, _, _ := brickManager.parseTag(tag); typeID == nonemptyTag {
+		return fmt.Errorf("the tag options %s and %s are mutually exclusive", optionalTag, nonemptyTag)
+	}
+	return nil
+}
+
+// isMissingDependency reports whether the panic value is the failure of a dependency that is not there,
+// i.e. its type is not registered or disabled, its liveID is unknown or disabled, or the type of its liveID can't be determined.
+// Any other failure, e.g. of NewBrick, still fails the injection of an optional field.
+func isMissingDependency(r any) bool {
+	err, ok := r.(error)
+	return ok && (errors.Is(err, ErrTypeNotRegistered) || errors.Is(err, ErrUnknownLiveID) ||
+		errors.Is(err, ErrLiveDisabled) || errors.Is(err, ErrBrickDisabled) || errors.Is(err, ErrDependencyNotFound))
+}
+
+// recoverMissingDependency is deferred by the injection of an optional field,
+// it leaves the field zero if the dependency is missing, and re-panics any other failure.
+func recoverMissingDependency(valueField reflect.Value) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if !isMissingDependency(r) {
+		panic(r)
+	}
+	valueField.Set(reflect.Zero(valueField.Type()))
+}
+
+// resolveOptional calls resolve, and returns false instead of panicking if the dependency is missing.
+func resolveOptional(resolve func()) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if !isMissingDependency(r) {
+				panic(r)
+			}
+			ok = false
+		}
+	}()
+	resolve()
+	return true
+}
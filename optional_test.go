@@ -0,0 +1,108 @@
+package brick
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type TestBrick104 struct {
+	Default *TestBrick1044   `brick:",optional"`
+	Items   []*TestBrick1041 `brick:"TestBrick104 a;TestBrick104 missing;TestBrick104 b,optional"`
+	Group   []*TestBrick1041 `brick:"group,optional"`
+}
+
+func (t *TestBrick104) BrickTypeID() string {
+	return "TestBrick104"
+}
+
+type TestBrick1041 struct {
+	Name string `json:"name"`
+}
+
+func (t *TestBrick1041) BrickTypeID() string {
+	return "TestBrick1041"
+}
+
+func (t *TestBrick1041) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick1041{}
+	if config != nil {
+		if err := json.Unmarshal(config, newBrick); err != nil {
+			panic(err)
+		}
+	}
+	return newBrick
+}
+
+type TestBrick1042 struct {
+	Group []*TestBrick1041 `brick:"group,nonempty,optional"`
+}
+
+func (t *TestBrick1042) BrickTypeID() string {
+	return "TestBrick1042"
+}
+
+type TestBrick1043 struct {
+	Bad *TestBrick1045 `brick:",optional"`
+}
+
+func (t *TestBrick1043) BrickTypeID() string {
+	return "TestBrick1043"
+}
+
+type TestBrick1044 struct {
+	N int
+}
+
+func (t *TestBrick1044) BrickTypeID() string {
+	return "TestBrick1044"
+}
+
+type TestBrick1045 struct{}
+
+func (t *TestBrick1045) BrickTypeID() string {
+	return "TestBrick1045"
+}
+
+func (t *TestBrick1045) NewBrick(config []byte) Brick {
+	panic(errors.New("TestBrick1045 can't be built"))
+}
+
+func TestOptionalResolution(t *testing.T) {
+	Register[*TestBrick104]()
+	Register[*TestBrick1043]()
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick1041"},
+		"lives": [
+			{"liveID": "TestBrick1041", "config": {"name": "default"}},
+			{"liveID": "TestBrick104 a", "config": {"name": "a"}},
+			{"liveID": "TestBrick104 b", "config": {"name": "b"}}
+		]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := Get[*TestBrick104]()
+	if b.Default == nil || b.Default != Get[*TestBrick1044]() {
+		t.Errorf("Default = %v, want the default live, which is buildable without config", b.Default)
+	}
+	if len(b.Items) != 2 || b.Items[0].Name != "a" || b.Items[1].Name != "b" {
+		t.Errorf("Items = %v, want the lives a and b without the missing one", b.Items)
+	}
+	if len(b.Group) != 3 {
+		t.Errorf("Group = %v, want the 3 configured lives", b.Group)
+	}
+
+	// A dependency failing to build is not a missing one.
+	if _, err := TryGet[*TestBrick1043](); err == nil {
+		t.Error("TryGet() of an optional dependency failing to build succeeded")
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Register() accepts the mutually exclusive options optional and nonempty")
+		}
+	}()
+	Register[*TestBrick1042]()
+}
@@ -0,0 +1,46 @@
+package brick
+
+import "fmt"
+
+// SetParent sets the parent of the package-level manager, e.g. a shared platform container holding the logging
+// and metrics lives, made by NewBrickManager and filled by its AddConfig methods.
+// A live that has neither an instance nor a config in the package-level manager is resolved from the parent
+// if the parent has one, and its instance is built and cached in the parent, so singletons resolved from the parent are shared.
+//
+// The package-level manager shadows its parent: a live with an instance or a config in it is always resolved locally.
+// Brick types, factories and providers are registered globally, so they are shared with the parent.
+// Only the package-level manager resolves through a parent, and only one level: the parent of a parent is not used.
+// Shutdown doesn't close the bricks cached in the parent, call the Shutdown method of the parent to close them.
+// A nil parent removes the parent.
+func SetParent(parent *BrickManager) {
+	if parent == brickManager {
+		panic(fmt.Errorf("the parent of the package-level manager can't be itself"))
+	}
+	brickManager.parentLock.Lock()
+	defer brickManager.parentLock.Unlock()
+	brickManager.parent = parent
+}
+
+func (b *BrickManager) getParent() *BrickManager {
+	b.parentLock.RLock()
+	defer b.parentLock.RUnlock()
+	return b.parent
+}
+
+// ownerOf returns the parent if it has an instance or a config of the liveID and b has none, or b otherwise.
+func (b *BrickManager) ownerOf(liveID string) *BrickManager {
+	parent := b.getParent()
+	if parent == nil || b.hasLive(liveID) || !parent.hasLive(liveID) {
+		return b
+	}
+	return parent
+}
+
+// hasLive reports whether the manager has an instance or a config of the liveID.
+func (b *BrickManager) hasLive(liveID string) bool {
+	if _, ok := b.getBrickFromExist(liveID); ok {
+		return true
+	}
+	_, ok := b.getBrickConfig(liveID)
+	return ok
+}
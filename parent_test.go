@@ -0,0 +1,83 @@
+package brick
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type TestBrick41 struct {
+	Name string `json:"name"`
+}
+
+func (t *TestBrick41) BrickTypeID() string {
+	return "TestBrick41"
+}
+
+func (t *TestBrick41) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick41{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+type TestBrick411 struct {
+	Platform *TestBrick41 `brick:"TestBrick41 platform"`
+}
+
+func (t *TestBrick411) BrickTypeID() string {
+	return "TestBrick411"
+}
+
+func TestSetParent(t *testing.T) {
+	RegisterNewer[*TestBrick41]()
+	Register[*TestBrick411]()
+	parent := NewBrickManager()
+	err := parent.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick41"},
+		"lives": [
+			{"liveID": "TestBrick41", "config": {"name": "parent default"}},
+			{"liveID": "TestBrick41 platform", "config": {"name": "platform"}},
+			{"liveID": "TestBrick41 shadowed", "config": {"name": "parent"}}
+		]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick41"},
+		"lives": [
+			{"liveID": "TestBrick41", "config": {"name": "child default"}},
+			{"liveID": "TestBrick41 shadowed", "config": {"name": "child"}}
+		]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetParent(parent)
+	defer SetParent(nil)
+
+	platform := Get[*TestBrick411]().Platform
+	if platform.Name != "platform" {
+		t.Errorf("Name = %s, want the live of the parent", platform.Name)
+	}
+	if instance, ok := parent.getBrickFromExist("TestBrick41 platform"); !ok || instance.Interface() != platform {
+		t.Errorf("the live resolved from the parent is not cached in the parent")
+	}
+	if Get[*TestBrick41]("TestBrick41 platform") != platform {
+		t.Errorf("the live resolved from the parent is not shared")
+	}
+	if got := Get[*TestBrick41]("TestBrick41 shadowed").Name; got != "child" {
+		t.Errorf("Name = %s, want the child to shadow the parent", got)
+	}
+	if got := Get[*TestBrick41]().Name; got != "child default" {
+		t.Errorf("Name = %s, want the child to shadow the parent", got)
+	}
+
+	defer func() {
+		if err := recover(); err == nil {
+			t.Errorf("expected panic for the package-level manager as its own parent")
+		}
+	}()
+	SetParent(brickManager)
+}
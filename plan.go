@@ -0,0 +1,95 @@
+package brick
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// The sources of the decisions of an injection plan.
+const (
+	// planSourceTag is a liveID written in the tag.
+	planSourceTag = "tag"
+	// planSourceDefault is the default live of the field type.
+	planSourceDefault = "default"
+	// planSourceBinding is the default live of the brick registered for the interface by RegisterImpl.
+	planSourceBinding = "binding"
+	// planSourceUnresolved is an interface dependency whose live or type can't be determined statically.
+	planSourceUnresolved = "unresolved"
+)
+
+// fieldPlan is the injection decision of a `brick` tagged field.
+type fieldPlan struct {
+	Field string `json:"field"`
+	// LiveIDs are the lives injected into the field, after resolving the aliases.
+	LiveIDs []string `json:"liveIDs,omitempty"`
+	// TypeID is the typeID of the injected lives, empty if it is unknown.
+	TypeID    string `json:"typeID,omitempty"`
+	Clone     bool   `json:"clone,omitempty"`
+	Random    bool   `json:"random,omitempty"`
+	Interface bool   `json:"interface,omitempty"`
+	Lazy      bool   `json:"lazy,omitempty"`
+	Optional  bool   `json:"optional,omitempty"`
+	Deferred  bool   `json:"deferred,omitempty"`
+	// Source is how the lives are decided: tag, default, binding, group, all, weighted, selected,
+	// provider, configs, liveids, secret, from, method, random or unresolved.
+	Source string `json:"source"`
+}
+
+// InjectionPlanJSON returns the injection decision of every `brick` tagged field of every registered type,
+// indexed by TypeID, as indented JSON with sorted keys. It is resolved statically from the tags, the configs
+// and the bindings, no brick is built, so the plan can be committed as a golden file to review wiring changes.
+func InjectionPlanJSON() []byte {
+	return brickManager.InjectionPlanJSON()
+}
+
+// InjectionPlanJSON returns the injection decision of every `brick` tagged field of every registered type.
+func (b *BrickManager) InjectionPlanJSON() []byte {
+	b.brickTypeIDMapLock.RLock()
+	types := make(map[string]reflect.Type, len(b.brickTypeIDMap2))
+	for typeID, typ := range b.brickTypeIDMap2 {
+		types[typeID] = typ
+	}
+	b.brickTypeIDMapLock.RUnlock()
+
+	plan := make(map[string][]fieldPlan, len(types))
+	for typeID, typ := range types {
+		plan[typeID] = b.typeInjectionPlan(typ)
+	}
+	// The plan only holds strings and bools, it can't fail to marshal.
+	data, _ := json.MarshalIndent(plan, "", "  ")
+	return data
+}
+
+// typeInjectionPlan returns the injection decisions of the `brick` tagged fields of the type, in declaration order.
+func (b *BrickManager) typeInjectionPlan(typ reflect.Type) []fieldPlan {
+	plans := []fieldPlan{}
+	for _, field := range b.brickFields(typ) {
+		plans = append(plans, b.fieldInjectionPlan(field.field.Name, field.field.Type, field.tag))
+	}
+	return plans
+}
+
+func (b *BrickManager) fieldInjectionPlan(name string, fieldType reflect.Type, tag BrickTag) fieldPlan {
+	deps := b.resolveField(fieldType, tag)
+	plan := fieldPlan{
+		Field:     name,
+		TypeID:    deps.typeID,
+		Clone:     tag.Clone,
+		Random:    tag.Random,
+		Interface: deps.iface,
+		Lazy:      deps.lazy,
+		Optional:  tag.Optional,
+		Deferred:  tag.Deferred,
+		Source:    deps.source,
+	}
+	if tag.Provider {
+		// The provider is not a brick, but the plan names it.
+		plan.LiveIDs = []string{b.resolveLiveID(tag.LiveID)}
+	}
+	for _, edge := range deps.edges {
+		if edge.liveID != "" {
+			plan.LiveIDs = append(plan.LiveIDs, edge.liveID)
+		}
+	}
+	return plan
+}
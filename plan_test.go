@@ -0,0 +1,58 @@
+package brick
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type TestLogger79 interface {
+	Log79()
+}
+
+type TestBrick79 struct{}
+
+func (t *TestBrick79) BrickTypeID() string {
+	return "TestBrick79"
+}
+
+func (t *TestBrick79) Log79() {}
+
+type TestBrick791 struct {
+	Shared *TestBrick79       `brick:""`
+	Clone  *TestBrick79       `brick:"clone:TestBrick79 a"`
+	Random *TestBrick79       `brick:"random"`
+	Bound  TestLogger79       `brick:""`
+	Named  TestLogger79       `brick:"TestBrick79 a"`
+	Lazy   Lazy[*TestBrick79] `brick:"TestBrick79 a"`
+}
+
+func (t *TestBrick791) BrickTypeID() string {
+	return "TestBrick791"
+}
+
+func TestInjectionPlanJSON(t *testing.T) {
+	m := NewBrickManager()
+	m.register2("TestBrick791", reflect.TypeOf(&TestBrick791{}))
+	m.implBindings[reflect.TypeOf((*TestLogger79)(nil)).Elem()] = "TestBrick79"
+	m.RegisterLiveIDType("TestBrick79 a", reflect.TypeOf(&TestBrick79{}))
+
+	var plan map[string][]fieldPlan
+	if err := json.Unmarshal(m.InjectionPlanJSON(), &plan); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]fieldPlan{
+		"TestBrick79": {},
+		"TestBrick791": {
+			{Field: "Shared", LiveIDs: []string{"TestBrick79"}, TypeID: "TestBrick79", Source: "default"},
+			{Field: "Clone", LiveIDs: []string{"TestBrick79 a"}, TypeID: "TestBrick79", Clone: true, Source: "tag"},
+			{Field: "Random", TypeID: "TestBrick79", Random: true, Source: "random"},
+			{Field: "Bound", LiveIDs: []string{"TestBrick79"}, TypeID: "TestBrick79", Interface: true, Source: "binding"},
+			{Field: "Named", LiveIDs: []string{"TestBrick79 a"}, TypeID: "TestBrick79", Interface: true, Source: "tag"},
+			{Field: "Lazy", LiveIDs: []string{"TestBrick79 a"}, TypeID: "TestBrick79", Lazy: true, Source: "tag"},
+		},
+	}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("InjectionPlanJSON() = %s, want %v", m.InjectionPlanJSON(), want)
+	}
+}
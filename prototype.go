@@ -0,0 +1,20 @@
+package brick
+
+// RegisterPrototype like RegisterNewer, but the brick is prototype-scoped: every Get, and every field injecting it,
+// builds a new instance from the config of the liveID instead of sharing one per liveID.
+// The dependencies of a prototype are resolved as usual, the singletons are shared by its instances.
+// The instances are not saved, so Shutdown doesn't close them, the caller owns them.
+func RegisterPrototype[T BrickNewer]() {
+	RegisterNewer[T]()
+	typeID := GetBrickTypeID[T]()
+	brickManager.prototypesLock.Lock()
+	defer brickManager.prototypesLock.Unlock()
+	brickManager.prototypes[typeID] = true
+}
+
+// isPrototype reports whether the brick type of typeID is registered by RegisterPrototype.
+func (b *BrickManager) isPrototype(typeID string) bool {
+	b.prototypesLock.RLock()
+	defer b.prototypesLock.RUnlock()
+	return b.prototypes[typeID]
+}
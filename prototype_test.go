@@ -0,0 +1,64 @@
+package brick
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type TestBrick98 struct {
+	Name string        `json:"name"`
+	Dep  *TestBrick981 `brick:""`
+}
+
+func (t *TestBrick98) BrickTypeID() string {
+	return "TestBrick98"
+}
+
+func (t *TestBrick98) NewBrick(jsonConfig []byte) Brick {
+	newBrick := &TestBrick98{}
+	if len(jsonConfig) > 0 {
+		if err := json.Unmarshal(jsonConfig, newBrick); err != nil {
+			panic(err)
+		}
+	}
+	return newBrick
+}
+
+type TestBrick981 struct {
+	Name string
+}
+
+func (t *TestBrick981) BrickTypeID() string {
+	return "TestBrick981"
+}
+
+type TestBrick982 struct {
+	A *TestBrick98 `brick:""`
+	B *TestBrick98 `brick:""`
+}
+
+func (t *TestBrick982) BrickTypeID() string {
+	return "TestBrick982"
+}
+
+func TestRegisterPrototype(t *testing.T) {
+	RegisterPrototype[*TestBrick98]()
+	Register[*TestBrick982]()
+	if err := AddLive("TestBrick98", "TestBrick98", map[string]any{"name": "proto"}); err != nil {
+		t.Fatal(err)
+	}
+
+	a, b := Get[*TestBrick98](), Get[*TestBrick98]()
+	if a == b {
+		t.Fatal("Get() of a prototype returns the same instance twice")
+	}
+	if a.Name != "proto" || b.Name != "proto" {
+		t.Errorf("Name = %q, %q, want the config of the live", a.Name, b.Name)
+	}
+	if a.Dep == nil || a.Dep != b.Dep {
+		t.Errorf("Dep = %p, %p, want the singleton shared", a.Dep, b.Dep)
+	}
+	if got := Get[*TestBrick982](); got.A == got.B {
+		t.Error("the fields injecting a prototype share one instance")
+	}
+}
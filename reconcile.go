@@ -0,0 +1,58 @@
+package brick
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// WouldChange reports whether building the brick with the candidate config would produce
+// a different config from the one the live instance was built with.
+// Environment variables in the candidate are expanded before comparing, and the order of map keys is ignored.
+//
+// If liveID is empty, the typeID of T is used as the liveID.
+func WouldChange[T BrickNewer](liveID string, candidate any) (bool, error) {
+	typeID := GetBrickTypeID[T]()
+	if liveID == "" {
+		liveID = typeID
+	}
+	return brickManager.wouldChange(typeID, brickManager.resolveLiveID(liveID), candidate)
+}
+
+func (b *BrickManager) wouldChange(typeID string, liveID string, candidate any) (bool, error) {
+	if _, ok := b.getBrickFromExist(liveID); !ok {
+		return false, fmt.Errorf("brick(%s) has not been built", liveID)
+	}
+	if config, ok := b.getBrickConfig(liveID); ok && config.TypeID != typeID {
+		return false, fmt.Errorf("config TypeID mismatch: ID(%s) != ID(%s)", config.TypeID, typeID)
+	}
+	builtConfig, ok := b.getBuiltConfig(liveID)
+	if !ok {
+		return false, fmt.Errorf("brick(%s) was not built from a config", liveID)
+	}
+	candidateConfig, err := marshalBrickConfig(candidate)
+	if err != nil {
+		return false, fmt.Errorf("brick config marshal error: %w", err)
+	}
+	equal, err := jsonEqual(builtConfig, candidateConfig)
+	if err != nil {
+		return false, err
+	}
+	return !equal, nil
+}
+
+// jsonEqual reports whether two json documents are deeply equal, ignoring the order of object keys.
+func jsonEqual(a, b []byte) (bool, error) {
+	var va, vb any
+	if len(a) > 0 {
+		if err := json.Unmarshal(a, &va); err != nil {
+			return false, err
+		}
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &vb); err != nil {
+			return false, err
+		}
+	}
+	return reflect.DeepEqual(va, vb), nil
+}
@@ -0,0 +1,75 @@
+package brick
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type TestBrick9 struct {
+	Host  string            `json:"host"`
+	Ports []int             `json:"ports"`
+	Tags  map[string]string `json:"tags"`
+}
+
+func (t *TestBrick9) BrickTypeID() string {
+	return "TestBrick9"
+}
+
+func (t *TestBrick9) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick9{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+// dropUnregisteredConfigs removes the configs of the bricks that are not registered, left by earlier tests
+// like printService, so that the config check of the first Get doesn't fail on them.
+func dropUnregisteredConfigs() {
+	for liveID, config := range brickManager.snapshotBrickConfigs() {
+		if _, ok := brickManager.getBrickType(config.TypeID); !ok && !config.noCheck {
+			brickManager.deleteBrickConfig(liveID)
+		}
+	}
+}
+
+func TestWouldChange(t *testing.T) {
+	dropUnregisteredConfigs()
+	RegisterNewer[*TestBrick9]()
+	t.Setenv("TEST_BRICK9_HOST", "localhost")
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick9"},
+		"lives": [{"liveID": "TestBrick9", "config": {"host": "${TEST_BRICK9_HOST}", "ports": [1, 2], "tags": {"a": "1", "b": "2"}}}]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WouldChange[*TestBrick9]("", map[string]any{}); err == nil {
+		t.Errorf("expected error for a brick that has not been built")
+	}
+	Get[*TestBrick9]()
+
+	changed, err := WouldChange[*TestBrick9]("", map[string]any{
+		"tags":  map[string]any{"b": "2", "a": "1"},
+		"ports": []any{1, 2},
+		"host":  "${TEST_BRICK9_HOST}",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Errorf("WouldChange() = true for an identical candidate")
+	}
+
+	changed, err = WouldChange[*TestBrick9]("TestBrick9", map[string]any{
+		"tags":  map[string]any{"a": "1", "b": "2"},
+		"ports": []any{2, 1},
+		"host":  "localhost",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Errorf("WouldChange() = false for a differing candidate")
+	}
+}
@@ -1,10 +1,11 @@
 package brick
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 )
 
 // Register registers the brick type and recursively registers its all dependencies.
@@ -48,9 +49,12 @@ func GetBrickTypeID[T Brick]() string {
 // The provided type must implement the BrickNewer interface, which includes the NewBrick method
 // for parsing configurations.
 func RegisterNewer[T BrickNewer]() {
+	registerNewerType(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// registerNewerType registers the brick type implementing BrickNewer like RegisterNewer.
+func registerNewerType(typ reflect.Type) {
 	// Pointer receiver registers pointer type, value receiver registers value type
-	var instance = *new(T)
-	var typ = reflect.TypeOf(instance)
 	var check = func() {
 		baseField, ok := typ.FieldByName("BrickBase")
 		if ok && baseField.Anonymous && baseField.Type.Kind() == reflect.Ptr {
@@ -59,6 +63,7 @@ func RegisterNewer[T BrickNewer]() {
 	}
 	if typ.Kind() != reflect.Ptr {
 		check()
+		instance := reflect.New(typ).Elem().Interface().(BrickNewer)
 		brickManager.register2(instance.BrickTypeID(), typ, instance.NewBrick)
 		return
 	}
@@ -126,12 +131,77 @@ func RegisterLives[T BrickLives]() {
 	param.BrickFactory = brick.NewBrick
 }
 
+// RegisterWithLives like RegisterNewer, but it also registers the lives of the brick with their dependencies,
+// like the BrickLives method, e.g. to wire a third-party brick or to configure the lives in main.
+// The keys of RelyLives must be `brick` tagged fields of T. The lives are added to the ones of BrickLives, if any.
+func RegisterWithLives[T BrickNewer](lives []Live) {
+	RegisterNewer[T]()
+	typeID := GetBrickTypeID[T]()
+	typ, _ := brickManager.getBrickType(typeID)
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	for _, live := range lives {
+		for field := range live.RelyLives {
+			f, ok := typ.FieldByName(field)
+			if _, tagged := f.Tag.Lookup(brickTag); !ok || !tagged {
+				panic(fmt.Errorf("field %s in %s is not a brick component", field, typ))
+			}
+		}
+	}
+	brickManager.brickLivesLock.Lock()
+	brickManager.brickLives[typeID] = append(brickManager.brickLives[typeID], lives...)
+	brickManager.brickLivesLock.Unlock()
+	for _, live := range lives {
+		brickManager.setDeclaredLiveID(live.LiveID)
+		for _, depLive := range live.RelyLives {
+			brickManager.setDeclaredLiveID(depLive)
+		}
+	}
+}
+
 func (b *BrickManager) RegisterLiveIDType(liveID string, reflectType reflect.Type) {
 	b.liveIDTypeMapLock.Lock()
 	defer b.liveIDTypeMapLock.Unlock()
 	b.liveIDTypeMap[liveID] = reflectType
 }
 
+// RegisterProvider registers a provider of non-brick values (a clock, a request ID generator, etc.),
+// which can be injected into fields tagged with `brick:"liveID,provider"`.
+//
+// If singleton is true, the provider is called once and the value is shared by all fields,
+// otherwise the provider is called every time a field is injected.
+func RegisterProvider[T any](liveID string, provider func() T, singleton bool) {
+	fn := func() reflect.Value {
+		return reflect.ValueOf(provider())
+	}
+	if singleton {
+		fn = sync.OnceValue(fn)
+	}
+	brickManager.providersLock.Lock()
+	defer brickManager.providersLock.Unlock()
+	if _, ok := brickManager.providers[liveID]; ok {
+		panic(fmt.Errorf("provider(%s) already registered", liveID))
+	}
+	brickManager.providers[liveID] = fn
+}
+
+// RegisterLiveFactory registers the factory of a single live, e.g. a test double,
+// and declares the liveID. The factory receives the config of the live like NewBrick,
+// and must return a brick of the type of the live.
+//
+// The precedence of the factories is: the live factory, then the factory of the type (NewBrickCtx, NewBrick),
+// then the default empty instance.
+func RegisterLiveFactory(liveID string, factory func(jsonConfig []byte) Brick) {
+	brickManager.liveFactoriesLock.Lock()
+	defer brickManager.liveFactoriesLock.Unlock()
+	if _, ok := brickManager.liveFactories[liveID]; ok {
+		panic(fmt.Errorf("factory of live(%s) already registered", liveID))
+	}
+	brickManager.liveFactories[liveID] = factory
+	brickManager.setDeclaredLiveID(liveID)
+}
+
 // RegisterLiveIDType registers the type of the liveID instance,
 // allowing the actual type of liveID to be obtained when injecting a brick for an interface.
 func RegisterLiveIDType[T Brick](liveID string) {
@@ -156,13 +226,62 @@ func (b *BrickManager) register2(typeID string, reflectType reflect.Type, brickF
 	b.register(param)
 }
 
+// newBrickCtxFactory returns the factory of the brick type if it implements BrickNewerCtx.
+func newBrickCtxFactory(reflectType reflect.Type) (func(ctx context.Context, config any) (Brick, error), bool) {
+	typ := reflectType
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	newer, ok := reflect.New(typ).Interface().(BrickNewerCtx)
+	if !ok {
+		if newer, ok = reflect.New(typ).Elem().Interface().(BrickNewerCtx); !ok {
+			return nil, false
+		}
+	}
+	return func(ctx context.Context, config any) (Brick, error) {
+		configBytes, err := marshalBrickConfig(config)
+		if err != nil {
+			return nil, fmt.Errorf("brick config marshal error: %w", err)
+		}
+		return newer.NewBrickCtx(ctx, configBytes)
+	}, true
+}
+
 // register registers a brick type and its all recursive dependencies.
 // It takes a type ID, the type itself and optionally a factory function.
 //
 // If a factory function is provided, it will be used to create new instances of the brick type from a configuration.
 // If not, the brick is registered as a non-configurable brick and a default instance will be used.
+//
+// The type and its dependencies are checked before anything is registered, so a rejected type is not half-registered.
 func (b *BrickManager) register(param RegisterBrickParam) {
 	typeID, reflectType, lives, brickFactory := param.TypeID, param.ReflectType, param.Lives, param.BrickFactory
+	if b.valueCopyChecks.Load() && reflectType.Kind() != reflect.Ptr {
+		if path, ok := findNoCopyField(reflectType, ""); ok {
+			panic(fmt.Errorf("the value-type brick %s can't be copied safely, its field %s holds a lock, "+
+				"please register the pointer type", reflectType, path))
+		}
+	}
+	if _, ok := b.getBrickTypeID(reflectType); ok {
+		return
+	}
+	structType := reflectType
+	for structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	var fields brickTypeFields
+	if structType.Kind() == reflect.Struct {
+		fields = b.scanBrickTypeFields(structType)
+		for _, live := range lives {
+			for field := range live.RelyLives {
+				if !fields.names[field] {
+					panic(fmt.Errorf("field %s in %s is not a brick component", field, structType))
+				}
+			}
+		}
+		b.checkTypeCycle(typeID, fields)
+	}
+
 	if !b.setBrickTypeID(reflectType, typeID) {
 		return
 	}
@@ -170,49 +289,124 @@ func (b *BrickManager) register(param RegisterBrickParam) {
 	if brickFactory != nil {
 		b.brickFactoriesLock.Lock()
 		b.brickFactories[typeID] = func(config any) Brick {
-			config = handleConfig(config)
-			configBytes, err := json.Marshal(config)
+			configBytes, err := marshalBrickConfig(config)
 			if err != nil {
 				panic(fmt.Errorf("brick config marshal error: %w", err))
 			}
-			if bytes.Equal(configBytes, []byte("null")) {
-				configBytes = nil
-			}
 			return brickFactory(configBytes)
 		}
 		b.brickFactoriesLock.Unlock()
 	}
-
-	for reflectType.Kind() == reflect.Ptr {
-		reflectType = reflectType.Elem()
+	if ctxFactory, ok := newBrickCtxFactory(reflectType); ok {
+		b.brickCtxFactoriesLock.Lock()
+		b.brickCtxFactories[typeID] = ctxFactory
+		b.brickCtxFactoriesLock.Unlock()
+		if brickFactory == nil {
+			b.brickFactoriesLock.Lock()
+			b.brickFactories[typeID] = func(config any) Brick {
+				brick, err := ctxFactory(context.Background(), config)
+				if err != nil {
+					panic(err)
+				}
+				return brick
+			}
+			b.brickFactoriesLock.Unlock()
+		}
 	}
-	if reflectType.Kind() != reflect.Struct {
+	if structType.Kind() != reflect.Struct {
 		return
 	}
-	var brickFieldNames = make(map[string]bool, 10)
+	for _, tag := range fields.tags {
+		b.declareFieldLiveIDs(tag)
+	}
+	for _, dep := range fields.deps {
+		b.register(dep)
+	}
+	b.setTypeEdges(typeID, fields.edges)
+}
+
+// brickTypeFields is what the `brick` tagged fields of a struct type register, see scanBrickTypeFields.
+type brickTypeFields struct {
+	// names are the names of the fields that can be overridden by RelyLives.
+	names map[string]bool
+	// tags are the tags whose liveIDs are declared.
+	tags []BrickTag
+	// deps are the registrations of the brick types of the fields.
+	deps []RegisterBrickParam
+	// edges are the TypeIDs that the type always builds with it.
+	edges []string
+}
+
+// scanBrickTypeFields checks the `brick` tagged fields of the struct type without registering anything,
+// and returns what they register.
+func (b *BrickManager) scanBrickTypeFields(reflectType reflect.Type) brickTypeFields {
+	fields := brickTypeFields{names: make(map[string]bool, 10)}
 	for i := 0; i < reflectType.NumField(); i++ {
 		Field := reflectType.Field(i)
+		if tag, ok := Field.Tag.Lookup(brickTag); ok {
+			if tag, optional := splitOptionalTag(tag); optional {
+				if err := checkOptionalTag(tag); err != nil {
+					panic(fmt.Errorf("field %s in %s: %w", Field.Name, reflectType, err))
+				}
+			}
+			if liveID, _, _, _ := b.parseTag(tag); strings.HasPrefix(liveID, methodTagPrefix) {
+				checkMethodField(reflectType, Field, strings.TrimPrefix(liveID, methodTagPrefix))
+				continue
+			}
+		}
 		fieldType := Field.Type
+		elemType, isLazy := lazyElemType(fieldType)
+		if isLazy {
+			fieldType = elemType
+		}
+		if fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Map {
+			fieldType = fieldType.Elem()
+		}
 		for fieldType.Kind() == reflect.Ptr {
 			fieldType = fieldType.Elem()
 		}
+		if fieldType.Kind() == reflect.Interface {
+			if tag, ok := Field.Tag.Lookup(brickTag); ok {
+				b.checkInterfaceField(reflectType, Field, fieldType, ParseBrickTag(tag))
+				fields.tags = append(fields.tags, ParseBrickTag(tag))
+			}
+			continue
+		}
 		if fieldType.Kind() != reflect.Struct {
 			continue
 		}
-		tag, ok := Field.Tag.Lookup(brickTag)
+		rawTag, ok := Field.Tag.Lookup(brickTag)
 		if !ok {
 			continue
 		}
-		brickFieldNames[Field.Name] = true
-		liveID, typeID, isClone, _ := b.parseTag(tag)
-		if !isClone && liveID != typeID && liveID != "" {
-			b.setDeclaredLiveID(liveID)
+		fields.names[Field.Name] = true
+		tag := ParseBrickTag(rawTag)
+		if tag.Provider || tag.Configs || tag.Secret != "" {
+			continue
 		}
+		// A lazy, deferred or random dependency is not built with its dependent, so it can't close a cycle,
+		// neither can the members of a group, nor a liveID only known at injection time.
+		isEdge := !isLazy && !tag.Random && tag.From == "" && !tag.Group && !tag.All && !tag.Deferred && !tag.Selected
+		fields.tags = append(fields.tags, tag)
 		// This is the dependency that needs to be injected, check if it implements the Brick interface
 		brickType := reflect.TypeOf((*Brick)(nil)).Elem()
 		imp := fieldType.Implements(brickType)
 		ptrImp := reflect.PointerTo(fieldType).Implements(brickType)
 		if !imp && !ptrImp {
+			if tag.TypeID != "" {
+				// A wrapper type of the brick given by typeID, e.g. `type MyLogger Logger`.
+				if isEdge {
+					fields.edges = append(fields.edges, tag.TypeID)
+				}
+				continue
+			}
+			if configTypeID, ok := b.typeIDOf(fieldType); ok {
+				// A config brick registered by RegisterConfigBrick.
+				if isEdge {
+					fields.edges = append(fields.edges, configTypeID)
+				}
+				continue
+			}
 			panic(fmt.Errorf("field %s in %s is not a brick component", Field.Name, reflectType))
 		}
 		// Call BrickTypeID()
@@ -222,10 +416,14 @@ func (b *BrickManager) register(param RegisterBrickParam) {
 		}
 		instanceI := instance.Interface()
 		fieldTypeID := instanceI.(Brick).BrickTypeID()
+		if isEdge {
+			fields.edges = append(fields.edges, fieldTypeID)
+		}
 		instanceConfiger, ok := instanceI.(BrickNewer)
 		instanceLives, ok2 := instanceI.(BrickLives)
+		// Like Register, a value receiver registers the value type, a pointer receiver the pointer type.
 		registerType := fieldType
-		if ptrImp {
+		if !imp {
 			registerType = reflect.PointerTo(fieldType)
 		}
 		params := RegisterBrickParam{
@@ -238,13 +436,158 @@ func (b *BrickManager) register(param RegisterBrickParam) {
 		if ok2 {
 			params.Lives = instanceLives.BrickLives()
 		}
-		b.register(params)
+		fields.deps = append(fields.deps, params)
 	}
-	for _, live := range lives {
-		for field := range live.RelyLives {
-			if !brickFieldNames[field] {
-				panic(fmt.Errorf("field %s in %s is not a brick component", field, reflectType))
+	return fields
+}
+
+// checkMethodField panics if the method of a `method:Name` field is not a method of the struct type,
+// taking no argument and returning the field type.
+func checkMethodField(structType reflect.Type, field reflect.StructField, name string) {
+	// The method value of a zero instance has the type of the method without its receiver.
+	method := reflect.New(structType).MethodByName(name)
+	if !method.IsValid() {
+		panic(fmt.Errorf("the method %s of field %s in %s is not found", name, field.Name, structType))
+	}
+	if err := checkFieldMethod(method.Type(), field); err != nil {
+		panic(fmt.Errorf("the method %s of field %s in %s %w", name, field.Name, structType, err))
+	}
+}
+
+// checkInterfaceField panics if the brick type known at registration for the interface field doesn't implement
+// the interface: the type of the typeID given on the tag, or the type registered for the liveID by RegisterLiveIDType.
+// The types only known at injection, e.g. given by the configuration, are checked when the field is injected.
+func (b *BrickManager) checkInterfaceField(structType reflect.Type, field reflect.StructField, iface reflect.Type, tag BrickTag) {
+	deps := b.resolveField(field.Type, tag)
+	if deps.source != planSourceTag && deps.source != "weighted" {
+		return
+	}
+	if tag.TypeID != "" {
+		if typ, ok := b.getBrickType(tag.TypeID); ok && !implementsInterface(typ, iface) {
+			panic(fmt.Errorf("field %s in %s: the brick %v of typeID(%s) does not implement %v", field.Name, structType, typ, tag.TypeID, iface))
+		}
+		return
+	}
+	for _, edge := range deps.edges {
+		b.liveIDTypeMapLock.RLock()
+		typ, ok := b.liveIDTypeMap[edge.liveID]
+		b.liveIDTypeMapLock.RUnlock()
+		if ok && !implementsInterface(typ, iface) {
+			panic(fmt.Errorf("field %s in %s: the brick %v of liveID(%s) does not implement %v", field.Name, structType, typ, edge.liveID, iface))
+		}
+	}
+}
+
+// declareFieldLiveIDs declares the liveIDs written in the tag of a brick or interface field.
+// The liveID of an optional field is not declared, it is unknown until it is configured, neither is the one of a clone.
+func (b *BrickManager) declareFieldLiveIDs(tag BrickTag) {
+	if tag.Optional || tag.Clone || tag.Provider {
+		return
+	}
+	liveIDs := tag.LiveIDs
+	if tag.LiveID != "" && tag.LiveID != tag.TypeID {
+		liveIDs = []string{tag.LiveID}
+	}
+	for _, id := range liveIDs {
+		b.setDeclaredLiveID(id)
+	}
+}
+
+// implementsInterface reports whether an instance of typ can be injected into a field of the interface iface,
+// which dereferences or takes the address of the instance like convertInstance.
+func implementsInterface(typ reflect.Type, iface reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Implements(iface) || reflect.PointerTo(typ).Implements(iface)
+}
+
+// setTypeEdges saves the TypeIDs that the type always builds with it, checked by checkTypeCycle.
+func (b *BrickManager) setTypeEdges(typeID string, edges []string) {
+	b.typeEdgesLock.Lock()
+	defer b.typeEdgesLock.Unlock()
+	b.typeEdges[typeID] = edges
+}
+
+// checkTypeCycle panics if registering the type with its fields would close a cycle.
+// The edges of the dependencies that are not registered yet are scanned from their fields.
+func (b *BrickManager) checkTypeCycle(typeID string, fields brickTypeFields) {
+	pending := map[string][]string{typeID: fields.edges}
+	deps := fields.deps
+	for len(deps) > 0 {
+		dep := deps[0]
+		deps = deps[1:]
+		if _, ok := pending[dep.TypeID]; ok {
+			continue
+		}
+		if _, ok := b.getBrickTypeID(dep.ReflectType); ok {
+			continue
+		}
+		depType := dep.ReflectType
+		for depType.Kind() == reflect.Ptr {
+			depType = depType.Elem()
+		}
+		pending[dep.TypeID] = nil
+		if depType.Kind() == reflect.Struct {
+			depFields := b.scanBrickTypeFields(depType)
+			pending[dep.TypeID] = depFields.edges
+			deps = append(deps, depFields.deps...)
+		}
+	}
+
+	b.typeEdgesLock.RLock()
+	defer b.typeEdgesLock.RUnlock()
+	edgesOf := func(typeID string) []string {
+		if edges, ok := pending[typeID]; ok {
+			return edges
+		}
+		return b.typeEdges[typeID]
+	}
+	if cycle, ok := findTypeCycle(edgesOf, typeID, []string{typeID}, make(map[string]bool)); ok {
+		panic(fmt.Errorf("%w at registration: %s", ErrCircularDependency, strings.Join(cycle, " -> ")))
+	}
+}
+
+// findTypeCycle returns the path from the last typeID of path back to its first typeID, if any.
+func findTypeCycle(edgesOf func(typeID string) []string, start string, path []string, visited map[string]bool) ([]string, bool) {
+	for _, next := range edgesOf(path[len(path)-1]) {
+		if next == start {
+			return append(path, next), true
+		}
+		if visited[next] {
+			continue
+		}
+		visited[next] = true
+		if cycle, ok := findTypeCycle(edgesOf, start, append(path, next), visited); ok {
+			return cycle, true
+		}
+	}
+	return nil, false
+}
+
+var lockerInterfaceType = reflect.TypeOf((*sync.Locker)(nil)).Elem()
+
+// findNoCopyField returns the path of a field of the type that must not be copied,
+// i.e. a type of the sync packages or a struct with a pointer Lock method like a noCopy marker, the way `go vet` detects them.
+func findNoCopyField(typ reflect.Type, path string) (string, bool) {
+	switch typ.Kind() {
+	case reflect.Struct:
+		pkg := typ.PkgPath()
+		if path != "" && (pkg == "sync" || pkg == "sync/atomic" || reflect.PointerTo(typ).Implements(lockerInterfaceType)) {
+			return path, true
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			fieldPath := field.Name
+			if path != "" {
+				fieldPath = path + "." + field.Name
+			}
+			if p, ok := findNoCopyField(field.Type, fieldPath); ok {
+				return p, true
 			}
 		}
+	case reflect.Array:
+		return findNoCopyField(typ.Elem(), path+"[]")
 	}
+	return "", false
 }
@@ -0,0 +1,339 @@
+package brick
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+)
+
+// reloadEventsBuffer is the capacity of the reload events channel.
+const reloadEventsBuffer = 16
+
+// ReloadEvent describes a completed config file reload.
+type ReloadEvent struct {
+	// FilePath is the reloaded config file, empty for RollbackConfig.
+	FilePath string
+	// ChangedLiveIDs are the sorted liveIDs whose configuration was added, changed or removed.
+	ChangedLiveIDs []string
+	// Err is the error that aborted the reload, the old configuration stays active if it is not nil.
+	// It is also the error of BrickReload, after the new configuration is applied.
+	Err error
+}
+
+// ReloadConfigFile reloads a config file previously added by AddConfigFile,
+// replacing the configurations of its lives. Instances already built are not rebuilt:
+// the ones of the changed lives implementing BrickReloader are given their new config,
+// the other ones keep their old config until they are rebuilt.
+//
+// The new configuration is validated before it is applied, see BrickConfigValidator.
+// If any live is invalid, the whole reload is rejected and the old configuration stays active.
+func ReloadConfigFile(path string) error {
+	return brickManager.ReloadConfigFile(path)
+}
+
+// ConfigReloadEvents returns the channel receiving an event every time a config file reload completes.
+// The channel is buffered, events are dropped if it is full.
+func ConfigReloadEvents() <-chan ReloadEvent {
+	return brickManager.ConfigReloadEvents()
+}
+
+// ConfigReloadEvents returns the channel receiving an event every time a config file reload completes.
+func (b *BrickManager) ConfigReloadEvents() <-chan ReloadEvent {
+	return b.reloadEvents
+}
+
+// ReloadConfigFile reloads a config file previously added by AddConfigFile.
+func (b *BrickManager) ReloadConfigFile(path string) error {
+	changed, err := b.reloadConfigFile(path)
+	if err == nil {
+		err = b.notifyReloaders(changed)
+	}
+	b.emitReloadEvent(ReloadEvent{FilePath: path, ChangedLiveIDs: changed, Err: err})
+	return err
+}
+
+func (b *BrickManager) reloadConfigFile(path string) ([]string, error) {
+	staged, err := b.stageReload(path)
+	if err != nil {
+		return nil, err
+	}
+	b.applyReload(staged)
+	return staged.changed, nil
+}
+
+// stagedReload is a validated reload of a config file that has not been applied.
+type stagedReload struct {
+	path string
+	// configs are the staged configurations, indexed by LiveID.
+	configs map[string]BrickConfig
+	// oldConfigs are the configurations of the file when the reload was staged, indexed by LiveID.
+	oldConfigs map[string]BrickConfig
+	// changed are the sorted liveIDs whose configuration is added, changed or removed.
+	changed []string
+}
+
+// stageReload reads and validates the config file, and diffs it against the current configurations of the file.
+func (b *BrickManager) stageReload(path string) (stagedReload, error) {
+	if !b.hasConfigFile(path) {
+		return stagedReload{}, fmt.Errorf("config file(%s) has not been added", path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return stagedReload{}, err
+	}
+	configs, err := parseConfigFile(path, content)
+	if err != nil {
+		return stagedReload{}, err
+	}
+
+	b.prepareFileConfigs(configs)
+	oldConfigs := b.getBrickConfigsByFile(path)
+	replacing := make(map[string]bool, len(oldConfigs))
+	for liveID := range oldConfigs {
+		replacing[liveID] = true
+	}
+	if err := b.checkFileConfigs(configs, replacing, b.configFileOptions(path)); err != nil {
+		return stagedReload{}, err
+	}
+	if err := b.validateFileConfigs(configs); err != nil {
+		return stagedReload{}, err
+	}
+
+	return newStagedReload(path, oldConfigs, fileBrickConfigs(configs, path)), nil
+}
+
+// newStagedReload stages the replacement of the configurations oldConfigs by configs, both indexed by LiveID.
+func newStagedReload(path string, oldConfigs, configs map[string]BrickConfig) stagedReload {
+	var changed []string
+	for liveID, config := range configs {
+		if old, ok := oldConfigs[liveID]; !ok || !brickConfigEqual(old, config) {
+			changed = append(changed, liveID)
+		}
+	}
+	for liveID := range oldConfigs {
+		if _, ok := configs[liveID]; !ok {
+			changed = append(changed, liveID)
+		}
+	}
+	sort.Strings(changed)
+	return stagedReload{path: path, configs: configs, oldConfigs: oldConfigs, changed: changed}
+}
+
+// applyReload replaces the staged old configurations with the staged ones.
+func (b *BrickManager) applyReload(staged stagedReload) {
+	for liveID := range staged.oldConfigs {
+		if _, ok := staged.configs[liveID]; !ok {
+			b.deleteBrickConfig(liveID)
+		}
+	}
+	b.setBrickConfigs(staged.configs)
+}
+
+// notifyReloaders calls BrickReload of the built instances of the liveIDs with their current config.
+func (b *BrickManager) notifyReloaders(liveIDs []string) error {
+	var errs []error
+	for _, liveID := range liveIDs {
+		instance, ok := b.getBrickFromExist(liveID)
+		if !ok {
+			continue
+		}
+		config, ok := b.getBrickConfig(liveID)
+		if !ok {
+			continue
+		}
+		reloader, ok := asInterface[BrickReloader](instance)
+		if !ok {
+			continue
+		}
+		configBytes, err := marshalBrickConfig(config.Config)
+		if err == nil {
+			err = reloader.BrickReload(configBytes)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to reload brick(%s): %w", liveID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ReloadPlan describes the effect of reloading a config file, see PlanReload.
+type ReloadPlan struct {
+	// FilePath is the config file to reload.
+	FilePath string
+	// ChangedLiveIDs are the sorted liveIDs whose configuration would be added, changed or removed.
+	ChangedLiveIDs []string
+	// Rebuild are the sorted liveIDs of the built instances that ApplyReload drops, so they are rebuilt at the next Get:
+	// the changed lives and their transitive dependents that are built.
+	Rebuild []string
+	// Dependents are the sorted liveIDs that transitively depend on the changed lives, built or not.
+	Dependents []string
+
+	staged stagedReload
+}
+
+// PlanReload reads and validates the updated config file, and returns the plan of its reload without applying anything,
+// so an operator can review it before calling ApplyReload.
+func PlanReload(path string) (ReloadPlan, error) {
+	return brickManager.PlanReload(path)
+}
+
+// ApplyReload applies the plan of PlanReload: it replaces the configurations of the file with the planned ones,
+// and drops the instances listed in plan.Rebuild, so they are rebuilt with the new configuration at the next Get.
+// The dropped instances are not closed, since their holders may still use them.
+//
+// It fails, without applying anything, if the configurations of the file changed since the plan.
+func ApplyReload(plan ReloadPlan) error {
+	return brickManager.ApplyReload(plan)
+}
+
+// PlanReload returns the plan of the reload of the config file without applying anything.
+func (b *BrickManager) PlanReload(path string) (ReloadPlan, error) {
+	staged, err := b.stageReload(path)
+	if err != nil {
+		return ReloadPlan{}, err
+	}
+	changed := make(map[string]bool, len(staged.changed))
+	dependents := make(map[string]bool)
+	for _, liveID := range staged.changed {
+		changed[liveID] = true
+		for _, dependent := range b.Dependents(liveID) {
+			dependents[dependent] = true
+		}
+	}
+	plan := ReloadPlan{FilePath: path, ChangedLiveIDs: staged.changed, staged: staged}
+	for liveID := range dependents {
+		if !changed[liveID] {
+			plan.Dependents = append(plan.Dependents, liveID)
+		}
+	}
+	sort.Strings(plan.Dependents)
+	for _, liveID := range b.BuiltLiveIDs() {
+		if changed[liveID] || dependents[liveID] {
+			plan.Rebuild = append(plan.Rebuild, liveID)
+		}
+	}
+	return plan, nil
+}
+
+// ApplyReload applies the plan of PlanReload.
+func (b *BrickManager) ApplyReload(plan ReloadPlan) error {
+	err := b.applyReloadPlan(plan)
+	b.emitReloadEvent(ReloadEvent{FilePath: plan.FilePath, ChangedLiveIDs: plan.ChangedLiveIDs, Err: err})
+	return err
+}
+
+func (b *BrickManager) applyReloadPlan(plan ReloadPlan) error {
+	if plan.staged.path == "" {
+		return fmt.Errorf("the reload plan is not made by PlanReload")
+	}
+	current := b.getBrickConfigsByFile(plan.staged.path)
+	stale := len(current) != len(plan.staged.oldConfigs)
+	for liveID, old := range plan.staged.oldConfigs {
+		config, ok := current[liveID]
+		if !ok || !brickConfigEqual(config, old) {
+			stale = true
+			break
+		}
+	}
+	if stale {
+		return fmt.Errorf("the configurations of file(%s) changed since the reload plan", plan.staged.path)
+	}
+	b.applyReload(plan.staged)
+	for _, liveID := range plan.Rebuild {
+		if instance, ok := b.getBrickFromExist(liveID); ok {
+			b.deleteBrickInstance(liveID, instance)
+		}
+	}
+	return nil
+}
+
+// validateFileConfigs validates the staged configurations of a reload, and returns the errors of every invalid live.
+// A live is invalid if its brick type is not registered, if a validator of RegisterConfigValidator rejects a key of its config,
+// or if the brick implements BrickConfigValidator and rejects its config.
+// Configs with `noCheck: true` are not validated.
+func (b *BrickManager) validateFileConfigs(configs []BrickFileConfig) error {
+	var errs []error
+	for _, config := range configs {
+		if config.MetaData.NoCheck {
+			continue
+		}
+		typ, ok := b.getBrickType(config.MetaData.TypeID)
+		if !ok {
+			errs = append(errs, fmt.Errorf("the brick(%s) is not registered", config.MetaData.TypeID))
+			continue
+		}
+		for _, live := range config.Lives {
+			errs = append(errs, b.validateConfigKeys(config.MetaData.TypeID, live.LiveID, live.Config)...)
+		}
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		validator, ok := reflect.New(typ).Interface().(BrickConfigValidator)
+		if !ok {
+			continue
+		}
+		for _, live := range config.Lives {
+			configBytes, err := marshalBrickConfig(live.Config)
+			if err == nil {
+				err = validator.ValidateConfig(configBytes)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("invalid config of live(%s): %w", live.LiveID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (b *BrickManager) emitReloadEvent(event ReloadEvent) {
+	select {
+	case b.reloadEvents <- event:
+	default:
+	}
+}
+
+func (b *BrickManager) hasConfigFile(path string) bool {
+	b.configsLock.RLock()
+	defer b.configsLock.RUnlock()
+	for _, config := range b.configs {
+		if config.filePath == path {
+			return true
+		}
+	}
+	return false
+}
+
+// configFileOptions returns the options the config file was added with.
+func (b *BrickManager) configFileOptions(path string) ConfigOptions {
+	b.configsLock.RLock()
+	defer b.configsLock.RUnlock()
+	for _, config := range b.configs {
+		if config.filePath == path {
+			return config.options
+		}
+	}
+	return ConfigOptions{}
+}
+
+// brickConfigEqual reports whether two configurations of a live are the same.
+func brickConfigEqual(a, b BrickConfig) bool {
+	return a.TypeID == b.TypeID && a.Weight == b.Weight && a.Order == b.Order && a.Disabled == b.Disabled &&
+		a.noCheck == b.noCheck && a.filePath == b.filePath && a.clone == b.clone && configEqual(a.Config, b.Config)
+}
+
+// configEqual reports whether two raw configurations are deeply equal, placeholders are compared as is.
+func configEqual(a, b any) bool {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	equal, err := jsonEqual(ja, jb)
+	return err == nil && equal
+}
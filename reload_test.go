@@ -0,0 +1,323 @@
+package brick
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type TestBrick16 struct {
+	Name string `json:"name"`
+}
+
+func (t *TestBrick16) BrickTypeID() string {
+	return "TestBrick16"
+}
+
+func (t *TestBrick16) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick16{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+// waitReloadEvent waits for the reload event of the config file.
+func waitReloadEvent(t *testing.T, path string) ReloadEvent {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-ConfigReloadEvents():
+			if event.FilePath == path {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("no reload event for %s", path)
+		}
+	}
+}
+
+func TestReloadConfigFile(t *testing.T) {
+	RegisterNewer[*TestBrick16]()
+	t.Setenv("TEST_BRICK16_NAME", "env name")
+	path := filepath.Join(t.TempDir(), "bricks.json")
+	writeConfig := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig(`[{
+		"metaData": {"typeID": "TestBrick16"},
+		"lives": [
+			{"liveID": "TestBrick16", "config": {"name": "${TEST_BRICK16_NAME}"}},
+			{"liveID": "TestBrick16 a", "config": {"name": "a"}}
+		]
+	}]`)
+	if err := AddConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := Get[*TestBrick16]().Name; got != "env name" {
+		t.Errorf("Name = %v, want env name", got)
+	}
+
+	writeConfig(`[{
+		"metaData": {"typeID": "TestBrick16"},
+		"lives": [
+			{"liveID": "TestBrick16", "config": {"name": "${TEST_BRICK16_NAME}"}},
+			{"liveID": "TestBrick16 a", "config": {"name": "changed"}}
+		]
+	}]`)
+	if err := ReloadConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	event := waitReloadEvent(t, path)
+	if event.Err != nil || !slices.Equal(event.ChangedLiveIDs, []string{"TestBrick16 a"}) {
+		t.Errorf("event = %+v, want TestBrick16 a changed", event)
+	}
+	if got := Get[*TestBrick16]("TestBrick16 a").Name; got != "changed" {
+		t.Errorf("Name = %v, want changed", got)
+	}
+
+	writeConfig(`{`)
+	if err := ReloadConfigFile(path); err == nil {
+		t.Errorf("expected error for an invalid config file")
+	}
+	if event := waitReloadEvent(t, path); event.Err == nil {
+		t.Errorf("event = %+v, want error", event)
+	}
+}
+
+type TestBrick26 struct {
+	Port int `json:"port"`
+}
+
+func (t *TestBrick26) BrickTypeID() string {
+	return "TestBrick26"
+}
+
+func (t *TestBrick26) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick26{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func (t *TestBrick26) ValidateConfig(config []byte) error {
+	var c TestBrick26
+	if err := json.Unmarshal(config, &c); err != nil {
+		return err
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		return fmt.Errorf("port %d out of range", c.Port)
+	}
+	return nil
+}
+
+func TestReloadConfigFileValidation(t *testing.T) {
+	RegisterNewer[*TestBrick26]()
+	path := filepath.Join(t.TempDir(), "bricks.json")
+	writeConfig := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig(`[{
+		"metaData": {"typeID": "TestBrick26"},
+		"lives": [
+			{"liveID": "TestBrick26", "config": {"port": 80}},
+			{"liveID": "TestBrick26 a", "config": {"port": 81}}
+		]
+	}]`)
+	if err := AddConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	writeConfig(`[{
+		"metaData": {"typeID": "TestBrick26"},
+		"lives": [
+			{"liveID": "TestBrick26", "config": {"port": 8080}},
+			{"liveID": "TestBrick26 a", "config": {"port": -1}}
+		]
+	}, {
+		"metaData": {"typeID": "TestBrick26 unknown"},
+		"lives": [{"liveID": "TestBrick26 unknown"}]
+	}]`)
+	err := ReloadConfigFile(path)
+	if err == nil {
+		t.Fatal("expected the reload to be rejected")
+	}
+	for _, want := range []string{"live(TestBrick26 a)", "port -1 out of range", "TestBrick26 unknown"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+	if event := waitReloadEvent(t, path); event.Err == nil {
+		t.Errorf("event = %+v, want error", event)
+	}
+	if got := Get[*TestBrick26]().Port; got != 80 {
+		t.Errorf("Port = %d, want the old config 80", got)
+	}
+	if _, ok := brickManager.getBrickConfig("TestBrick26 unknown"); ok {
+		t.Errorf("the rejected live is added")
+	}
+}
+
+type TestBrick50 struct {
+	Value string `json:"value"`
+}
+
+func (t *TestBrick50) BrickTypeID() string {
+	return "TestBrick50"
+}
+
+func (t *TestBrick50) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick50{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+type TestBrick501 struct {
+	Dep *TestBrick50 `brick:"TestBrick50 b"`
+}
+
+func (t *TestBrick501) BrickTypeID() string {
+	return "TestBrick501"
+}
+
+type TestBrick502 struct {
+	Dep *TestBrick501 `brick:""`
+}
+
+func (t *TestBrick502) BrickTypeID() string {
+	return "TestBrick502"
+}
+
+func TestPlanReload(t *testing.T) {
+	RegisterNewer[*TestBrick50]()
+	Register[*TestBrick502]()
+	path := filepath.Join(t.TempDir(), "plan.json")
+	write := func(value string) {
+		content := fmt.Sprintf(`[{"metaData": {"typeID": "TestBrick50"}, "lives": [
+			{"liveID": "TestBrick50", "config": {"value": "a"}},
+			{"liveID": "TestBrick50 b", "config": {"value": %q}}
+		]}]`, value)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("old")
+	if err := AddConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	Get[*TestBrick502]()
+
+	write("new")
+	plan, err := PlanReload(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"TestBrick50 b"}; !slices.Equal(plan.ChangedLiveIDs, want) {
+		t.Errorf("ChangedLiveIDs = %v, want %v", plan.ChangedLiveIDs, want)
+	}
+	if want := []string{"TestBrick501", "TestBrick502"}; !slices.Equal(plan.Dependents, want) {
+		t.Errorf("Dependents = %v, want %v", plan.Dependents, want)
+	}
+	if want := []string{"TestBrick50 b", "TestBrick501", "TestBrick502"}; !slices.Equal(plan.Rebuild, want) {
+		t.Errorf("Rebuild = %v, want %v", plan.Rebuild, want)
+	}
+	if got := Get[*TestBrick50]("TestBrick50 b").Value; got != "old" {
+		t.Errorf("value = %q before ApplyReload, want the plan not applied", got)
+	}
+
+	if err := ApplyReload(plan); err != nil {
+		t.Fatal(err)
+	}
+	if got := Get[*TestBrick502]().Dep.Dep.Value; got != "new" {
+		t.Errorf("value = %q after ApplyReload, want the dependents rebuilt", got)
+	}
+	if err := ApplyReload(plan); err == nil {
+		t.Error("ApplyReload() of a stale plan succeeded, want an error")
+	}
+}
+
+type TestBrick83 struct {
+	mu   sync.Mutex
+	name string
+}
+
+func (t *TestBrick83) BrickTypeID() string {
+	return "TestBrick83"
+}
+
+func (t *TestBrick83) NewBrick(config []byte) Brick {
+	newBrick := &TestBrick83{}
+	if err := newBrick.BrickReload(config); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func (t *TestBrick83) BrickReload(config []byte) error {
+	var c struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(config, &c); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.name = c.Name
+	return nil
+}
+
+func (t *TestBrick83) Name() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.name
+}
+
+func TestReloadConfigFileNotifiesReloaders(t *testing.T) {
+	RegisterNewer[*TestBrick83]()
+	path := filepath.Join(t.TempDir(), "bricks.json")
+	writeConfig := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig(`[{"metaData": {"typeID": "TestBrick83"}, "lives": [{"liveID": "TestBrick83", "config": {"name": "old"}}]}]`)
+	if err := AddConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	instance := Get[*TestBrick83]()
+
+	writeConfig(`[{"metaData": {"typeID": "TestBrick83"}, "lives": [{"liveID": "TestBrick83", "config": {"name": "new"}}]}]`)
+	if err := ReloadConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	event := waitReloadEvent(t, path)
+	if event.Err != nil || !slices.Equal(event.ChangedLiveIDs, []string{"TestBrick83"}) {
+		t.Errorf("event = %+v, want TestBrick83 changed", event)
+	}
+	if got := Get[*TestBrick83](); got != instance || got.Name() != "new" {
+		t.Errorf("Get() = %p with name %s, want %p reloaded with name new", got, got.Name(), instance)
+	}
+
+	// A partially written file is rejected, and the old configuration stays active.
+	writeConfig(`[{"metaData": {"typeID": "TestBrick83"}, "lives": [{"liveID": "TestBrick83", "con`)
+	if err := ReloadConfigFile(path); err == nil {
+		t.Error("ReloadConfigFile() of a partial file succeeded")
+	}
+	if got := instance.Name(); got != "new" {
+		t.Errorf("Name() = %s after a partial write, want new", got)
+	}
+}
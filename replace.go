@@ -0,0 +1,126 @@
+package brick
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Replace replaces the instance of the liveID, and re-wires the existing instances
+// whose fields are tagged `rewireable`, e.g. `brick:"db,rewireable"`.
+// Fields without the `rewireable` option keep the old instance.
+// It panics if the type of the instance is not the brick type of the liveID.
+//
+// The old instance is closed like Shutdown closes it if it implements BrickCloser, after the re-wiring,
+// and the error of its Close is returned. The fields without `rewireable` that still hold it see it closed.
+//
+// Re-wiring sets the fields through reflection, so only exported fields of bricks stored as pointers
+// (i.e. addressable fields) are updated. The fields are not synchronized, the caller must ensure
+// that no dependent is using the field while it is replaced.
+func Replace(liveID string, instance Brick) error {
+	return brickManager.Replace(liveID, instance)
+}
+
+// Replace replaces the instance of the liveID, re-wires the `rewireable` fields of the existing instances, and closes the old instance.
+func (b *BrickManager) Replace(liveID string, instance Brick) error {
+	if instance == nil {
+		panic(fmt.Errorf("cannot replace brick(%s) with nil", liveID))
+	}
+	liveID = b.resolveLiveID(liveID)
+	newInstance := reflect.ValueOf(instance)
+	if typ, ok := b.liveIDType(liveID); ok && !isSameBaseType(newInstance.Type(), typ) {
+		panic(fmt.Errorf("cannot replace brick(%s) of type %v with type %v", liveID, typ, newInstance.Type()))
+	}
+	if newInstance.Kind() != reflect.Ptr {
+		ptr := reflect.New(newInstance.Type())
+		ptr.Elem().Set(newInstance)
+		newInstance = ptr
+	}
+	oldInstance, replaced := b.getBrickFromExist(liveID)
+	b.saveBrickInstance(liveID, newInstance)
+	// The replacement is not built from a config.
+	b.deleteBuiltConfig(liveID)
+	b.setDegraded(liveID, false)
+
+	b.instancesLock.RLock()
+	instances := make(map[string]reflect.Value, len(b.instances))
+	for id, brick := range b.instances {
+		instances[id] = brick
+	}
+	b.instancesLock.RUnlock()
+	for dependentLiveID, brick := range instances {
+		if dependentLiveID != liveID {
+			b.rewire(brick, dependentLiveID, liveID, newInstance)
+		}
+	}
+	if !replaced || oldInstance.Pointer() == newInstance.Pointer() {
+		return nil
+	}
+	return b.closeReplaced(liveID, oldInstance)
+}
+
+// closeReplaced closes the replaced instance of the liveID if it implements BrickCloser, once like Shutdown.
+func (b *BrickManager) closeReplaced(liveID string, instance reflect.Value) error {
+	closer, ok := asInterface[BrickCloser](instance)
+	if !ok {
+		return nil
+	}
+	b.shutdownLock.Lock()
+	defer b.shutdownLock.Unlock()
+	key := closedBrickKey{ptr: instance.Pointer(), typ: instance.Type()}
+	if _, done := b.closedBricks[key]; done {
+		return nil
+	}
+	b.closedBricks[key] = instance
+	if err := closeBrick(context.Background(), closer, 0); err != nil {
+		return fmt.Errorf("close replaced brick(%s): %w", liveID, err)
+	}
+	return nil
+}
+
+// rewire sets the `rewireable` fields of the brick that depend on the liveID to the new instance.
+func (b *BrickManager) rewire(brick reflect.Value, brickLiveID string, liveID string, newInstance reflect.Value) {
+	rfValue := brick
+	for rfValue.Kind() == reflect.Ptr || rfValue.Kind() == reflect.Interface {
+		rfValue = rfValue.Elem()
+	}
+	if rfValue.Kind() != reflect.Struct {
+		return
+	}
+	rfType := rfValue.Type()
+	var brickLive *Live
+	if lives, ok := b.getBrickLives(rfType); ok {
+		for _, live := range lives {
+			if live.LiveID == brickLiveID {
+				brickLive = &live
+				break
+			}
+		}
+	}
+	for i := 0; i < rfType.NumField(); i++ {
+		typeField := rfType.Field(i)
+		valueField := rfValue.Field(i)
+		tag, ok := typeField.Tag.Lookup(brickTag)
+		if !ok || !valueField.CanSet() {
+			continue
+		}
+		if brickLive != nil {
+			if tag2, ok := brickLive.RelyLives[typeField.Name]; ok {
+				tag = tag2
+			}
+		}
+		depLiveID, typeID, isClone, _ := b.parseTag(tag)
+		if typeID != rewireableTag || isClone {
+			continue
+		}
+		if depLiveID == "" {
+			if typeField.Type.Kind() == reflect.Interface {
+				continue
+			}
+			depLiveID = b.getTypeIDByReflectType(typeField.Type)
+		}
+		if b.resolveLiveID(depLiveID) == liveID {
+			valueField.Set(convertInstance(newInstance, typeField.Type, liveID))
+		}
+	}
+}
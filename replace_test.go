@@ -0,0 +1,130 @@
+package brick
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type TestBrick23 struct {
+	DSN string
+}
+
+func (t *TestBrick23) BrickTypeID() string {
+	return "TestBrick23"
+}
+
+type TestBrick231 struct {
+	DB       *TestBrick23 `brick:",rewireable"`
+	DBAny    any          `brick:"TestBrick23,rewireable"`
+	DBPinned *TestBrick23 `brick:""`
+}
+
+func (t *TestBrick231) BrickTypeID() string {
+	return "TestBrick231"
+}
+
+func TestReplace(t *testing.T) {
+	Register[*TestBrick231]()
+	consumer := Get[*TestBrick231]()
+	old := Get[*TestBrick23]()
+	if consumer.DB != old || consumer.DBAny != old {
+		t.Fatalf("consumer is not wired to the shared brick")
+	}
+
+	replacement := &TestBrick23{DSN: "replacement"}
+	Replace("TestBrick23", replacement)
+	if got := Get[*TestBrick23](); got != replacement {
+		t.Errorf("Get() = %v after Replace, want the replacement", got)
+	}
+	if consumer.DB != replacement {
+		t.Errorf("rewireable field DB = %v, want the replacement", consumer.DB)
+	}
+	if consumer.DBAny != replacement {
+		t.Errorf("rewireable field DBAny = %v, want the replacement", consumer.DBAny)
+	}
+	if consumer.DBPinned != old {
+		t.Errorf("field DBPinned = %v without rewireable, want the old instance", consumer.DBPinned)
+	}
+}
+
+type TestBrick57 struct {
+	Name string
+}
+
+func (t *TestBrick57) BrickTypeID() string {
+	return "TestBrick57"
+}
+
+func TestInstanceGeneration(t *testing.T) {
+	Register[*TestBrick57]()
+	if got := InstanceGeneration("TestBrick57"); got != 0 {
+		t.Errorf("InstanceGeneration() = %d before the build, want 0", got)
+	}
+	Get[*TestBrick57]()
+	Get[*TestBrick57]()
+	if got := InstanceGeneration("TestBrick57"); got != 1 {
+		t.Errorf("InstanceGeneration() = %d after the build, want 1", got)
+	}
+	Replace("TestBrick57", &TestBrick57{Name: "second"})
+	Replace("TestBrick57", &TestBrick57{Name: "third"})
+	if got := InstanceGeneration("TestBrick57"); got != 3 {
+		t.Errorf("InstanceGeneration() = %d after 2 replaces, want 3", got)
+	}
+	Replace("TestBrick57", Get[*TestBrick57]())
+	if got := InstanceGeneration("TestBrick57"); got != 3 {
+		t.Errorf("InstanceGeneration() = %d after replacing with the same instance, want 3", got)
+	}
+}
+
+type TestBrick232 struct{}
+
+func (t *TestBrick232) BrickTypeID() string {
+	return "TestBrick232"
+}
+
+func TestReplaceTypeMismatch(t *testing.T) {
+	Register[*TestBrick232]()
+	old := Get[*TestBrick232]()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Replace() with an instance of another type did not panic")
+			}
+		}()
+		Replace("TestBrick232", &TestBrick23{})
+	}()
+	if got := Get[*TestBrick232](); got != old {
+		t.Errorf("Get() = %v after a rejected Replace, want the old instance", got)
+	}
+}
+
+type TestBrick233 struct {
+	closed  bool
+	failure error
+}
+
+func (t *TestBrick233) BrickTypeID() string {
+	return "TestBrick233"
+}
+
+func (t *TestBrick233) Close() error {
+	t.closed = true
+	return t.failure
+}
+
+func TestReplaceClosesOld(t *testing.T) {
+	Register[*TestBrick233]()
+	old := Get[*TestBrick233]()
+	old.failure = errors.New("close failed")
+	replacement := &TestBrick233{}
+	if err := Replace("TestBrick233", replacement); err == nil || !strings.Contains(err.Error(), "close failed") {
+		t.Errorf("Replace() error = %v, want the error of the old Close", err)
+	}
+	if !old.closed || replacement.closed {
+		t.Errorf("closed = %v, %v after Replace, want only the old instance closed", old.closed, replacement.closed)
+	}
+	if err := Replace("TestBrick233", replacement); err != nil || replacement.closed {
+		t.Errorf("Replace() with the same instance = %v, closed = %v, want it kept open", err, replacement.closed)
+	}
+}
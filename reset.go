@@ -0,0 +1,122 @@
+package brick
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Reset clears every registration, config and instance of the package-level manager, and restores the default settings,
+// so that tests can run in one process with a clean state. The instances are dropped without being closed,
+// call Shutdown first to close them.
+func Reset() {
+	brickManager.Reset()
+}
+
+// Reset clears every registration, config and instance of the manager, and restores the default settings.
+func (b *BrickManager) Reset() {
+	// The locks are taken in the order of the fields.
+	locks := []sync.Locker{
+		&b.brickConfigLock,
+		&b.instancesLock,
+		&b.brickFactoriesLock,
+		&b.implBindingsLock,
+		&b.initDependenciesLock,
+		&b.configValidatorsLock,
+		&b.tagModifiersLock,
+		&b.secretProvidersLock,
+		&b.parentLock,
+		&b.liveFactoriesLock,
+		&b.brickCtxFactoriesLock,
+		&b.brickTypeIDMapLock,
+		&b.typeEdgesLock,
+		&b.brickFieldsLock,
+		&b.brickLivesLock,
+		&b.liveIDTypeMapLock,
+		&b.declaredLiveIDsLock,
+		&b.randSourceLock,
+		&b.configsLock,
+		&b.liveIDAliasesLock,
+		&b.builtConfigsLock,
+		&b.disabledTypesLock,
+		&b.providersLock,
+		&b.deferredBuildsLock,
+		&b.configMergeKeysLock,
+		&b.configTransformsLock,
+		&b.selectorsLock,
+		&b.cleanupsLock,
+		&b.shutdownLock,
+		&b.startLock,
+		&b.goroutineScopesLock,
+		&b.buildStatsLock,
+		&b.inFlightBuildsLock,
+		&b.fallbackFactoriesLock,
+		&b.degradedLock,
+		&b.instanceHashesLock,
+		&b.prototypesLock,
+		&b.dedupInstancesLock,
+		&b.scopedTypesLock,
+		&b.bindingWarningsLock,
+	}
+	for _, lock := range locks {
+		lock.Lock()
+	}
+	defer func() {
+		for i := len(locks) - 1; i >= 0; i-- {
+			locks[i].Unlock()
+		}
+	}()
+
+	b.brickConfigs = make(map[string]BrickConfig)
+	b.instances = make(map[string]reflect.Value)
+	b.buildOrder = nil
+	b.generations = make(map[string]uint64)
+	b.brickFactories = make(map[string]func(config any) Brick)
+	b.implBindings = make(map[reflect.Type]string)
+	b.initDependencies = make(map[string][]string)
+	b.configValidators = make(map[string][]configKeyValidator)
+	b.tagModifiers = make(map[string]func(ctx InjectContext, field reflect.Value, arg string) error)
+	b.secretProviders = make(map[string]func() string)
+	b.parent = nil
+	b.liveFactories = make(map[string]func(jsonConfig []byte) Brick)
+	b.brickCtxFactories = make(map[string]func(ctx context.Context, config any) (Brick, error))
+	b.brickTypeIDMap1 = make(map[reflect.Type]string)
+	b.brickTypeIDMap2 = make(map[string]reflect.Type)
+	b.typeEdges = make(map[string][]string)
+	b.brickFieldCache = make(map[reflect.Type][]brickField)
+	b.brickLives = make(map[string][]Live)
+	b.liveIDTypeMap = make(map[string]reflect.Type)
+	b.declaredLiveIDs = make(map[string]bool)
+	b.randSource = nil
+	b.brickConfigCheckOnce = sync.Once{}
+	b.configs = make([]*ConfigManager, 0, 1)
+	b.liveIDConstraint = true
+	b.valueCopyChecks.Store(false)
+	b.failFastOnConfig.Store(false)
+	b.activeProfiles = nil
+	b.flagResolver = nil
+	b.liveIDAliases = make(map[string]string)
+	b.builtConfigs = make(map[string][]byte)
+	b.disabledTypes = make(map[string]bool)
+	b.disabledFallback = make(map[string]reflect.Value)
+	b.providers = make(map[string]func() reflect.Value)
+	b.deferredBuilds = make(map[string]*deferredBuild)
+	b.configMergeKeys = make(map[string]map[string]string)
+	b.configTransforms = nil
+	b.selectors = make(map[reflect.Type]func(ctx context.Context) string)
+	b.cleanups = nil
+	b.closedBricks = make(map[closedBrickKey]reflect.Value)
+	b.startedBricks = make(map[closedBrickKey]reflect.Value)
+	b.goroutineScopes = make(map[uint64]*Scope)
+	b.buildStats = make(map[string]*BuildStat)
+	b.inFlightBuilds = make(map[string]int)
+	b.fallbackFactories = make(map[string]func() Brick)
+	b.degraded = make(map[string]bool)
+	b.mutationDetection.Store(false)
+	b.instanceHashes = make(map[string]uint64)
+	b.bindingWarnings = make(map[string]bool)
+	b.prototypes = make(map[string]bool)
+	b.configDedup.Store(false)
+	b.dedupInstances = make(map[dedupKey]dedupInstance)
+	b.scopedTypes = make(map[string]bool)
+}
@@ -0,0 +1,55 @@
+package brick
+
+import (
+	"reflect"
+	"testing"
+)
+
+type TestBrick66 struct{}
+
+func (t *TestBrick66) BrickTypeID() string {
+	return "TestBrick66"
+}
+
+func TestReset(t *testing.T) {
+	m := NewBrickManager()
+	m.register2("TestBrick66", reflect.TypeOf(&TestBrick66{}))
+	m.RegisterLiveIDType("TestBrick66 b", reflect.TypeOf(&TestBrick66{}))
+	m.liveIDConstraint = false
+	configs, err := parseConfigYaml([]byte(`
+- metaData:
+    typeID: TestBrick66
+  lives:
+    - liveID: TestBrick66 a
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.addConfig(configs); err != nil {
+		t.Fatal(err)
+	}
+	m.saveBrickInstance("TestBrick66 a", reflect.ValueOf(&TestBrick66{}))
+
+	m.Reset()
+	if _, ok := m.getBrickType("TestBrick66"); ok {
+		t.Error("the brick type is still registered after Reset")
+	}
+	if _, ok := m.getBrickConfig("TestBrick66 a"); ok {
+		t.Error("the config is still added after Reset")
+	}
+	if _, ok := m.getBrickFromExist("TestBrick66 a"); ok {
+		t.Error("the instance still exists after Reset")
+	}
+	if m.isBoundLiveID("TestBrick66 b") || m.getDeclaredLiveID("TestBrick66 a") {
+		t.Error("the liveIDs are still known after Reset")
+	}
+	if !m.liveIDConstraint {
+		t.Error("the liveID constraint is not restored by Reset")
+	}
+
+	// The manager is usable again.
+	m.register2("TestBrick66", reflect.TypeOf(&TestBrick66{}))
+	if _, ok := m.getBrickType("TestBrick66"); !ok {
+		t.Error("the brick type can't be registered after Reset")
+	}
+}
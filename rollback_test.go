@@ -0,0 +1,100 @@
+package brick
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var testBrick49Closed int
+
+type TestBrick49 struct{}
+
+func (t *TestBrick49) BrickTypeID() string {
+	return "TestBrick49"
+}
+
+func (t *TestBrick49) Close() error {
+	testBrick49Closed++
+	return nil
+}
+
+type TestBrick491 struct{}
+
+func (t *TestBrick491) BrickTypeID() string {
+	return "TestBrick491"
+}
+
+func (t *TestBrick491) NewBrick(config []byte) Brick {
+	panic(errors.New("connection refused"))
+}
+
+var testBrick492Closed int
+
+type TestBrick492 struct {
+	Dep    *TestBrick49  `brick:""`
+	Broken *TestBrick491 `brick:""`
+}
+
+func (t *TestBrick492) BrickTypeID() string {
+	return "TestBrick492"
+}
+
+func (t *TestBrick492) Close() error {
+	testBrick492Closed++
+	return errors.New("close of a half-built brick")
+}
+
+func TestBuildRollback(t *testing.T) {
+	Register[*TestBrick492]()
+	RegisterNewer[*TestBrick491]()
+
+	func() {
+		defer func() {
+			err, _ := recover().(error)
+			if err == nil {
+				t.Fatal("Get did not panic")
+			}
+			msg := err.Error()
+			if !strings.Contains(msg, "connection refused") || !strings.Contains(msg, "close of a half-built brick") {
+				t.Errorf("Get() panic = %v, want the build error joined with the error of the rollback", err)
+			}
+		}()
+		Get[*TestBrick492]()
+	}()
+	if testBrick492Closed != 1 {
+		t.Errorf("TestBrick492 closed %d times, want the instance of the failed build closed once", testBrick492Closed)
+	}
+	// The dependency built by the failed build is published, other callers may hold it.
+	if testBrick49Closed != 0 {
+		t.Errorf("TestBrick49 closed %d times, want the published dependency kept", testBrick49Closed)
+	}
+	if built := BuiltLiveIDs(); !slices.Contains(built, "TestBrick49") || slices.Contains(built, "TestBrick492") {
+		t.Errorf("BuiltLiveIDs() = %v, want only the published dependency", built)
+	}
+}
+
+type TestBrick493 struct {
+	Broken *TestBrick491 `brick:""`
+}
+
+func (t *TestBrick493) BrickTypeID() string {
+	return "TestBrick493"
+}
+
+func (t *TestBrick493) Close() error {
+	panic("close panic")
+}
+
+func TestBuildRollbackClosePanic(t *testing.T) {
+	Register[*TestBrick493]()
+	RegisterNewer[*TestBrick491]()
+	defer func() {
+		err, _ := recover().(error)
+		if err == nil || !strings.Contains(err.Error(), "connection refused") || !strings.Contains(err.Error(), "close panic") {
+			t.Errorf("Get() panic = %v, want the build error joined with the panic of Close", err)
+		}
+	}()
+	Get[*TestBrick493]()
+}
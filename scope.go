@@ -0,0 +1,131 @@
+package brick
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Scope holds the instances of the request-scoped bricks (see RegisterScoped) got in it, e.g. for one HTTP request:
+// a request-scoped brick is a singleton within a scope, and a new instance is built in every scope.
+// The other bricks are the global singletons, a global brick can't depend on a request-scoped one.
+// A Scope is safe for concurrent use, Close it at the end of the request to close its instances.
+type Scope struct {
+	mu        sync.Mutex
+	instances map[string]reflect.Value
+	// order is the construction order of the liveIDs of the instances.
+	order  []string
+	closed bool
+	// group builds an instance of a liveID once when it is got concurrently.
+	group singleflight.Group
+}
+
+// NewScope returns an empty Scope.
+func NewScope() *Scope {
+	return &Scope{instances: make(map[string]reflect.Value)}
+}
+
+// RegisterScoped like Register, or like RegisterNewer if T implements BrickNewer, but the brick is request-scoped:
+// it can only be got in a Scope, by GetInScope or by the bricks built in the scope.
+func RegisterScoped[T Brick]() {
+	var instance T
+	if _, ok := any(instance).(BrickNewer); ok {
+		registerNewerType(reflect.TypeOf((*T)(nil)).Elem())
+	} else {
+		Register[T]()
+	}
+	typeID := GetBrickTypeID[T]()
+	brickManager.scopedTypesLock.Lock()
+	defer brickManager.scopedTypesLock.Unlock()
+	brickManager.scopedTypes[typeID] = true
+}
+
+// GetInScope like Get, but the request-scoped bricks, including the requested one and its dependencies,
+// are the instances of the scope, built at their first get in it.
+func GetInScope[T Brick](scope *Scope, liveID ...string) T {
+	brickManager.brickConfigCheckOnce.Do(brickManager.checkConfig)
+	ctx := getBrickInstanceCtx{
+		buildingBrick: make(map[reflect.Type]bool),
+		createUnknown: false,
+		scope:         scope,
+	}
+	return getBrickInstance(reflect.TypeOf((*(new(T)))), ctx, liveID...).Interface().(T)
+}
+
+// GetOrCreateInScope like GetInScope, but it will create a new instance for unknown liveID.
+func GetOrCreateInScope[T Brick](scope *Scope, liveID ...string) T {
+	brickManager.brickConfigCheckOnce.Do(brickManager.checkConfig)
+	ctx := getBrickInstanceCtx{
+		buildingBrick: make(map[reflect.Type]bool),
+		createUnknown: true,
+		scope:         scope,
+	}
+	return getBrickInstance(reflect.TypeOf((*(new(T)))), ctx, liveID...).Interface().(T)
+}
+
+// Close closes the instances of the scope implementing BrickCloser in reverse construction order,
+// and drops them. The scope can't be used once closed.
+func (s *Scope) Close() error {
+	s.mu.Lock()
+	order, instances := s.order, s.instances
+	s.order, s.instances, s.closed = nil, nil, true
+	s.mu.Unlock()
+	var errs []error
+	for i := len(order) - 1; i >= 0; i-- {
+		if closer, ok := asInterface[BrickCloser](instances[order[i]]); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close scoped brick of liveID(%s): %w", order[i], err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// instance returns the instance of the liveID built in the scope.
+func (s *Scope) instance(liveID string) (reflect.Value, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		panic(fmt.Errorf("the scope is closed, can't get liveID(%s) in it", liveID))
+	}
+	instance, ok := s.instances[liveID]
+	return instance, ok
+}
+
+// build builds the instance of the liveID once, and saves it in the scope.
+func (s *Scope) build(liveID string, build func() (any, error)) reflect.Value {
+	// The panic of the build is re-panicked in every caller, like the builds of the global instances.
+	v, err, _ := s.group.Do(liveID, func() (v any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = newBuildPanic(r)
+			}
+		}()
+		if instance, ok := s.instance(liveID); ok {
+			return instance, nil
+		}
+		v, _ = build()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.closed {
+			panic(fmt.Errorf("the scope is closed while building liveID(%s)", liveID))
+		}
+		s.instances[liveID] = v.(reflect.Value)
+		s.order = append(s.order, liveID)
+		return v, nil
+	})
+	if p, ok := err.(*buildPanic); ok {
+		panic(p)
+	}
+	return v.(reflect.Value)
+}
+
+// isScoped reports whether the brick type of typeID is registered by RegisterScoped.
+func (b *BrickManager) isScoped(typeID string) bool {
+	b.scopedTypesLock.RLock()
+	defer b.scopedTypesLock.RUnlock()
+	return b.scopedTypes[typeID]
+}
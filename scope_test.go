@@ -0,0 +1,113 @@
+package brick
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+type TestBrick102 struct {
+	Closed bool
+}
+
+func (t *TestBrick102) BrickTypeID() string {
+	return "TestBrick102"
+}
+
+func (t *TestBrick102) Close() error {
+	t.Closed = true
+	return nil
+}
+
+type TestBrick1021 struct {
+	Request *TestBrick102  `brick:""`
+	Global  *TestBrick1022 `brick:""`
+}
+
+func (t *TestBrick1021) BrickTypeID() string {
+	return "TestBrick1021"
+}
+
+type TestBrick1022 struct {
+	Name string
+}
+
+func (t *TestBrick1022) BrickTypeID() string {
+	return "TestBrick1022"
+}
+
+// TestBrick1023 is a global brick depending on a request-scoped one.
+type TestBrick1023 struct {
+	Request *TestBrick102 `brick:""`
+}
+
+func (t *TestBrick1023) BrickTypeID() string {
+	return "TestBrick1023"
+}
+
+func TestScope(t *testing.T) {
+	RegisterScoped[*TestBrick102]()
+	RegisterScoped[*TestBrick1021]()
+	Register[*TestBrick1023]()
+
+	s1, s2 := NewScope(), NewScope()
+	request := GetInScope[*TestBrick102](s1)
+	if GetInScope[*TestBrick102](s1) != request {
+		t.Error("a request-scoped brick is not a singleton of its scope")
+	}
+	if GetInScope[*TestBrick102](s2) == request {
+		t.Error("two scopes share a request-scoped brick")
+	}
+	service := GetInScope[*TestBrick1021](s1)
+	if service.Request != request {
+		t.Error("the request-scoped dependency is not the instance of the scope")
+	}
+	if service.Global != Get[*TestBrick1022]() {
+		t.Error("the global dependency of a request-scoped brick is not the global singleton")
+	}
+
+	if _, err := TryGet[*TestBrick102](); err == nil || !strings.Contains(err.Error(), "request-scoped") {
+		t.Errorf("TryGet() of a request-scoped brick error = %v", err)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r == nil || !strings.Contains(r.(error).Error(), "request-scoped") {
+				t.Errorf("GetInScope() of a global brick depending on a request-scoped one panic = %v", r)
+			}
+		}()
+		GetInScope[*TestBrick1023](s1)
+	}()
+
+	s3 := NewScope()
+	var wg sync.WaitGroup
+	results := make([]*TestBrick1021, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = GetInScope[*TestBrick1021](s3)
+		}()
+	}
+	wg.Wait()
+	for _, got := range results {
+		if got != results[0] {
+			t.Fatal("the concurrent gets in a scope build more than one instance")
+		}
+	}
+
+	if err := s1.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !request.Closed {
+		t.Error("Close() doesn't close the instances of the scope")
+	}
+	if GetInScope[*TestBrick102](s2).Closed {
+		t.Error("Close() closes the instances of another scope")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("GetInScope() of a closed scope should panic")
+		}
+	}()
+	GetInScope[*TestBrick102](s1)
+}
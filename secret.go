@@ -0,0 +1,88 @@
+package brick
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// secretTagPrefix is the tag prefix to inject a secret, e.g. `brick:"secret:DB_TOKEN"`.
+const secretTagPrefix = "secret:"
+
+// refreshTag is the tag option to resolve a secret on every access instead of once, e.g. `brick:"secret:DB_TOKEN,refresh"`.
+const refreshTag = "refresh"
+
+// Secret is a secret value injected into a field tagged with `brick:"secret:KEY"`.
+// The value comes from the provider registered for KEY by RegisterSecretProvider, or else the environment variable KEY.
+// With the `refresh` option, e.g. `brick:"secret:KEY,refresh"`, it is resolved on every call of Value,
+// so short-lived credentials are re-read after they are rotated, otherwise it is resolved once at injection.
+//
+// The field can also be a `func() string`, which returns the value like Value.
+type Secret struct {
+	value func() string
+}
+
+// Value returns the value of the secret, empty if the secret is not injected.
+func (s Secret) Value() string {
+	if s.value == nil {
+		return ""
+	}
+	return s.value()
+}
+
+// String masks the value, so the secret is not printed by accident.
+func (s Secret) String() string {
+	return maskedValue
+}
+
+// RegisterSecretProvider registers the provider of the secret KEY, which takes precedence over the environment variable.
+func RegisterSecretProvider(key string, provider func() string) {
+	brickManager.secretProvidersLock.Lock()
+	defer brickManager.secretProvidersLock.Unlock()
+	if _, ok := brickManager.secretProviders[key]; ok {
+		panic(fmt.Errorf("secret provider(%s) already registered", key))
+	}
+	brickManager.secretProviders[key] = provider
+}
+
+// secretTagKey returns the key of a `secret:KEY` liveID.
+func secretTagKey(liveID string) (string, bool) {
+	return strings.CutPrefix(liveID, secretTagPrefix)
+}
+
+// resolveSecret returns the value of the secret from its provider, or else the environment variable.
+func (b *BrickManager) resolveSecret(key string) string {
+	b.secretProvidersLock.RLock()
+	provider, ok := b.secretProviders[key]
+	b.secretProvidersLock.RUnlock()
+	if ok {
+		return provider()
+	}
+	return os.Getenv(key)
+}
+
+var (
+	secretType     = reflect.TypeOf(Secret{})
+	secretFuncType = reflect.TypeOf(func() string { return "" })
+)
+
+// `brick:"secret:KEY"` or `brick:"secret:KEY,refresh"`
+func injectSecret(valueField reflect.Value, key string, refresh bool) {
+	value := func() string { return brickManager.resolveSecret(key) }
+	if !refresh {
+		value = sync.OnceValue(value)
+		value()
+	}
+	switch valueField.Type() {
+	case secretType:
+		valueField.Set(reflect.ValueOf(Secret{value: value}))
+	case reflect.PointerTo(secretType):
+		valueField.Set(reflect.ValueOf(&Secret{value: value}))
+	case secretFuncType:
+		valueField.Set(reflect.ValueOf(value))
+	default:
+		panic(fmt.Errorf("secret(%s) can't be injected into field type %v, want brick.Secret or func() string", key, valueField.Type()))
+	}
+}
@@ -0,0 +1,49 @@
+package brick
+
+import (
+	"fmt"
+	"testing"
+)
+
+type TestBrick59 struct {
+	Token      Secret        `brick:"secret:TEST_BRICK59_TOKEN,refresh"`
+	TokenOnce  *Secret       `brick:"secret:TEST_BRICK59_TOKEN"`
+	TokenFunc  func() string `brick:"secret:TEST_BRICK59_TOKEN,refresh"`
+	IAMToken   Secret        `brick:"secret:TEST_BRICK59_IAM,refresh"`
+	NotPrinted Secret        `brick:"secret:TEST_BRICK59_TOKEN"`
+}
+
+func (t *TestBrick59) BrickTypeID() string {
+	return "TestBrick59"
+}
+
+func TestSecret(t *testing.T) {
+	t.Setenv("TEST_BRICK59_TOKEN", "token1")
+	iam := 0
+	RegisterSecretProvider("TEST_BRICK59_IAM", func() string {
+		iam++
+		return fmt.Sprintf("iam%d", iam)
+	})
+	Register[*TestBrick59]()
+	b := Get[*TestBrick59]()
+
+	t.Setenv("TEST_BRICK59_TOKEN", "token2")
+	if got := b.Token.Value(); got != "token2" {
+		t.Errorf("Token.Value() = %q, want the refreshed env value", got)
+	}
+	if got := b.TokenFunc(); got != "token2" {
+		t.Errorf("TokenFunc() = %q, want the refreshed env value", got)
+	}
+	if got := b.TokenOnce.Value(); got != "token1" {
+		t.Errorf("TokenOnce.Value() = %q, want the value at injection", got)
+	}
+	if first, second := b.IAMToken.Value(), b.IAMToken.Value(); first != "iam1" || second != "iam2" {
+		t.Errorf("IAMToken.Value() = %q, %q, want the provider called on every access", first, second)
+	}
+	if got := fmt.Sprint(b.NotPrinted); got == "token1" || got == "token2" {
+		t.Errorf("fmt.Sprint(Secret) = %q, want the value masked", got)
+	}
+	if tag := ParseBrickTag("secret:TEST_BRICK59_TOKEN,refresh"); tag.Secret != "TEST_BRICK59_TOKEN" || !tag.Refresh || tag.LiveID != "" {
+		t.Errorf("ParseBrickTag() = %+v, want the secret key and refresh", tag)
+	}
+}
@@ -0,0 +1,54 @@
+package brick
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// RegisterSelector registers the selector of the interface type I.
+// A field of type I tagged with `brick:",selected"` is injected with the live whose liveID is returned by the selector.
+//
+// The selector receives the context passed to GetCtx/GetOrCreateCtx, or context.Background() if the brick is built without one.
+// The selection happens when the dependent brick is built, so a cached dependent keeps its first selection,
+// use GetOrCreateCtx with a new liveID to build a dependent per request.
+func RegisterSelector[I any](selector func(ctx context.Context) string) {
+	typ := reflect.TypeOf((*I)(nil)).Elem()
+	if typ.Kind() != reflect.Interface {
+		panic(fmt.Errorf("selector type %v is not an interface", typ))
+	}
+	brickManager.selectorsLock.Lock()
+	defer brickManager.selectorsLock.Unlock()
+	if _, ok := brickManager.selectors[typ]; ok {
+		panic(fmt.Errorf("selector of %v already registered", typ))
+	}
+	brickManager.selectors[typ] = selector
+}
+
+// `brick:",selected"`
+func injectSelectedBrick(valueField reflect.Value, ctx getBrickInstanceCtx) {
+	typ := valueField.Type()
+	brickManager.selectorsLock.RLock()
+	selector, ok := brickManager.selectors[typ]
+	brickManager.selectorsLock.RUnlock()
+	if !ok {
+		panic(fmt.Errorf("no selector is registered for %v", typ))
+	}
+	buildCtx := ctx.buildCtx
+	if buildCtx == nil {
+		buildCtx = context.Background()
+	}
+	liveID := selector(buildCtx)
+	if liveID == "" {
+		panic(fmt.Errorf("the selector of %v returns an empty liveID", typ))
+	}
+	liveID = brickManager.resolveLiveID(liveID)
+	brickType, ok := brickManager.liveIDType(liveID)
+	if !ok {
+		panic(fmt.Errorf("the selector of %v returns liveID(%s), but its type can't be determined", typ, liveID))
+	}
+	if !brickType.Implements(typ) && (brickType.Kind() == reflect.Ptr || !reflect.PointerTo(brickType).Implements(typ)) {
+		panic(fmt.Errorf("the selector of %v returns liveID(%s), but its type %v does not implement it", typ, liveID, brickType))
+	}
+	injectInterfaceBrick(valueField, liveID, ctx)
+}
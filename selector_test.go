@@ -0,0 +1,83 @@
+package brick
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+type TestShard28 interface {
+	Shard() string
+}
+
+type TestBrick28 struct {
+	Name string `json:"name"`
+}
+
+func (t *TestBrick28) BrickTypeID() string {
+	return "TestBrick28"
+}
+
+func (t *TestBrick28) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick28{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func (t *TestBrick28) Shard() string {
+	return t.Name
+}
+
+type TestBrick281 struct {
+	DB TestShard28 `brick:",selected"`
+}
+
+func (t *TestBrick281) BrickTypeID() string {
+	return "TestBrick281"
+}
+
+type testRegionKey struct{}
+
+func TestRegisterSelector(t *testing.T) {
+	RegisterNewer[*TestBrick28]()
+	Register[*TestBrick281]()
+	err := brickManager.addConfigFileJson([]byte(`[{
+		"metaData": {"typeID": "TestBrick28"},
+		"lives": [
+			{"liveID": "TestBrick28", "config": {"name": "us"}},
+			{"liveID": "TestBrick28 eu", "config": {"name": "eu"}}
+		]
+	}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	RegisterSelector[TestShard28](func(ctx context.Context) string {
+		switch ctx.Value(testRegionKey{}) {
+		case "eu":
+			return "TestBrick28 eu"
+		case "invalid":
+			return "TestBrick281"
+		}
+		return "TestBrick28"
+	})
+
+	for region, want := range map[string]string{"eu": "eu", "us": "us"} {
+		ctx := context.WithValue(context.Background(), testRegionKey{}, region)
+		if got := GetOrCreateCtx[*TestBrick281](ctx, RandomLiveID()).DB.Shard(); got != want {
+			t.Errorf("region %s selected shard %s, want %s", region, got, want)
+		}
+	}
+	if got := GetOrCreate[*TestBrick281](RandomLiveID()).DB.Shard(); got != "us" {
+		t.Errorf("build without context selected shard %s, want us", got)
+	}
+
+	defer func() {
+		if err := recover(); err == nil {
+			t.Errorf("expected panic for a selected live not implementing the interface")
+		}
+	}()
+	ctx := context.WithValue(context.Background(), testRegionKey{}, "invalid")
+	GetOrCreateCtx[*TestBrick281](ctx, RandomLiveID())
+}
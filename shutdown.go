@@ -0,0 +1,179 @@
+package brick
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrCloseTimeout is the error recorded for a brick whose Close does not return within the per-brick timeout.
+var ErrCloseTimeout = errors.New("brick close timeout")
+
+// AddCleanup registers a function run by Shutdown after the bricks are closed,
+// for resources that are not bricks (a temp dir to remove, a global to reset).
+// Cleanups run in reverse registration order, each of them once.
+func AddCleanup(fn func() error) {
+	brickManager.AddCleanup(fn)
+}
+
+// AddCleanup registers a function run by Shutdown after the bricks are closed.
+func (b *BrickManager) AddCleanup(fn func() error) {
+	b.cleanupsLock.Lock()
+	defer b.cleanupsLock.Unlock()
+	b.cleanups = append(b.cleanups, fn)
+}
+
+// Shutdown closes the built bricks implementing BrickCloser so that dependents are closed before their dependencies,
+// following the dependency graph of Dependencies, e.g. a brick whose Lazy dependency is built later is still closed first.
+// The bricks unrelated by the graph are closed in reverse build order. Then Shutdown runs the cleanups registered by AddCleanup.
+// The errors of every Close and cleanup are aggregated. Shutdown stops closing bricks when ctx is done,
+// and the error includes ctx.Err(), the cleanups still run.
+//
+// Every brick and cleanup runs at most once, so a second call is a no-op,
+// unless bricks were built or cleanups added after the first one.
+// The bricks of a type disabled by Disable are closed too.
+func Shutdown(ctx context.Context) error {
+	return brickManager.ShutdownTimeout(ctx, 0)
+}
+
+// ShutdownTimeout is like Shutdown, but a Close that does not return within perBrickTimeout is abandoned,
+// an error wrapping ErrCloseTimeout is recorded for its brick, and the shutdown proceeds to the next brick.
+// There is no per-brick timeout if perBrickTimeout is not positive.
+func ShutdownTimeout(ctx context.Context, perBrickTimeout time.Duration) error {
+	return brickManager.ShutdownTimeout(ctx, perBrickTimeout)
+}
+
+// Shutdown closes the built bricks implementing BrickCloser in reverse dependency order.
+func (b *BrickManager) Shutdown(ctx context.Context) error {
+	return b.ShutdownTimeout(ctx, 0)
+}
+
+// ShutdownTimeout closes the built bricks implementing BrickCloser in reverse dependency order, with a timeout for each Close.
+func (b *BrickManager) ShutdownTimeout(ctx context.Context, perBrickTimeout time.Duration) error {
+	b.shutdownLock.Lock()
+	defer b.shutdownLock.Unlock()
+	errs := b.closeBricks(ctx, b.shutdownOrder(), perBrickTimeout)
+	errs = append(errs, b.runCleanups()...)
+	return errors.Join(errs...)
+}
+
+// shutdownOrder returns the built liveIDs ordered so that every live comes after the lives it depends on,
+// from the dependency graph of Dependencies. The lives unrelated by the graph keep their build order.
+func (b *BrickManager) shutdownOrder() []string {
+	built := b.BuildOrder()
+	graph := b.dependencyGraph()
+	isBuilt := make(map[string]bool, len(built))
+	for _, liveID := range built {
+		isBuilt[liveID] = true
+	}
+	order := make([]string, 0, len(built))
+	visited := make(map[string]bool, len(built))
+	var visit func(liveID string)
+	visit = func(liveID string) {
+		if visited[liveID] {
+			return
+		}
+		visited[liveID] = true
+		for _, dep := range graph[liveID] {
+			visit(dep)
+		}
+		if isBuilt[liveID] {
+			order = append(order, liveID)
+		}
+	}
+	for _, liveID := range built {
+		visit(liveID)
+	}
+	return order
+}
+
+// closeBricks closes the bricks of the liveIDs implementing BrickCloser in reverse order, skipping the closed ones.
+// The caller must hold shutdownLock.
+func (b *BrickManager) closeBricks(ctx context.Context, order []string, perBrickTimeout time.Duration) []error {
+	closed := b.closedBricks
+	var errs []error
+	for i := len(order) - 1; i >= 0; i-- {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		liveID := order[i]
+		closer, instance, ok := b.getBrickCloser(liveID)
+		if !ok {
+			continue
+		}
+		key := closedBrickKey{ptr: instance.Pointer(), typ: instance.Type()}
+		if _, done := closed[key]; done {
+			continue
+		}
+		closed[key] = instance
+		if err := closeBrick(ctx, closer, perBrickTimeout); err != nil {
+			errs = append(errs, fmt.Errorf("close brick(%s): %w", liveID, err))
+		}
+	}
+	return errs
+}
+
+// runCleanups runs the registered cleanups in reverse registration order and removes them.
+func (b *BrickManager) runCleanups() []error {
+	b.cleanupsLock.Lock()
+	cleanups := b.cleanups
+	b.cleanups = nil
+	b.cleanupsLock.Unlock()
+	var errs []error
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		if err := cleanups[i](); err != nil {
+			errs = append(errs, fmt.Errorf("cleanup: %w", err))
+		}
+	}
+	return errs
+}
+
+// closedBrickKey identifies an instance closed by Shutdown.
+// The type is part of the key, since the instances of zero-size types may share the same pointer.
+type closedBrickKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// getBrickCloser returns the instance of the liveID as a BrickCloser, and the instance itself, if it implements BrickCloser.
+// Deferred bricks that have not been constructed are not closed.
+func (b *BrickManager) getBrickCloser(liveID string) (BrickCloser, reflect.Value, bool) {
+	b.deferredBuildsLock.RLock()
+	_, pending := b.deferredBuilds[liveID]
+	b.deferredBuildsLock.RUnlock()
+	if pending {
+		return nil, reflect.Value{}, false
+	}
+	instance, ok := b.getBrickFromExist(liveID)
+	if !ok || instance.IsNil() {
+		return nil, reflect.Value{}, false
+	}
+	closer, ok := asInterface[BrickCloser](instance)
+	return closer, instance, ok
+}
+
+// closeBrick calls Close, and returns when it returns, when the per-brick timeout expires, or when ctx is done.
+func closeBrick(ctx context.Context, closer BrickCloser, perBrickTimeout time.Duration) error {
+	parent := ctx
+	if perBrickTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, perBrickTimeout)
+		defer cancel()
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- closer.Close()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if err := parent.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w after %v", ErrCloseTimeout, perBrickTimeout)
+	}
+}
@@ -0,0 +1,173 @@
+package brick
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testBrick27Closed []string
+
+type TestBrick27Slow struct{}
+
+func (t *TestBrick27Slow) BrickTypeID() string {
+	return "TestBrick27Slow"
+}
+
+func (t *TestBrick27Slow) Close() error {
+	time.Sleep(time.Second)
+	return nil
+}
+
+type TestBrick27Failing struct {
+	Slow *TestBrick27Slow `brick:""`
+}
+
+func (t *TestBrick27Failing) BrickTypeID() string {
+	return "TestBrick27Failing"
+}
+
+func (t *TestBrick27Failing) Close() error {
+	testBrick27Closed = append(testBrick27Closed, "TestBrick27Failing")
+	return errors.New("failing close")
+}
+
+type TestBrick27 struct {
+	Failing *TestBrick27Failing `brick:""`
+}
+
+func (t *TestBrick27) BrickTypeID() string {
+	return "TestBrick27"
+}
+
+func (t *TestBrick27) Close() error {
+	testBrick27Closed = append(testBrick27Closed, "TestBrick27")
+	return nil
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	Register[*TestBrick27]()
+	Get[*TestBrick27]()
+
+	start := time.Now()
+	err := ShutdownTimeout(context.Background(), 50*time.Millisecond)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("shutdown took %v, want it bounded by the per-brick timeout", elapsed)
+	}
+	if !errors.Is(err, ErrCloseTimeout) || !strings.Contains(err.Error(), "close brick(TestBrick27Slow)") {
+		t.Errorf("error = %v, want a timeout error for TestBrick27Slow", err)
+	}
+	if !strings.Contains(err.Error(), "close brick(TestBrick27Failing): failing close") {
+		t.Errorf("error = %v, want the close error of TestBrick27Failing", err)
+	}
+	if want := []string{"TestBrick27", "TestBrick27Failing"}; !slices.Equal(testBrick27Closed, want) {
+		t.Errorf("closed = %v, want %v", testBrick27Closed, want)
+	}
+}
+
+var testBrick31Order []string
+
+type TestBrick31 struct{}
+
+func (t *TestBrick31) BrickTypeID() string {
+	return "TestBrick31"
+}
+
+func (t *TestBrick31) Close() error {
+	testBrick31Order = append(testBrick31Order, "TestBrick31")
+	return nil
+}
+
+func TestAddCleanup(t *testing.T) {
+	Register[*TestBrick31]()
+	Get[*TestBrick31]()
+	errCleanup := errors.New("cleanup failed")
+	for _, name := range []string{"first", "second", "third"} {
+		AddCleanup(func() error {
+			testBrick31Order = append(testBrick31Order, name)
+			if name == "second" {
+				return errCleanup
+			}
+			return nil
+		})
+	}
+
+	err := ShutdownTimeout(context.Background(), 50*time.Millisecond)
+	if !errors.Is(err, errCleanup) {
+		t.Errorf("error = %v, want the cleanup error", err)
+	}
+	if want := []string{"TestBrick31", "third", "second", "first"}; !slices.Equal(testBrick31Order, want) {
+		t.Errorf("order = %v, want %v", testBrick31Order, want)
+	}
+}
+
+var testBrick54Closed int
+
+type TestBrick54 struct{}
+
+func (t *TestBrick54) BrickTypeID() string {
+	return "TestBrick54"
+}
+
+func (t *TestBrick54) Close() error {
+	testBrick54Closed++
+	return nil
+}
+
+func TestShutdownTwice(t *testing.T) {
+	Register[*TestBrick54]()
+	Get[*TestBrick54]()
+
+	_ = Shutdown(context.Background())
+	if testBrick54Closed != 1 {
+		t.Fatalf("closed %d times after the first Shutdown, want 1", testBrick54Closed)
+	}
+	if err := Shutdown(context.Background()); err != nil {
+		t.Errorf("second Shutdown() error = %v, want nil", err)
+	}
+	if testBrick54Closed != 1 {
+		t.Errorf("closed %d times after the second Shutdown, want 1", testBrick54Closed)
+	}
+}
+
+var testBrick109Closed []string
+
+type TestBrick109 struct {
+	Dep Lazy[*TestBrick1091] `brick:""`
+}
+
+func (t *TestBrick109) BrickTypeID() string {
+	return "TestBrick109"
+}
+
+func (t *TestBrick109) Close() error {
+	testBrick109Closed = append(testBrick109Closed, "TestBrick109")
+	return nil
+}
+
+type TestBrick1091 struct{}
+
+func (t *TestBrick1091) BrickTypeID() string {
+	return "TestBrick1091"
+}
+
+func (t *TestBrick1091) Close() error {
+	testBrick109Closed = append(testBrick109Closed, "TestBrick1091")
+	return nil
+}
+
+func TestShutdownDependencyOrder(t *testing.T) {
+	Register[*TestBrick109]()
+	b := Get[*TestBrick109]()
+	// The lazy dependency is built after its dependent.
+	b.Dep.Get()
+
+	_ = Shutdown(context.Background())
+	want := []string{"TestBrick109", "TestBrick1091"}
+	if !slices.Equal(testBrick109Closed, want) {
+		t.Errorf("closed %v, want %v", testBrick109Closed, want)
+	}
+}
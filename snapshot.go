@@ -0,0 +1,82 @@
+package brick
+
+import "fmt"
+
+// ConfigState is a copy of the configurations of every live, made by ConfigSnapshot.
+type ConfigState struct {
+	// configs are the configurations indexed by LiveID, nil if the state is not made by ConfigSnapshot.
+	configs map[string]BrickConfig
+}
+
+// ConfigSnapshot returns a copy of the current configurations, to restore them by RollbackConfig,
+// e.g. before a reload that may turn out bad. The maps and slices of the configurations are copied,
+// so later changes of the configurations don't affect the snapshot.
+func ConfigSnapshot() ConfigState {
+	return brickManager.ConfigSnapshot()
+}
+
+// RollbackConfig restores the configurations of the snapshot, like a reload of every config file.
+// The built instances of the liveIDs whose configuration changed that implement BrickReloader are given
+// their restored config. The other ones, and their transitive dependents, are dropped,
+// so they are rebuilt with the restored configuration at the next Get like ApplyReload, without being closed.
+// A ReloadEvent without FilePath is sent to ConfigReloadEvents.
+// The config files are not rewritten, reloading a file applies its content again.
+func RollbackConfig(snapshot ConfigState) error {
+	return brickManager.RollbackConfig(snapshot)
+}
+
+// ConfigSnapshot returns a copy of the current configurations.
+func (b *BrickManager) ConfigSnapshot() ConfigState {
+	configs := b.snapshotBrickConfigs()
+	for liveID, config := range configs {
+		config.Config = copyConfig(config.Config)
+		configs[liveID] = config
+	}
+	return ConfigState{configs: configs}
+}
+
+// RollbackConfig restores the configurations of the snapshot, and reloads or drops the instances built with the changed ones.
+func (b *BrickManager) RollbackConfig(snapshot ConfigState) error {
+	if snapshot.configs == nil {
+		return fmt.Errorf("the config state is not made by ConfigSnapshot")
+	}
+	configs := make(map[string]BrickConfig, len(snapshot.configs))
+	for liveID, config := range snapshot.configs {
+		// The snapshot is copied again, so it can be restored more than once.
+		config.Config = copyConfig(config.Config)
+		configs[liveID] = config
+	}
+	staged := newStagedReload("", b.snapshotBrickConfigs(), configs)
+	if len(staged.changed) == 0 {
+		return nil
+	}
+
+	// The dependents are found in the wiring of the built instances, before the rollback.
+	var reloaders []string
+	rebuild := make(map[string]bool)
+	for _, liveID := range staged.changed {
+		if instance, ok := b.getBrickFromExist(liveID); ok {
+			if _, ok := asInterface[BrickReloader](instance); ok && configs[liveID].TypeID == staged.oldConfigs[liveID].TypeID {
+				reloaders = append(reloaders, liveID)
+				continue
+			}
+		}
+		rebuild[liveID] = true
+		for _, dependent := range b.Dependents(liveID) {
+			rebuild[dependent] = true
+		}
+	}
+
+	b.applyReload(staged)
+	for _, liveID := range b.BuiltLiveIDs() {
+		if !rebuild[liveID] {
+			continue
+		}
+		if instance, ok := b.getBrickFromExist(liveID); ok {
+			b.deleteBrickInstance(liveID, instance)
+		}
+	}
+	err := b.notifyReloaders(reloaders)
+	b.emitReloadEvent(ReloadEvent{ChangedLiveIDs: staged.changed, Err: err})
+	return err
+}
@@ -0,0 +1,148 @@
+package brick
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+type TestBrick92 struct {
+	Host string `json:"host"`
+}
+
+func (t *TestBrick92) BrickTypeID() string {
+	return "TestBrick92"
+}
+
+func (t *TestBrick92) NewBrick(config []byte) Brick {
+	var newBrick = &TestBrick92{}
+	if err := json.Unmarshal(config, newBrick); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+type TestBrick921 struct {
+	DB *TestBrick92 `brick:""`
+}
+
+func (t *TestBrick921) BrickTypeID() string {
+	return "TestBrick921"
+}
+
+func TestRollbackConfig(t *testing.T) {
+	RegisterNewer[*TestBrick92]()
+	Register[*TestBrick921]()
+	path := filepath.Join(t.TempDir(), "db.json")
+	writeConfig := func(host string) {
+		content := `[{"metaData": {"typeID": "TestBrick92"}, "lives": [{"liveID": "TestBrick92", "config": {"host": "` + host + `"}}]}]`
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig("good")
+	if err := AddConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := Get[*TestBrick921]().DB.Host; got != "good" {
+		t.Fatalf("Host = %s, want good", got)
+	}
+	snapshot := ConfigSnapshot()
+
+	writeConfig("bad")
+	plan, err := PlanReload(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ApplyReload(plan); err != nil {
+		t.Fatal(err)
+	}
+	if got := Get[*TestBrick921]().DB.Host; got != "bad" {
+		t.Fatalf("Host = %s, want bad after the reload", got)
+	}
+	// A mutation of the current config doesn't corrupt the snapshot.
+	config, _ := brickManager.getBrickConfig("TestBrick92")
+	config.Config.(map[string]any)["host"] = "mutated"
+
+	if err := RollbackConfig(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	dependent := Get[*TestBrick921]()
+	if got := dependent.DB.Host; got != "good" {
+		t.Errorf("Host = %s, want good after the rollback", got)
+	}
+	if err := RollbackConfig(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if Get[*TestBrick921]() != dependent {
+		t.Error("a rollback without change rebuilds the instances")
+	}
+	if err := RollbackConfig(ConfigState{}); err == nil {
+		t.Error("RollbackConfig() of a zero state succeeds")
+	}
+}
+
+type TestBrick922 struct {
+	mu   sync.Mutex
+	host string
+}
+
+func (t *TestBrick922) BrickTypeID() string {
+	return "TestBrick922"
+}
+
+func (t *TestBrick922) NewBrick(config []byte) Brick {
+	newBrick := &TestBrick922{}
+	if err := newBrick.BrickReload(config); err != nil {
+		panic(err)
+	}
+	return newBrick
+}
+
+func (t *TestBrick922) BrickReload(config []byte) error {
+	var c struct {
+		Host string `json:"host"`
+	}
+	if err := json.Unmarshal(config, &c); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.host = c.Host
+	return nil
+}
+
+func TestRollbackConfigNotifiesReloaders(t *testing.T) {
+	RegisterNewer[*TestBrick922]()
+	if err := AddLive("TestBrick922", "TestBrick922", map[string]any{"host": "good"}); err != nil {
+		t.Fatal(err)
+	}
+	reloader := Get[*TestBrick922]()
+	snapshot := ConfigSnapshot()
+	// Drop the events of the previous tests.
+	for len(ConfigReloadEvents()) > 0 {
+		<-ConfigReloadEvents()
+	}
+	if err := brickManager.saveBrickConfig("TestBrick922", "TestBrick922", []byte(`{"host": "bad"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RollbackConfig(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if Get[*TestBrick922]() != reloader {
+		t.Fatal("the reloader is rebuilt by the rollback")
+	}
+	reloader.mu.Lock()
+	host := reloader.host
+	reloader.mu.Unlock()
+	if host != "good" {
+		t.Errorf("host = %s, want the restored config", host)
+	}
+	if event := waitReloadEvent(t, ""); !slices.Contains(event.ChangedLiveIDs, "TestBrick922") {
+		t.Errorf("ChangedLiveIDs = %v, want TestBrick922", event.ChangedLiveIDs)
+	}
+}
@@ -0,0 +1,74 @@
+package brick
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// StartAll calls Start on the built bricks implementing BrickStarter in build order,
+// so dependencies start before their dependents. Get only constructs and injects the bricks, StartAll begins serving.
+// Every brick starts at most once, a later call only starts the bricks built since.
+// The bricks of a type disabled by Disable are not started.
+//
+// If a Start fails, the remaining bricks are not started, and the bricks started by this call implementing BrickCloser
+// are closed in reverse order like Shutdown. The error includes the errors of these Close.
+// The failed brick and the bricks closed are started again by a later call, e.g. a retry.
+func StartAll(ctx context.Context) error {
+	return brickManager.StartAll(ctx)
+}
+
+// StartAll calls Start on the built bricks implementing BrickStarter in build order.
+func (b *BrickManager) StartAll(ctx context.Context) error {
+	b.startLock.Lock()
+	defer b.startLock.Unlock()
+	var started []string
+	var startedKeys []closedBrickKey
+	for _, liveID := range b.BuildOrder() {
+		starter, instance, ok := b.getBrickStarter(liveID)
+		if !ok || b.isDisabledInstance(instance) {
+			continue
+		}
+		key := closedBrickKey{ptr: instance.Pointer(), typ: instance.Type()}
+		if _, done := b.startedBricks[key]; done {
+			continue
+		}
+		if err := starter.Start(ctx); err != nil {
+			errs := []error{fmt.Errorf("start brick(%s): %w", liveID, err)}
+			b.shutdownLock.Lock()
+			errs = append(errs, b.closeBricks(ctx, started, 0)...)
+			b.shutdownLock.Unlock()
+			// The bricks rolled back are started again by the next call.
+			for _, key := range startedKeys {
+				delete(b.startedBricks, key)
+			}
+			return errors.Join(errs...)
+		}
+		b.startedBricks[key] = instance
+		// A brick closed by the rollback of a previous call is closed again by Shutdown.
+		b.shutdownLock.Lock()
+		delete(b.closedBricks, key)
+		b.shutdownLock.Unlock()
+		started = append(started, liveID)
+		startedKeys = append(startedKeys, key)
+	}
+	return nil
+}
+
+// getBrickStarter returns the instance of the liveID as a BrickStarter, and the instance itself, if it implements BrickStarter.
+// Deferred bricks that have not been constructed are not started.
+func (b *BrickManager) getBrickStarter(liveID string) (BrickStarter, reflect.Value, bool) {
+	b.deferredBuildsLock.RLock()
+	_, pending := b.deferredBuilds[liveID]
+	b.deferredBuildsLock.RUnlock()
+	if pending {
+		return nil, reflect.Value{}, false
+	}
+	instance, ok := b.getBrickFromExist(liveID)
+	if !ok || instance.IsNil() {
+		return nil, reflect.Value{}, false
+	}
+	starter, ok := asInterface[BrickStarter](instance)
+	return starter, instance, ok
+}
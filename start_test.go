@@ -0,0 +1,113 @@
+package brick
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var testStartLog []string
+
+// testPortInUse fails the Start of TestBrick692.
+var testPortInUse = true
+
+type TestBrick69 struct {
+	DB *TestBrick691 `brick:""`
+}
+
+func (t *TestBrick69) BrickTypeID() string {
+	return "TestBrick69"
+}
+
+func (t *TestBrick69) Start(ctx context.Context) error {
+	testStartLog = append(testStartLog, "server")
+	return nil
+}
+
+type TestBrick691 struct{}
+
+func (t *TestBrick691) BrickTypeID() string {
+	return "TestBrick691"
+}
+
+func (t *TestBrick691) Start(ctx context.Context) error {
+	testStartLog = append(testStartLog, "db")
+	return nil
+}
+
+func (t *TestBrick691) Close() error {
+	testStartLog = append(testStartLog, "db closed")
+	return nil
+}
+
+type TestBrick692 struct {
+	Cache *TestBrick693 `brick:""`
+}
+
+func (t *TestBrick692) BrickTypeID() string {
+	return "TestBrick692"
+}
+
+func (t *TestBrick692) Start(ctx context.Context) error {
+	if testPortInUse {
+		return errors.New("port in use")
+	}
+	testStartLog = append(testStartLog, "server2")
+	return nil
+}
+
+type TestBrick693 struct {
+	Name string
+}
+
+func (t *TestBrick693) BrickTypeID() string {
+	return "TestBrick693"
+}
+
+func (t *TestBrick693) Start(ctx context.Context) error {
+	testStartLog = append(testStartLog, "cache")
+	return nil
+}
+
+func (t *TestBrick693) Close() error {
+	testStartLog = append(testStartLog, "cache closed")
+	return nil
+}
+
+func TestStartAll(t *testing.T) {
+	Register[*TestBrick69]()
+	Register[*TestBrick692]()
+	Get[*TestBrick69]()
+	if err := StartAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"db", "server"}; !slices.Equal(testStartLog, want) {
+		t.Fatalf("start log = %v, want %v", testStartLog, want)
+	}
+	if err := StartAll(context.Background()); err != nil || len(testStartLog) != 2 {
+		t.Fatalf("the second StartAll() = %v, log %v, want the bricks not started again", err, testStartLog)
+	}
+
+	testStartLog = nil
+	Get[*TestBrick692]()
+	err := StartAll(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "start brick(TestBrick692): port in use") {
+		t.Fatalf("StartAll() = %v, want the error of the failing start", err)
+	}
+	if want := []string{"cache", "cache closed"}; !slices.Equal(testStartLog, want) {
+		t.Errorf("start log = %v, want %v", testStartLog, want)
+	}
+
+	// A retry starts the failed brick and the bricks rolled back.
+	testStartLog = nil
+	testPortInUse = false
+	defer func() { testPortInUse = true }()
+	if err := StartAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"cache", "server2"}; !slices.Equal(testStartLog, want) {
+		t.Errorf("start log of the retry = %v, want %v", testStartLog, want)
+	}
+}
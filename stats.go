@@ -0,0 +1,92 @@
+package brick
+
+import (
+	"sort"
+	"time"
+)
+
+// BuildStat is the construction statistics of a brick type.
+type BuildStat struct {
+	TypeID string
+	// Builds is the number of successful constructions, including the ones not cached (GetWith, deferred bricks).
+	Builds int
+	// Failures is the number of constructions that panicked.
+	Failures int
+	// TotalDuration is the total time spent in constructions, including the construction of dependencies.
+	TotalDuration time.Duration
+	// Instances is the number of cached instances of the type.
+	Instances int
+}
+
+// BuildStats returns the construction statistics of every brick type built at least once, sorted by typeID.
+func BuildStats() []BuildStat {
+	return brickManager.BuildStats()
+}
+
+// BuildStats returns the construction statistics of every brick type built at least once, sorted by typeID.
+func (b *BrickManager) BuildStats() []BuildStat {
+	instances := make(map[string]int)
+	for _, liveID := range b.BuiltLiveIDs() {
+		if instance, ok := b.getBrickFromExist(liveID); ok {
+			instances[b.getTypeIDByReflectType(instance.Type())]++
+		}
+	}
+	b.buildStatsLock.Lock()
+	stats := make([]BuildStat, 0, len(b.buildStats))
+	for typeID, stat := range b.buildStats {
+		s := *stat
+		s.Instances = instances[typeID]
+		stats = append(stats, s)
+	}
+	b.buildStatsLock.Unlock()
+	sort.Slice(stats, func(i, j int) bool { return stats[i].TypeID < stats[j].TypeID })
+	return stats
+}
+
+// InFlightBuilds returns the sorted liveIDs whose construction is in progress, including the dependents
+// waiting for their dependencies, e.g. to report the bricks stuck when the startup times out.
+func InFlightBuilds() []string {
+	return brickManager.InFlightBuilds()
+}
+
+// InFlightBuilds returns the sorted liveIDs whose construction is in progress.
+func (b *BrickManager) InFlightBuilds() []string {
+	b.inFlightBuildsLock.Lock()
+	liveIDs := make([]string, 0, len(b.inFlightBuilds))
+	for liveID := range b.inFlightBuilds {
+		liveIDs = append(liveIDs, liveID)
+	}
+	b.inFlightBuildsLock.Unlock()
+	sort.Strings(liveIDs)
+	return liveIDs
+}
+
+func (b *BrickManager) startBuild(liveID string) {
+	b.inFlightBuildsLock.Lock()
+	defer b.inFlightBuildsLock.Unlock()
+	b.inFlightBuilds[liveID]++
+}
+
+func (b *BrickManager) finishBuild(liveID string) {
+	b.inFlightBuildsLock.Lock()
+	defer b.inFlightBuildsLock.Unlock()
+	if b.inFlightBuilds[liveID]--; b.inFlightBuilds[liveID] <= 0 {
+		delete(b.inFlightBuilds, liveID)
+	}
+}
+
+func (b *BrickManager) recordBuild(typeID string, duration time.Duration, succeeded bool) {
+	b.buildStatsLock.Lock()
+	defer b.buildStatsLock.Unlock()
+	stat, ok := b.buildStats[typeID]
+	if !ok {
+		stat = &BuildStat{TypeID: typeID}
+		b.buildStats[typeID] = stat
+	}
+	if succeeded {
+		stat.Builds++
+	} else {
+		stat.Failures++
+	}
+	stat.TotalDuration += duration
+}
@@ -154,6 +154,15 @@ func (c *CloneService) BrickTypeID() string {
 	return "CloneService-VWXYZ"
 }
 
+// 深度克隆的组件，ConfigService 的依赖也被克隆
+type DeepCloneService struct {
+	ConfigService *ConfigService `brick:"deepclone:"`
+}
+
+func (c *DeepCloneService) BrickTypeID() string {
+	return "DeepCloneService-VWXYZ"
+}
+
 // 测试非指针类型的单例
 type NonPtrSingleton struct {
 	Counter int
@@ -174,6 +183,7 @@ func Test_3(t *testing.T) {
 	brick.RegisterNewer[*DBConfig]()
 	brick.Register[*ConfigService]()
 	brick.Register[*CloneService]()
+	brick.Register[*DeepCloneService]()
 	brick.Register[*NonPtrSingleton]()
 
 	// 注册接口类型对应的实例类型
@@ -307,6 +317,19 @@ func Test_3(t *testing.T) {
 	fmt.Println("CloneService2 ConfigService AppConfig Name:", cloneService2.ConfigService.AppConfig.GetName())
 	fmt.Println("CloneService2 ConfigService DBConfig Name:", cloneService2.ConfigService.DBConfig.GetName())
 
+	// 深度克隆的 ConfigService 不与单例共享 AppConfig
+	deepCloneService := brick.GetOrCreate[*DeepCloneService]()
+	if deepCloneService.ConfigService == configService {
+		t.Error("the deep clone of ConfigService is the singleton")
+	}
+	if deepCloneService.ConfigService.AppConfig == configService.AppConfig {
+		t.Error("the deep clone of ConfigService shares the singleton AppConfig")
+	}
+	deepCloneService.ConfigService.AppConfig.(*AppConfig).Name = "Deep Cloned AppConfig"
+	if name := configService.AppConfig.GetName(); name == "Deep Cloned AppConfig" {
+		t.Errorf("the singleton AppConfig name = %s, want it unchanged by the deep clone", name)
+	}
+
 	// 获取并测试非指针类型的单例
 	nonPtrSingleton := brick.GetOrCreate[*NonPtrSingleton]()
 	nonPtrSingleton.Counter++
@@ -209,3 +209,24 @@ func Test_4(t *testing.T) {
 	uuidGen := brick.GetOrCreate[*UUIDGenerator]()
 	fmt.Println(uuidGen.GenID())
 }
+
+func Test_DependencyGraph(t *testing.T) {
+	brick.RegisterNewer[*Logger]()
+	brick.RegisterNewer[*DB]()
+	brick.RegisterNewer[*Redis]()
+	brick.Register[*Service]()
+	brick.RegisterNewer[*Server]()
+	brick.Register[*UUIDGenerator]()
+
+	graph := brick.DependencyGraph()
+	want := map[string][]string{
+		"Server":  {"DB", "Logger", "Redis", "Service", "UUIDGenerator"},
+		"Service": {"Logger"},
+		"Logger":  {},
+	}
+	for typeID, edges := range want {
+		if fmt.Sprint(graph[typeID]) != fmt.Sprint(edges) {
+			t.Errorf("DependencyGraph()[%s] = %v, want %v", typeID, graph[typeID], edges)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/doraemonkeys/brick"
+)
+
+type DB struct {
+	DSN string `json:"dsn"`
+}
+
+func (d *DB) BrickTypeID() string {
+	return "DB"
+}
+
+func (d *DB) NewBrick(jsonConfig []byte) brick.Brick {
+	db := &DB{}
+	if jsonConfig != nil {
+		if err := json.Unmarshal(jsonConfig, db); err != nil {
+			panic(err)
+		}
+	}
+	if db.DSN == "" {
+		panic(errors.New("db dsn is required"))
+	}
+	return db
+}
+
+type Worker struct {
+	DB *DB `brick:""`
+	// A random worker has no instance of its own.
+	Peer *Peer `brick:"random"`
+}
+
+func (w *Worker) BrickTypeID() string {
+	return "Worker"
+}
+
+type Peer struct{}
+
+func (p *Peer) BrickTypeID() string {
+	return "Peer"
+}
+
+func Test_EagerInit(t *testing.T) {
+	brick.RegisterNewer[*DB]()
+	brick.Register[*Worker]()
+	if err := brick.AddConfigFile("config.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := brick.EagerInit(); err != nil {
+		t.Fatalf("EagerInit() error = %v", err)
+	}
+	built := brick.BuiltLiveIDs()
+	for _, liveID := range []string{"DB", "Peer", "Worker", "replica"} {
+		if !slices.Contains(built, liveID) {
+			t.Errorf("BuiltLiveIDs() = %v, want %s built", built, liveID)
+		}
+	}
+	if slices.Contains(built, "broken") {
+		t.Error("EagerInit() built the disabled live")
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.json")
+	err := os.WriteFile(bad, []byte(`[{"metaData": {"typeID": "DB"}, "lives": [{"liveID": "missing dsn", "config": {}}]}]`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := brick.AddConfigFileWithOptions(bad, brick.ConfigOptions{DisableLiveIDConstraint: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := brick.EagerInit(); err == nil || !strings.Contains(err.Error(), "db dsn is required") {
+		t.Errorf("EagerInit() error = %v, want the error of the live missing dsn", err)
+	}
+}
@@ -2,13 +2,36 @@ package brick
 
 import (
 	"io/fs"
+	"math/rand"
 	"os"
 
 	"github.com/doraemonkeys/doraemon"
 )
 
+const randomLiveIDChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// RandomLiveID returns a random liveID, e.g. the liveID of a clone or of a `random` tagged field.
 func RandomLiveID() string {
-	return doraemon.GenRandomString("ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789", 25)
+	brickManager.randSourceLock.Lock()
+	defer brickManager.randSourceLock.Unlock()
+	src := brickManager.randSource
+	if src == nil {
+		return doraemon.GenRandomString(randomLiveIDChars, 25)
+	}
+	id := make([]byte, 25)
+	for i := range id {
+		id[i] = randomLiveIDChars[src.Int63()%int64(len(randomLiveIDChars))]
+	}
+	return string(id)
+}
+
+// SetRandSource sets the source of the liveIDs generated by RandomLiveID, e.g. `rand.NewSource(1)`,
+// so that the clone liveIDs of a test are reproducible. The source is only used under a lock, it needn't be thread-safe.
+// A nil source restores the default generator.
+func SetRandSource(src rand.Source) {
+	brickManager.randSourceLock.Lock()
+	defer brickManager.randSourceLock.Unlock()
+	brickManager.randSource = src
 }
 func WriteFilePerm(name string, data []byte) error {
 	perm := fs.FileMode(0644)
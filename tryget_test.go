@@ -0,0 +1,98 @@
+package brick
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type TestBrick58 struct {
+	B *TestBrick581 `brick:""`
+}
+
+func (t *TestBrick58) BrickTypeID() string {
+	return "TestBrick58"
+}
+
+type TestBrick581 struct {
+	A *TestBrick58 `brick:""`
+}
+
+func (t *TestBrick581) BrickTypeID() string {
+	return "TestBrick581"
+}
+
+type TestBrick582 struct{}
+
+func (t *TestBrick582) BrickTypeID() string {
+	return "TestBrick582"
+}
+
+type TestBrick583 struct{}
+
+func (t *TestBrick583) BrickTypeID() string {
+	return "TestBrick583"
+}
+
+func TestTryGet(t *testing.T) {
+	func() {
+		defer func() {
+			if err, _ := recover().(error); !errors.Is(err, ErrCircularDependency) {
+				t.Errorf("Register() of a circular dependency panic = %v, want ErrCircularDependency", err)
+			}
+		}()
+		Register[*TestBrick58]()
+	}()
+	Register[*TestBrick582]()
+
+	if _, err := TryGet[*TestBrick583](); !errors.Is(err, ErrTypeNotRegistered) {
+		t.Errorf("TryGet() of an unregistered type error = %v, want ErrTypeNotRegistered", err)
+	}
+	if _, err := TryGet[*TestBrick58](); !errors.Is(err, ErrTypeNotRegistered) {
+		t.Errorf("TryGet() of a rejected circular dependency error = %v, want ErrTypeNotRegistered", err)
+	}
+	if _, err := TryGet[*TestBrick582]("TestBrick582 unknown"); !errors.Is(err, ErrUnknownLiveID) {
+		t.Errorf("TryGet() of an unknown liveID error = %v, want ErrUnknownLiveID", err)
+	}
+	if b, err := TryGet[*TestBrick582](); err != nil || b != Get[*TestBrick582]() {
+		t.Errorf("TryGet() = %v, %v, want the brick", b, err)
+	}
+
+	if b, err := TryGetOrCreate[*TestBrick582]("TestBrick582 unknown"); err != nil || b == nil {
+		t.Errorf("TryGetOrCreate() = %v, %v, want a new brick", b, err)
+	}
+	if _, err := TryGetOrCreate[*TestBrick583]("TestBrick583 unknown"); !errors.Is(err, ErrTypeNotRegistered) {
+		t.Errorf("TryGetOrCreate() of an unregistered type error = %v, want ErrTypeNotRegistered", err)
+	}
+}
+
+type TestBrick584 struct {
+	counts map[string]int
+}
+
+func (t *TestBrick584) BrickTypeID() string {
+	return "TestBrick584"
+}
+
+func (t *TestBrick584) BrickInit() error {
+	// A bug: the map is not made.
+	t.counts["init"]++
+	return nil
+}
+
+func TestTryGetRuntimeError(t *testing.T) {
+	Register[*TestBrick584]()
+	defer func() {
+		err, _ := recover().(error)
+		var runtimeErr runtime.Error
+		if !errors.As(err, &runtimeErr) {
+			t.Fatalf("TryGet() of a build with a runtime error panic = %v, want the runtime error", err)
+		}
+		if !strings.Contains(err.Error(), "BrickInit") {
+			t.Errorf("the panic %q does not have the stack of the build", err)
+		}
+	}()
+	_, err := TryGet[*TestBrick584]()
+	t.Errorf("TryGet() of a build with a runtime error = %v, want a panic", err)
+}
@@ -0,0 +1,121 @@
+package brick
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The suffixes of the edges of DependencyGraph to a clone or a random live, which are new instances of the type.
+const (
+	cloneEdgeSuffix  = " (clone)"
+	randomEdgeSuffix = " (random)"
+)
+
+// DependencyGraph returns the typeIDs that each registered brick type directly depends on, indexed by TypeID.
+// The edges are read from the `brick` tags of the types, a liveID on a tag is resolved to its typeID where it is known,
+// from its config, its instance or RegisterLiveIDType. The dependencies whose type can't be determined are skipped.
+// An edge to a clone or a random live is marked by the suffix ` (clone)` or ` (random)`, e.g. `Logger (clone)`.
+//
+// Unlike Dependencies, the graph is made of types, so it describes the wiring of the app before any config is added.
+func DependencyGraph() map[string][]string {
+	return brickManager.DependencyGraph()
+}
+
+// ExportDOT writes the DependencyGraph in the Graphviz DOT language, e.g. to render it with `dot -Tsvg`.
+// The edges to a clone or a random live are dashed and labeled.
+func ExportDOT(w io.Writer) error {
+	return brickManager.ExportDOT(w)
+}
+
+// DependencyGraph returns the typeIDs that each registered brick type directly depends on, indexed by TypeID.
+func (b *BrickManager) DependencyGraph() map[string][]string {
+	b.brickTypeIDMapLock.RLock()
+	types := make(map[string]reflect.Type, len(b.brickTypeIDMap2))
+	for typeID, typ := range b.brickTypeIDMap2 {
+		types[typeID] = typ
+	}
+	b.brickTypeIDMapLock.RUnlock()
+
+	graph := make(map[string][]string, len(types))
+	for typeID, typ := range types {
+		edges := []string{}
+		seen := make(map[string]bool)
+		for _, edge := range b.typeDependencies(typ) {
+			if !seen[edge] {
+				seen[edge] = true
+				edges = append(edges, edge)
+			}
+		}
+		sort.Strings(edges)
+		graph[typeID] = edges
+	}
+	return graph
+}
+
+// typeDependencies returns the edges of the `brick` tagged fields of the type, see resolveField.
+func (b *BrickManager) typeDependencies(typ reflect.Type) []string {
+	var edges []string
+	for _, field := range b.brickFields(typ) {
+		for _, edge := range b.resolveField(field.field.Type, field.tag).edges {
+			if edge.typ == nil {
+				continue
+			}
+			typeID, ok := b.typeIDOf(edge.typ)
+			if !ok {
+				continue
+			}
+			if edge.clone {
+				typeID += cloneEdgeSuffix
+			} else if edge.random {
+				typeID += randomEdgeSuffix
+			}
+			edges = append(edges, typeID)
+		}
+	}
+	return edges
+}
+
+// typeIDOf returns the typeID of the registered type, or of the registered pointer or value type of the same base type.
+func (b *BrickManager) typeIDOf(typ reflect.Type) (string, bool) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typeID, ok := b.getBrickTypeID(typ); ok {
+		return typeID, true
+	}
+	return b.getBrickTypeID(reflect.PointerTo(typ))
+}
+
+// ExportDOT writes the DependencyGraph in the Graphviz DOT language.
+func (b *BrickManager) ExportDOT(w io.Writer) error {
+	graph := b.DependencyGraph()
+	typeIDs := make([]string, 0, len(graph))
+	for typeID := range graph {
+		typeIDs = append(typeIDs, typeID)
+	}
+	sort.Strings(typeIDs)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph brick {")
+	for _, typeID := range typeIDs {
+		fmt.Fprintf(bw, "\t%s;\n", strconv.Quote(typeID))
+	}
+	for _, typeID := range typeIDs {
+		for _, edge := range graph[typeID] {
+			attrs := ""
+			if dep, ok := strings.CutSuffix(edge, cloneEdgeSuffix); ok {
+				edge, attrs = dep, ` [style=dashed, label="clone"]`
+			} else if dep, ok := strings.CutSuffix(edge, randomEdgeSuffix); ok {
+				edge, attrs = dep, ` [style=dashed, label="random"]`
+			}
+			fmt.Fprintf(bw, "\t%s -> %s%s;\n", strconv.Quote(typeID), strconv.Quote(edge), attrs)
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}